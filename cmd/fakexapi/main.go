@@ -0,0 +1,81 @@
+// fakexapi serves a XAPI database file over JSON-RPC so the live mode of
+// readxapidb can be tried without a pool:
+//
+//	go run ./cmd/fakexapi --file ./examples/xapi-db.xml --listen localhost:8080
+//	./readxapidb --live --hostname http://localhost:8080
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"time"
+
+	"example.com/readxapidb/internal/fetch"
+	"example.com/readxapidb/internal/xapi"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+func main() {
+	fileName := flag.String("file", "", "Database file to serve")
+	listen := flag.String("listen", "localhost:8080", "Address to listen on")
+	churn := flag.Duration("churn", 0, "Modify the description of a random object at this interval to generate events (0 disables it)")
+	flag.Parse()
+
+	if *fileName == "" {
+		fmt.Println("Error: -file is required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	data, err := fetch.Local(*fileName)
+	if err != nil {
+		fmt.Printf("failed to read %s: %s\n", *fileName, err)
+		os.Exit(1)
+	}
+
+	db, err := xapidb.ParseXapiDB(data)
+	if err != nil {
+		fmt.Printf("failed to parse %s: %s\n", *fileName, err)
+		os.Exit(1)
+	}
+
+	server := xapi.NewServer(db)
+	if *churn > 0 {
+		go generateEvents(server, db, *churn)
+	}
+
+	fmt.Printf("Serving %s on http://%s/jsonrpc\n", *fileName, *listen)
+	if err := http.ListenAndServe(*listen, server); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// generateEvents periodically updates the description of a random row that
+// has one.
+func generateEvents(server *xapi.Server, db *xapidb.DB, interval time.Duration) {
+	db.RLock()
+	refs := []string{}
+	for ref, row := range db.RefIndex {
+		if _, ok := row.Attr["name__description"]; ok {
+			refs = append(refs, ref)
+		}
+	}
+	db.RUnlock()
+
+	if len(refs) == 0 {
+		fmt.Println("no object with a description, no events will be generated")
+		return
+	}
+
+	for range time.Tick(interval) {
+		ref := refs[rand.IntN(len(refs))]
+		desc := fmt.Sprintf("modified at %s", time.Now().Format(time.TimeOnly))
+		if err := server.SetField(ref, "name__description", desc); err != nil {
+			fmt.Println(err)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// parsebench measures the time and the memory used to parse a big synthetic
+// database with the parsers of xapidb:
+//
+//	go run ./cmd/parsebench --vms 2000 --messages 200000 --tasks 50000
+//
+// The database is written by the synth package with its default pool, a
+// real one can be given with --file. The BenchmarkParse functions of
+// internal/xapidb compare the parsers on a smaller database, parsebench runs
+// them on a big one, shows the best time of --runs and the heap kept by the
+// parsed database.
+// The baseline is the parser as it was before interning (a map per node
+// with a copy of every string). For the default 105 MiB database:
+//
+//	parser           time       heap  allocated     rows
+//	baseline       5.189s  353.0 MiB    1.2 GiB   262069
+//	bytes          5.341s  311.0 MiB    1.1 GiB   262069
+//	stream         6.334s  311.0 MiB  978.9 MiB   262069
+//	lazy           4.417s   32.8 KiB  671.3 MiB        0
+//	lazy+VM        4.397s   10.7 MiB  721.5 MiB     2011
+//
+// The parsers reading all the rows are not faster than the baseline, most
+// of the time is spent in encoding/xml and interning costs about what it
+// saves in allocations. They keep 12% less memory and stream doesn't need
+// the file in memory while parsing. Only the lazy mode is faster, the file
+// is still tokenized but the rows are not built.
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"example.com/readxapidb/internal/synth"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+func main() {
+	vms := flag.Int("vms", 2000, "Number of VMs (with their VBDs, VDIs and VIFs)")
+	messages := flag.Int("messages", 200000, "Number of messages")
+	tasks := flag.Int("tasks", 50000, "Number of tasks")
+	fileName := flag.String("file", "", "Database to parse instead of a synthetic one")
+	runs := flag.Int("runs", 3, "Number of times each parser is run, the best time is shown")
+	flag.Parse()
+
+	path := *fileName
+	if path == "" {
+		dir, err := os.MkdirTemp("", "parsebench")
+		if err != nil {
+			fmt.Printf("failed to create a directory: %s\n", err)
+			os.Exit(1)
+		}
+		defer os.RemoveAll(dir)
+
+		path = filepath.Join(dir, "state.db")
+		o := synth.DefaultOptions()
+		o.VMs, o.Messages, o.Tasks = *vms, *messages, *tasks
+		if err := writeSynthetic(path, o); err != nil {
+			fmt.Printf("failed to write %s: %s\n", path, err)
+			os.Exit(1)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Printf("Parsing %s (%s)\n\n", path, size(uint64(info.Size())))
+
+	benchmarks := []struct {
+		name  string
+		parse func() (*xapidb.DB, error)
+	}{
+		{"baseline", func() (*xapidb.DB, error) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			return parseBaseline(data)
+		}},
+		{"bytes", func() (*xapidb.DB, error) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			return xapidb.ParseXapiDB(data)
+		}},
+		{"stream", func() (*xapidb.DB, error) {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			return xapidb.ReadXapiDB(f)
+		}},
+		{"lazy", func() (*xapidb.DB, error) {
+			return openLazy(path, info.Size())
+		}},
+		{"lazy+VM", func() (*xapidb.DB, error) {
+			db, err := openLazy(path, info.Size())
+			if err != nil {
+				return nil, err
+			}
+			if err := db.Table("VM").Load(); err != nil {
+				db.Close()
+				return nil, err
+			}
+			return db, nil
+		}},
+	}
+
+	fmt.Printf("%-10s %10s %10s %10s %8s\n", "parser", "time", "heap", "allocated", "rows")
+	for _, b := range benchmarks {
+		var best time.Duration
+		var heap, allocated uint64
+		var rows int
+
+		var err error
+		for range max(*runs, 1) {
+			runtime.GC()
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+
+			start := time.Now()
+			var db *xapidb.DB
+			db, err = b.parse()
+			elapsed := time.Since(start)
+			if err != nil {
+				break
+			}
+
+			// The heap left is what the database keeps in memory
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			runtime.KeepAlive(db)
+			db.Close()
+
+			if best == 0 || elapsed < best {
+				best = elapsed
+			}
+			heap = after.HeapAlloc - min(before.HeapAlloc, after.HeapAlloc)
+			allocated = after.TotalAlloc - before.TotalAlloc
+			rows = len(db.RefIndex)
+		}
+		if err != nil {
+			fmt.Printf("%-10s failed: %s\n", b.name, err)
+			continue
+		}
+
+		fmt.Printf("%-10s %10s %10s %10s %8d\n", b.name, best.Round(time.Millisecond), size(heap), size(allocated), rows)
+	}
+}
+
+// openLazy opens the file with OpenXapiDB, it is closed with the database.
+func openLazy(path string, size int64) (*xapidb.DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	db, err := xapidb.OpenXapiDB(f, size)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func size(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// parseBaseline is the parser before interning and streaming, to compare.
+func parseBaseline(data []byte) (*xapidb.DB, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var stack []*xapidb.Node
+	var root *xapidb.Node
+	refIndex := make(map[string]*xapidb.Node)
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xapidb.Node{Name: t.Name.Local, Attr: map[string]string{}, Children: []*xapidb.Node{}}
+			for _, a := range t.Attr {
+				n.Attr[a.Name.Local] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				n.Parent = parent
+				parent.Children = append(parent.Children, n)
+			} else {
+				root = n
+			}
+			if ref, ok := n.Attr["ref"]; ok {
+				refIndex[ref] = n
+			}
+			stack = append(stack, n)
+
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	return &xapidb.DB{Root: root, RefIndex: refIndex}, nil
+}
+
+// writeSynthetic writes a database generated with the options.
+func writeSynthetic(path string, o synth.Options) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	db, _ := synth.Generate(o)
+	return xapidb.WriteXapiDB(f, db)
+}
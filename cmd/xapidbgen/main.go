@@ -0,0 +1,64 @@
+// xapidbgen writes a synthetic XAPI database, to try readxapidb on pools of
+// any size or with defects:
+//
+//	go run ./cmd/xapidbgen --hosts 16 --vms 1000 -o big.db
+//	go run ./cmd/xapidbgen --dangling 3 --asymmetric 3 --stuck 2 -o broken.db
+//
+// The same options and seed always write the same database. The injected
+// defects are listed on the standard error.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"example.com/readxapidb/internal/synth"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+func main() {
+	o := synth.DefaultOptions()
+	flag.Uint64Var(&o.Seed, "seed", o.Seed, "Seed of the random generator")
+	flag.IntVar(&o.Hosts, "hosts", o.Hosts, "Number of hosts, each one with a local SR")
+	flag.IntVar(&o.SRs, "srs", o.SRs, "Number of shared SRs")
+	flag.IntVar(&o.Networks, "networks", o.Networks, "Number of networks, with a PIF on each host")
+	flag.IntVar(&o.VMs, "vms", o.VMs, "Number of VMs")
+	flag.IntVar(&o.DisksPerVM, "disks", o.DisksPerVM, "Number of disks (VBD and VDI) of each VM")
+	flag.IntVar(&o.VIFsPerVM, "vifs", o.VIFsPerVM, "Number of VIFs of each VM")
+	flag.IntVar(&o.SnapshotChains, "snapshot-chains", o.SnapshotChains, "Number of VMs with snapshots")
+	flag.IntVar(&o.SnapshotDepth, "snapshot-depth", o.SnapshotDepth, "Number of snapshots of these VMs")
+	flag.IntVar(&o.Messages, "messages", o.Messages, "Number of messages")
+	flag.IntVar(&o.Tasks, "tasks", o.Tasks, "Number of tasks")
+	flag.IntVar(&o.Defects.DanglingRefs, "dangling", 0, "Number of references to objects that don't exist")
+	flag.IntVar(&o.Defects.AsymmetricLinks, "asymmetric", 0, "Number of links set on one side only")
+	flag.IntVar(&o.Defects.StuckOperations, "stuck", 0, "Number of VM operations with a task that doesn't exist")
+	output := flag.String("o", "", "File to write (default the standard output)")
+	flag.Parse()
+
+	db, defects := synth.Generate(o)
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Printf("failed to create %s: %s\n", *output, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := xapidb.WriteXapiDB(w, db); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write the database: %s\n", err)
+		os.Exit(1)
+	}
+
+	for _, d := range defects {
+		fmt.Fprintln(os.Stderr, d)
+	}
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, "Wrote %d objects to %s\n", len(db.RefIndex), *output)
+	}
+}
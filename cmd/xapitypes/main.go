@@ -0,0 +1,282 @@
+// xapitypes generates the Go structs of the core XAPI classes of
+// pkg/xapidb from the schema:
+//
+//	go generate ./pkg/xapidb
+//	go run ./cmd/xapitypes --schema extra.json --classes VM,VBD -o classes.go
+//
+// A struct has a field per field of the class, typed after the schema, and
+// a decode method filling it from a row. References to the generated
+// classes are Ref[T] resolved to their target.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"example.com/readxapidb/internal/schema"
+)
+
+// classes are the classes generated by default.
+var classes = []string{
+	"Bond", "GPU_group", "PBD", "PCI", "PGPU", "PIF", "SM", "SR", "VBD",
+	"VDI", "VGPU", "VGPU_type", "VIF", "VLAN", "VM", "VM_guest_metrics",
+	"VM_metrics", "host", "host_cpu", "host_metrics", "message", "network",
+	"pool", "task", "tunnel",
+}
+
+// initialisms are the words written in upper case in Go names.
+var initialisms = map[string]string{
+	"api": "API", "cbt": "CBT", "cpu": "CPU", "cpus": "CPUs", "dns": "DNS",
+	"gpu": "GPU", "ha": "HA", "http": "HTTP", "https": "HTTPS", "hvm": "HVM",
+	"id": "ID", "ip": "IP", "ips": "IPs", "ipv4": "IPv4", "ipv6": "IPv6",
+	"iqn": "IQN", "iscsi": "ISCSI", "iso": "ISO", "mac": "MAC", "mtu": "MTU",
+	"numa": "NUMA", "os": "OS", "pbd": "PBD", "pci": "PCI", "pif": "PIF",
+	"qos": "QoS", "sm": "SM", "sr": "SR", "sriov": "SRIOV", "ssh": "SSH",
+	"ssl": "SSL", "tls": "TLS", "uefi": "UEFI", "url": "URL", "uuid": "UUID",
+	"vbd": "VBD", "vdi": "VDI", "vif": "VIF", "vm": "VM", "vmpp": "VMPP",
+	"vmss": "VMSS", "wlb": "WLB",
+}
+
+func main() {
+	schemaFile := flag.String("schema", "", "JSON schema file updating the embedded one")
+	classList := flag.String("classes", strings.Join(classes, ","), "Classes to generate, separated by commas")
+	pkg := flag.String("package", "xapidb", "Package of the generated file")
+	output := flag.String("o", "classes_gen.go", "File to write")
+	flag.Parse()
+
+	s := schema.Default()
+	if *schemaFile != "" {
+		var err error
+		if s, err = schema.Load(*schemaFile); err != nil {
+			fmt.Printf("failed to load the schema: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	src, err := generate(s, *pkg, strings.Split(*classList, ","))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		fmt.Printf("failed to write %s: %s\n", *output, err)
+		os.Exit(1)
+	}
+}
+
+// generator writes the code of the classes, names maps the classes to their
+// Go name to type the references.
+type generator struct {
+	buf   bytes.Buffer
+	names map[string]string
+}
+
+func (g *generator) printf(format string, a ...any) {
+	fmt.Fprintf(&g.buf, format, a...)
+}
+
+func generate(s *schema.Schema, pkg string, names []string) ([]byte, error) {
+	g := &generator{names: map[string]string{}}
+
+	var generated []*schema.Class
+	for _, name := range names {
+		c := s.Class(strings.TrimSpace(name))
+		if c == nil {
+			return nil, fmt.Errorf("no class %s in the schema", name)
+		}
+		generated = append(generated, c)
+		g.names[c.Name] = goName(c.Name)
+	}
+	sort.Slice(generated, func(i, j int) bool { return g.names[generated[i].Name] < g.names[generated[j].Name] })
+
+	g.printf("// Objects are the objects of the core classes, see DB.Objects.\n")
+	g.printf("type Objects struct {\n")
+	for _, c := range generated {
+		g.printf("%s []*%s\n", plural(g.names[c.Name]), g.names[c.Name])
+	}
+	g.printf("\nobjects map[string]any\n}\n\n")
+
+	g.printf("// load creates the objects of all the classes before decoding their\n")
+	g.printf("// fields, so references resolve to any of them.\n")
+	g.printf("func (o *Objects) load(d *decoder) {\n")
+	for _, c := range generated {
+		v := strings.ToLower(plural(g.names[c.Name]))
+		g.printf("%s, %sRows := create[%s](d, %q)\n", v, v, g.names[c.Name], c.Name)
+	}
+	for _, c := range generated {
+		v := strings.ToLower(plural(g.names[c.Name]))
+		g.printf("\no.%s = %s\n", plural(g.names[c.Name]), v)
+		g.printf("for i, row := range %sRows {\n%s[i].decode(d, row)\n}\n", v, v)
+	}
+	g.printf("}\n")
+
+	for _, c := range generated {
+		if err := g.class(c); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by xapitypes from the XAPI schema %d.%d. DO NOT EDIT.\n\n", s.Major, s.Minor)
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+	if bytes.Contains(g.buf.Bytes(), []byte("time.Time")) {
+		fmt.Fprintf(&out, "import \"time\"\n\n")
+	}
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("invalid generated code: %w", err)
+	}
+	return src, nil
+}
+
+// class writes the struct of the class, the set of its fields and its
+// decode method.
+func (g *generator) class(c *schema.Class) error {
+	name := g.names[c.Name]
+
+	fields := make([]string, 0, len(c.Fields))
+	for f := range c.Fields {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	// Names like foo_bar and foo__bar would give the same Go name
+	seen := map[string]string{"Ref": "ref", "Extras": "extras"}
+	for _, f := range fields {
+		n := goName(f)
+		if other, ok := seen[n]; ok {
+			return fmt.Errorf("%s: %s and %s are both %s", c.Name, f, other, n)
+		}
+		seen[n] = f
+	}
+
+	g.printf("\n")
+	g.comment(fmt.Sprintf("%s is an object of the class %s. %s", name, c.Name, c.Doc))
+	g.printf("type %s struct {\n", name)
+	g.printf("Ref string\n")
+	for _, f := range fields {
+		doc := c.Fields[f].Doc
+		if doc == "" {
+			doc = "No documentation"
+		}
+		g.printf("\n")
+		g.comment(fmt.Sprintf("%s (%s)", strings.TrimSuffix(doc, "."), f))
+		g.printf("%s %s\n", goName(f), g.goType(c.Fields[f].Type))
+	}
+	g.printf("\n// Fields not in the schema, as stored in the database\n")
+	g.printf("Extras map[string]string\n}\n\n")
+
+	g.printf("var %sFields = map[string]bool{\n", strings.ToLower(name))
+	g.printf("\"ref\": true, \"_ref\": true, \"__ctime\": true, \"__mtime\": true,\n")
+	for _, f := range fields {
+		g.printf("%q: true,\n", f)
+	}
+	g.printf("}\n\n")
+
+	g.printf("func (o *%s) decode(d *decoder, row *Row) {\n", name)
+	g.printf("o.Ref = row.Ref()\n")
+	for _, f := range fields {
+		g.printf("o.%s = field(d, row, %q, %s)\n", goName(f), f, g.conv(c.Fields[f].Type))
+	}
+	g.printf("o.Extras = extras(row, %sFields)\n}\n", strings.ToLower(name))
+	return nil
+}
+
+// comment writes text as a comment wrapped at 80 columns.
+func (g *generator) comment(text string) {
+	line := "//"
+	for _, word := range strings.Fields(text) {
+		if len(line)+1+len(word) > 78 && line != "//" {
+			g.printf("%s\n", line)
+			line = "//"
+		}
+		line += " " + word
+	}
+	g.printf("%s\n", line)
+}
+
+func (g *generator) goType(t schema.Type) string {
+	switch t.Kind {
+	case schema.KindInt:
+		return "int64"
+	case schema.KindFloat:
+		return "float64"
+	case schema.KindBool:
+		return "bool"
+	case schema.KindDateTime:
+		return "time.Time"
+	case schema.KindRef:
+		if name, ok := g.names[t.Name]; ok {
+			return "Ref[" + name + "]"
+		}
+	case schema.KindSet:
+		return "[]" + g.goType(*t.Elem)
+	case schema.KindMap:
+		return "map[string]" + g.goType(*t.Elem)
+	}
+	return "string"
+}
+
+// conv returns the conversion of the decoded values of the type, see
+// objects.go.
+func (g *generator) conv(t schema.Type) string {
+	switch t.Kind {
+	case schema.KindInt:
+		return "asInt"
+	case schema.KindFloat:
+		return "asFloat"
+	case schema.KindBool:
+		return "asBool"
+	case schema.KindDateTime:
+		return "asTime"
+	case schema.KindRef:
+		if name, ok := g.names[t.Name]; ok {
+			return "asRef[" + name + "](d)"
+		}
+	case schema.KindSet:
+		return "asSet(" + g.conv(*t.Elem) + ")"
+	case schema.KindMap:
+		return "asMap(" + g.conv(*t.Elem) + ")"
+	}
+	return "asString"
+}
+
+// goName returns the exported Go name of a class or a field: VM_metrics is
+// VMMetrics, name__label is NameLabel.
+func goName(s string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(s, "_") {
+		if word == "" {
+			continue
+		}
+		if w, ok := initialisms[word]; ok {
+			b.WriteString(w)
+			continue
+		}
+		r := []rune(word)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// plural returns the name of the field of Objects with the objects of a
+// class, VMMetrics is already plural.
+func plural(name string) string {
+	if strings.HasSuffix(name, "s") {
+		return name
+	}
+	return name + "s"
+}
@@ -4,13 +4,70 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
+
+	"example.com/readxapidb/internal/cache"
+	"example.com/readxapidb/internal/config"
 )
 
+// DefaultDBPath is the database of XCP-ng and XenServer hosts.
+const DefaultDBPath = "/var/lib/xcp/state.db"
+
 type Args struct {
 	Username string
 	Password string
 	Hostname string
 	FileName string
+	Live     bool
+
+	Compare      bool
+	LiveHostname string
+	ReportOnly   bool
+	// Validate checks the database against the schema before starting
+	Validate bool
+
+	Hosts    []string
+	Pool     bool
+	Parallel int
+
+	CacheDir string
+	NoCache  bool
+	Offline  bool
+
+	// Recover keeps what can be parsed of a corrupted database
+	Recover bool
+	// Lazy parses the rows of a table when it is opened
+	Lazy bool
+
+	Watch         bool
+	WatchInterval time.Duration
+
+	Transport string
+	Gzip      bool
+	Dump      bool
+
+	Sudo         bool
+	SudoPassword string
+
+	// KeyFile and Agent are used to authenticate in addition to Password.
+	KeyFile string
+	Agent   bool
+	// JumpHost ([user@]host[:port]) is used to reach Hostname, with the
+	// credentials of its profile (JumpAuth) or the same credentials.
+	JumpHost string
+	JumpAuth *Credentials
+
+	Theme string
+	// Schema is a JSON file completing the embedded XAPI schema
+	Schema string
+	// Keys overrides the default key bindings by action name
+	Keys map[string][]string
+
+	// Command and its arguments when a command is given after the flags
+	// (for example "snapshots list").
+	Command     string
+	CommandArgs []string
 }
 
 func GetArgs() Args {
@@ -18,18 +75,282 @@ func GetArgs() Args {
 	username := flag.String("username", "", "SSH username (for remote fetch)")
 	password := flag.String("password", "", "SSH password (for remote fetch)")
 	hostname := flag.String("hostname", "", "Remote host (leave empty for local file)")
+	live := flag.Bool("live", false, "Browse live objects of -hostname through the XenAPI instead of a database file")
+	compare := flag.Bool("compare", false, "Compare the database file with the live objects of the pool")
+	liveHostname := flag.String("live-hostname", "", "Host to compare with (defaults to -hostname)")
+	reportOnly := flag.Bool("report", false, "With -compare or -validate, print the report and exit without starting the UI")
+	validate := flag.Bool("validate", false, "Validate the database against the XAPI schema")
+	hosts := flag.String("hosts", "", "Comma separated list of hosts to fetch the database from")
+	pool := flag.Bool("pool", false, "Fetch the database from -hostname (the master) and from all hosts of its pool")
+	parallel := flag.Int("parallel", 4, "Maximum number of hosts fetched at the same time")
+	cacheDir := flag.String("cache-dir", cache.DefaultDir(), "Directory where fetched databases are kept")
+	noCache := flag.Bool("no-cache", false, "Don't keep a copy of fetched databases")
+	offline := flag.Bool("offline", false, "Open the last database fetched from -hostname instead of fetching it")
+	recoverDB := flag.Bool("recover", false, "Keep what can be parsed of a truncated or corrupted database instead of failing")
+	lazy := flag.Bool("lazy", false, "Parse the rows of a table only when it is opened, for big local files")
+	watch := flag.Bool("watch", false, "Refetch the database when the local file changes or periodically for remote ones")
+	transport := flag.String("transport", "auto", "How to read remote files: sftp, exec (cat) or auto (sftp, exec if unavailable)")
+	gzip := flag.Bool("gzip", false, "Compress the database on the host before sending it (exec transport)")
+	sudo := flag.Bool("sudo", false, "Read the remote database with sudo (for non-root SSH users)")
+	sudoPassword := flag.String("sudo-password", "", "Password for sudo (defaults to -password, empty for NOPASSWD)")
+	dump := flag.Bool("dump", false, "Fetch a consistent dump made by 'xe pool-dump-database' instead of -file")
+	watchInterval := flag.Duration("watch-interval", 10*time.Second, "Interval between two fetches of a remote database in watch mode")
+	keyFile := flag.String("key-file", "", "SSH private key (for remote fetch)")
+	agent := flag.Bool("agent", false, "Authenticate with the keys of the SSH agent (SSH_AUTH_SOCK)")
+	jumpHost := flag.String("jump-host", "", "Reach -hostname through this SSH host ([user@]host[:port])")
+	themeName := flag.String("theme", "gruvbox", "Theme: gruvbox, monokai, light, none, a theme of the themes directory or a .toml file")
+	schemaPath := flag.String("schema", "", "JSON file adding or replacing classes and fields of the embedded XAPI schema")
+	configPath := flag.String("config", config.DefaultPath(), "Configuration file with defaults and host profiles")
+
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage: %s [flags]\n", os.Args[0])
+		fmt.Fprintf(out, "       %s [flags] open <profile>\n", os.Args[0])
+		fmt.Fprintf(out, "       %s [flags] snapshots list|open|prune|diff ...\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 
 	flag.Parse()
 
-	if *fileName == "" {
+	command := ""
+	commandArgs := []string{}
+	if flag.NArg() > 0 {
+		command = flag.Arg(0)
+		commandArgs = flag.Args()[1:]
+	}
+
+	conf, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Printf("Error: failed to read %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+
+	// Flags given on the command line take precedence over the configuration
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	setDefault := func(name string, value *string, conf string) {
+		if !set[name] && conf != "" {
+			*value = conf
+		}
+	}
+
+	setDefault("file", fileName, conf.Defaults.File)
+	setDefault("username", username, conf.Defaults.Username)
+	setDefault("transport", transport, conf.Defaults.Transport)
+	setDefault("theme", themeName, conf.Defaults.Theme)
+	setDefault("schema", schemaPath, config.ExpandHome(conf.Defaults.Schema))
+
+	var jumpAuth *Credentials
+
+	keys := map[string][]string{}
+	for action, k := range conf.Keys {
+		keys[action] = k
+	}
+
+	if command == "open" {
+		if len(commandArgs) != 1 {
+			fmt.Println("Error: open requires a profile name")
+			flag.Usage()
+			os.Exit(1)
+		}
+		profile, ok := conf.Hosts[commandArgs[0]]
+		if !ok {
+			fmt.Printf("Error: no profile %s in %s\n", commandArgs[0], *configPath)
+			os.Exit(1)
+		}
+
+		setDefault("hostname", hostname, profile.Endpoint())
+		setDefault("username", username, profile.User)
+		setDefault("password", password, profile.Password)
+		setDefault("file", fileName, profile.DBPath)
+		setDefault("transport", transport, profile.Transport)
+		setDefault("jump-host", jumpHost, jumpAddress(conf, profile.JumpHost))
+		if !set["sudo"] {
+			*sudo = profile.Sudo
+		}
+
+		auth := profileAuth(commandArgs[0], profile)
+		setDefault("key-file", keyFile, auth.KeyFile)
+		if !set["agent"] && auth.Agent {
+			*agent = true
+		}
+
+		// A jump host profile has its own credentials
+		if jump, ok := conf.Hosts[profile.JumpHost]; ok && !set["jump-host"] {
+			jumpAuth = profileAuth(profile.JumpHost, jump)
+		}
+
+		if *fileName == "" && !*dump {
+			*fileName = DefaultDBPath
+		}
+
+		// The profile only sets the flags
+		command = ""
+		commandArgs = []string{}
+	}
+
+	switch *transport {
+	case "auto", "sftp", "exec":
+	default:
+		fmt.Printf("Error: unknown transport %s\n", *transport)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if *dump {
+		if *hostname == "" {
+			fmt.Println("Error: -dump requires -hostname")
+			flag.Usage()
+			os.Exit(1)
+		}
+		// The file is not read, the name is only used in messages
+		if *fileName == "" {
+			*fileName = "pool database dump"
+		}
+	}
+
+	if *offline && *hostname == "" {
+		fmt.Println("Error: -offline requires -hostname")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if command != "" {
+		// Commands check their own arguments
+	} else if *live {
+		if *hostname == "" {
+			fmt.Println("Error: -hostname is required with -live")
+			flag.Usage()
+			os.Exit(1)
+		}
+		if *compare {
+			fmt.Println("Error: -compare cannot be used with -live")
+			flag.Usage()
+			os.Exit(1)
+		}
+		if *validate {
+			fmt.Println("Error: -validate cannot be used with -live")
+			flag.Usage()
+			os.Exit(1)
+		}
+		if *recoverDB {
+			fmt.Println("Error: -recover cannot be used with -live")
+			flag.Usage()
+			os.Exit(1)
+		}
+	} else if *fileName == "" && !*offline && !*dump {
 		fmt.Println("Error: -file is required")
 		flag.Usage()
 		os.Exit(1)
 	}
+
+	if *lazy && ((*hostname != "" && !*offline) || *recoverDB || *watch) {
+		fmt.Println("Error: -lazy is for local files and snapshots and cannot be used with -recover or -watch")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if *pool && *hostname == "" {
+		fmt.Println("Error: -pool requires -hostname")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	hostList := []string{}
+	for _, h := range strings.Split(*hosts, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			hostList = append(hostList, h)
+		}
+	}
+
+	if *compare && *liveHostname == "" {
+		if *hostname == "" {
+			fmt.Println("Error: -compare requires -hostname or -live-hostname")
+			flag.Usage()
+			os.Exit(1)
+		}
+		*liveHostname = *hostname
+	}
+
 	return Args{
 		FileName: *fileName,
 		Username: *username,
 		Password: *password,
 		Hostname: *hostname,
+		Live:     *live,
+
+		Compare:      *compare,
+		LiveHostname: *liveHostname,
+		ReportOnly:   *reportOnly,
+		Validate:     *validate,
+
+		Hosts:    hostList,
+		Pool:     *pool,
+		Parallel: *parallel,
+
+		CacheDir: *cacheDir,
+		NoCache:  *noCache,
+		Offline:  *offline,
+
+		Recover: *recoverDB,
+		Lazy:    *lazy,
+
+		Watch:         *watch,
+		WatchInterval: *watchInterval,
+
+		Transport: *transport,
+		Gzip:      *gzip,
+		Dump:      *dump,
+
+		Sudo:         *sudo,
+		SudoPassword: *sudoPassword,
+
+		KeyFile:  *keyFile,
+		Agent:    *agent,
+		JumpHost: *jumpHost,
+		JumpAuth: jumpAuth,
+
+		Theme:  *themeName,
+		Schema: *schemaPath,
+		Keys:   keys,
+
+		Command:     command,
+		CommandArgs: commandArgs,
+	}
+}
+
+// Credentials are the authentication settings of a host profile.
+type Credentials struct {
+	Password string
+	KeyFile  string
+	Agent    bool
+}
+
+// profileAuth returns the credentials of a profile.
+func profileAuth(name string, profile config.Host) *Credentials {
+	auth := &Credentials{Password: profile.Password}
+	switch profile.Auth {
+	case "", "password":
+	case "key":
+		auth.KeyFile = config.ExpandHome(profile.KeyFile)
+	case "agent":
+		auth.Agent = true
+	default:
+		fmt.Printf("Error: unknown auth %s in profile %s\n", profile.Auth, name)
+		os.Exit(1)
+	}
+	return auth
+}
+
+// jumpAddress returns the address of the jump host which is either the name
+// of a profile or an address.
+func jumpAddress(conf *config.Config, jump string) string {
+	profile, ok := conf.Hosts[jump]
+	if !ok {
+		return jump
+	}
+	if profile.User == "" {
+		return profile.Endpoint()
 	}
+	return profile.User + "@" + profile.Endpoint()
 }
@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fetched databases are stored as:
+//
+//	<dir>/<host>/<fetch time>-<generation count>.xml
+//
+// for example ~/.cache/readxapidb/xenhost/20250331T150019Z-24400.xml. The
+// time is in UTC so snapshots of a host sort by name. Each snapshot has an
+// index (.idx) to reopen it quickly.
+
+const timeFormat = "20060102T150405Z"
+
+type Cache struct {
+	Dir string
+}
+
+type Snapshot struct {
+	Host       string
+	Time       time.Time
+	Generation int64 // -1 if unknown
+	Path       string
+	Size       int64
+}
+
+// DefaultDir returns the cache directory of the user (~/.cache/readxapidb
+// on Linux).
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "readxapidb")
+}
+
+// hostDir returns the directory of the host. Characters that could be
+// problematic in a path (port separator, IPv6 colons, ...) are replaced.
+func (c *Cache) hostDir(host string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '[', ']':
+			return '_'
+		}
+		return r
+	}, host)
+	return filepath.Join(c.Dir, safe)
+}
+
+// Save stores data fetched from host.
+func (c *Cache) Save(host string, data []byte, generation int64) (Snapshot, error) {
+	dir := c.hostDir(host)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return Snapshot{}, err
+	}
+
+	s := Snapshot{
+		Host:       host,
+		Time:       time.Now().UTC().Truncate(time.Second),
+		Generation: generation,
+		Size:       int64(len(data)),
+	}
+	s.Path = filepath.Join(dir, fmt.Sprintf("%s-%d.xml", s.Time.Format(timeFormat), generation))
+
+	// The database contains secrets, keep it private
+	if err := os.WriteFile(s.Path, data, 0o600); err != nil {
+		return Snapshot{}, err
+	}
+
+	return s, nil
+}
+
+// Hosts returns the hosts that have snapshots.
+func (c *Cache) Hosts() ([]string, error) {
+	entries, err := os.ReadDir(c.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := []string{}
+	for _, e := range entries {
+		if e.IsDir() {
+			hosts = append(hosts, e.Name())
+		}
+	}
+
+	return hosts, nil
+}
+
+// List returns the snapshots of the host, the most recent first.
+func (c *Cache) List(host string) ([]Snapshot, error) {
+	dir := c.hostDir(host)
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []Snapshot{}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".xml")
+		if !ok || e.IsDir() {
+			continue
+		}
+
+		ts, gen, ok := strings.Cut(name, "-")
+		if !ok {
+			continue
+		}
+
+		t, err := time.Parse(timeFormat, ts)
+		if err != nil {
+			continue
+		}
+
+		generation, err := strconv.ParseInt(gen, 10, 64)
+		if err != nil {
+			generation = -1
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		snapshots = append(snapshots, Snapshot{
+			Host:       host,
+			Time:       t,
+			Generation: generation,
+			Path:       filepath.Join(dir, e.Name()),
+			Size:       info.Size(),
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.After(snapshots[j].Time)
+	})
+
+	return snapshots, nil
+}
+
+// Latest returns the most recent snapshot of the host.
+func (c *Cache) Latest(host string) (Snapshot, error) {
+	snapshots, err := c.List(host)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if len(snapshots) == 0 {
+		return Snapshot{}, fmt.Errorf("no snapshot of %s in %s", host, c.Dir)
+	}
+	return snapshots[0], nil
+}
+
+// Prune removes all snapshots of the host except the keep most recent ones
+// and returns the removed snapshots.
+func (c *Cache) Prune(host string, keep int) ([]Snapshot, error) {
+	snapshots, err := c.List(host)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) <= keep {
+		return nil, nil
+	}
+
+	removed := []Snapshot{}
+	for _, s := range snapshots[max(keep, 0):] {
+		if err := os.Remove(s.Path); err != nil {
+			return removed, err
+		}
+		if err := os.Remove(IndexPath(s.Path)); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed = append(removed, s)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// snapshots creates files named like snapshots in the directory of host.
+func snapshots(t *testing.T, c *Cache, host string, names ...string) {
+	t.Helper()
+	dir := c.hostDir(host)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func names(list []Snapshot) string {
+	var out []string
+	for _, s := range list {
+		out = append(out, filepath.Base(s.Path))
+	}
+	return strings.Join(out, " ")
+}
+
+func TestList(t *testing.T) {
+	c := &Cache{Dir: t.TempDir()}
+	snapshots(t, c, "xcp1",
+		"20250331T150019Z-24400.xml",
+		"20250401T080000Z-24500.xml",
+		"20250330T000000Z-x.xml",
+		"20250401T080000Z-24500.idx",
+		"notes.txt",
+		"yesterday-12.xml",
+	)
+	if err := os.Mkdir(filepath.Join(c.hostDir("xcp1"), "20250402T000000Z-1.xml"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := c.List("xcp1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(list), "20250401T080000Z-24500.xml 20250331T150019Z-24400.xml 20250330T000000Z-x.xml"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	for i, want := range []Snapshot{
+		{Host: "xcp1", Time: time.Date(2025, 4, 1, 8, 0, 0, 0, time.UTC), Generation: 24500, Size: 26},
+		{Host: "xcp1", Time: time.Date(2025, 3, 31, 15, 0, 19, 0, time.UTC), Generation: 24400, Size: 26},
+		{Host: "xcp1", Time: time.Date(2025, 3, 30, 0, 0, 0, 0, time.UTC), Generation: -1, Size: 22},
+	} {
+		got := list[i]
+		got.Path = ""
+		if got != want {
+			t.Errorf("snapshot %d: got %+v, want %+v", i, got, want)
+		}
+	}
+
+	if list, err := c.List("unknown"); err != nil || len(list) != 0 {
+		t.Errorf("unknown host: got %v, %v", list, err)
+	}
+}
+
+func TestSave(t *testing.T) {
+	c := &Cache{Dir: t.TempDir()}
+
+	s, err := c.Save("[fe80::1]:22", []byte("<database/>"), 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir := filepath.Base(filepath.Dir(s.Path)); dir != "_fe80__1__22" {
+		t.Errorf("saved in %s", dir)
+	}
+
+	latest, err := c.Latest("[fe80::1]:22")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != s {
+		t.Errorf("got %+v, want %+v", latest, s)
+	}
+	if info, err := os.Stat(s.Path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("snapshot mode: %v %v", info, err)
+	}
+
+	if hosts, err := c.Hosts(); err != nil || len(hosts) != 1 || hosts[0] != "_fe80__1__22" {
+		t.Errorf("hosts: got %q, %v", hosts, err)
+	}
+}
+
+func TestLatestNone(t *testing.T) {
+	c := &Cache{Dir: t.TempDir()}
+	if _, err := c.Latest("xcp1"); err == nil {
+		t.Error("no error")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	for _, tt := range []struct {
+		keep    int
+		removed string
+		kept    string
+	}{
+		{3, "", "20250403T000000Z-3.xml 20250402T000000Z-2.xml 20250401T000000Z-1.xml"},
+		{1, "20250402T000000Z-2.xml 20250401T000000Z-1.xml", "20250403T000000Z-3.xml"},
+		{0, "20250403T000000Z-3.xml 20250402T000000Z-2.xml 20250401T000000Z-1.xml", ""},
+		{-1, "20250403T000000Z-3.xml 20250402T000000Z-2.xml 20250401T000000Z-1.xml", ""},
+	} {
+		c := &Cache{Dir: t.TempDir()}
+		snapshots(t, c, "xcp1",
+			"20250401T000000Z-1.xml", "20250401T000000Z-1.idx",
+			"20250402T000000Z-2.xml",
+			"20250403T000000Z-3.xml", "20250403T000000Z-3.idx",
+		)
+
+		removed, err := c.Prune("xcp1", tt.keep)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := names(removed); got != tt.removed {
+			t.Errorf("keep %d: removed %s, want %s", tt.keep, got, tt.removed)
+		}
+
+		list, _ := c.List("xcp1")
+		if got := names(list); got != tt.kept {
+			t.Errorf("keep %d: kept %s, want %s", tt.keep, got, tt.kept)
+		}
+		for _, s := range removed {
+			if _, err := os.Stat(IndexPath(s.Path)); !os.IsNotExist(err) {
+				t.Errorf("keep %d: index of %s not removed", tt.keep, s.Path)
+			}
+		}
+	}
+}
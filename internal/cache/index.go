@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// The index of a snapshot is next to it with the .idx extension
+// (20250331T150019Z-24400.idx), see xapidb.Index.
+
+// IndexPath returns the path of the index of a database file.
+func IndexPath(path string) string {
+	return strings.TrimSuffix(path, ".xml") + ".idx"
+}
+
+// WriteIndex indexes the database file at path.
+func WriteIndex(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	ix, err := xapidb.BuildIndex(f, info.Size())
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(IndexPath(path), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = ix.WriteTo(out)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		// A partial index would be found invalid each time the file is
+		// opened
+		os.Remove(IndexPath(path))
+		return err
+	}
+	return nil
+}
+
+// OpenIndexed opens the database file at path with its index. It fails with
+// os.ErrNotExist if there is no index and xapidb.ErrStaleIndex if the file
+// changed since it was indexed. The file stays open until the database is
+// closed or replaced (xapidb.DB.Close).
+func OpenIndexed(path string) (*xapidb.DB, error) {
+	data, err := os.ReadFile(IndexPath(path))
+	if err != nil {
+		return nil, err
+	}
+	ix, err := xapidb.ReadIndex(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() != ix.Size {
+		f.Close()
+		return nil, xapidb.ErrStaleIndex
+	}
+	hash, err := xapidb.HashFile(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if hash != ix.Hash {
+		f.Close()
+		return nil, xapidb.ErrStaleIndex
+	}
+
+	db, err := xapidb.OpenIndexed(f, ix)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return db, nil
+}
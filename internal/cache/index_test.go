@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// example copies the example database in a temporary directory and parses
+// it to know its content.
+func example(t *testing.T) (string, *xapidb.DB) {
+	t.Helper()
+	data, err := os.ReadFile("../../examples/xapi-db.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "20250331T150019Z-24400.xml")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := xapidb.ParseXapiDB(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, db
+}
+
+func TestOpenIndexed(t *testing.T) {
+	path, want := example(t)
+
+	if _, err := OpenIndexed(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("without index: got %v", err)
+	}
+	if err := WriteIndex(path); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := OpenIndexed(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ref, row := range want.RefIndex {
+		n, ok := db.Lookup(ref)
+		if !ok {
+			t.Errorf("%s not found", ref)
+			continue
+		}
+		if n.Attr["uuid"] != row.Attr["uuid"] {
+			t.Errorf("%s: got uuid %s, want %s", ref, n.Attr["uuid"], row.Attr["uuid"])
+		}
+	}
+
+	if err := db.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOpenIndexedClosed(t *testing.T) {
+	path, want := example(t)
+	if err := WriteIndex(path); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := OpenIndexed(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	for ref := range want.RefIndex {
+		if _, ok := db.Lookup(ref); ok {
+			t.Errorf("%s loaded after closing the database", ref)
+		}
+	}
+}
+
+func TestOpenIndexedStale(t *testing.T) {
+	path, _ := example(t)
+	if err := WriteIndex(path); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("\n")
+	f.Close()
+
+	if _, err := OpenIndexed(path); !errors.Is(err, xapidb.ErrStaleIndex) {
+		t.Errorf("got %v, want a stale index", err)
+	}
+}
+
+func TestWriteIndexError(t *testing.T) {
+	path, _ := example(t)
+
+	// The index cannot be written over a directory
+	if err := os.Mkdir(IndexPath(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteIndex(path); err == nil {
+		t.Error("no error")
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Example of configuration:
+//
+//	[defaults]
+//	theme = "monokai"
+//	file = "/var/lib/xcp/state.db"
+//	schema = "~/.config/readxapidb/schema.json"
+//
+//	[keys]
+//	quit = ["q", "Ctrl-Q"]
+//	focus-switch = "Alt-Left"
+//
+//	[hosts.lab-pool-1]
+//	address = "10.1.2.3"
+//	user = "admin"
+//	auth = "key"               # password (default), key or agent
+//	key_file = "~/.ssh/id_ed25519"
+//	sudo = true
+//	jump_host = "bastion"      # a profile or [user@]host[:port]
+//
+//	[hosts.bastion]
+//	address = "bastion.example.com"
+//	port = 2222
+//	user = "jump"
+//	auth = "agent"             # the jump host uses its own credentials
+
+type Config struct {
+	Defaults Defaults        `toml:"defaults"`
+	Keys     map[string]Keys `toml:"keys"`
+	Hosts    map[string]Host `toml:"hosts"`
+}
+
+// Keys bound to an action, a single key can be given as a string.
+type Keys []string
+
+func (k *Keys) UnmarshalTOML(v any) error {
+	switch v := v.(type) {
+	case string:
+		*k = Keys{v}
+	case []any:
+		*k = make(Keys, 0, len(v))
+		for _, key := range v {
+			s, ok := key.(string)
+			if !ok {
+				return fmt.Errorf("invalid key %v", key)
+			}
+			*k = append(*k, s)
+		}
+	default:
+		return fmt.Errorf("invalid keys %v", v)
+	}
+	return nil
+}
+
+// Defaults are used when the flag is not given on the command line.
+type Defaults struct {
+	Theme     string `toml:"theme"`
+	File      string `toml:"file"`
+	Username  string `toml:"username"`
+	Transport string `toml:"transport"`
+	Schema    string `toml:"schema"`
+}
+
+type Host struct {
+	Address   string `toml:"address"`
+	Port      int    `toml:"port"`
+	User      string `toml:"user"`
+	Auth      string `toml:"auth"`
+	Password  string `toml:"password"`
+	KeyFile   string `toml:"key_file"`
+	DBPath    string `toml:"db_path"`
+	JumpHost  string `toml:"jump_host"`
+	Sudo      bool   `toml:"sudo"`
+	Transport string `toml:"transport"`
+}
+
+// DefaultPath returns ~/.config/readxapidb/config.toml on Linux.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "readxapidb", "config.toml")
+}
+
+// ThemesDir returns the directory of the theme files, next to the default
+// configuration.
+func ThemesDir() string {
+	path := DefaultPath()
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), "themes")
+}
+
+// Load reads the configuration. A missing file is an empty configuration.
+func Load(path string) (*Config, error) {
+	c := &Config{Hosts: map[string]Host{}}
+	if path == "" {
+		return c, nil
+	}
+
+	_, err := toml.DecodeFile(path, c)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Endpoint returns the address of the host with its port if it is not the
+// default one.
+func (h Host) Endpoint() string {
+	if h.Port == 0 || h.Port == 22 {
+		return h.Address
+	}
+	return fmt.Sprintf("%s:%d", h.Address, h.Port)
+}
+
+// ExpandHome replaces a leading ~ by the home directory.
+func ExpandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// State is what the UI remembers between runs. Unlike the configuration it
+// is written by the tool.
+type State struct {
+	// Columns shown in the grid view, by table name
+	Columns map[string][]string `json:"columns"`
+}
+
+// StatePath returns $XDG_STATE_HOME/readxapidb/state.json, XDG_STATE_HOME
+// defaults to ~/.local/state.
+func StatePath() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "readxapidb", "state.json")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "state", "readxapidb", "state.json")
+	}
+	return ""
+}
+
+// LoadState reads the state, a missing file is an empty state.
+func LoadState(path string) (*State, error) {
+	s := &State{Columns: map[string][]string{}}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Columns == nil {
+		s.Columns = map[string][]string{}
+	}
+
+	return s, nil
+}
+
+func (s *State) Save(path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,331 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+type Kind int
+
+const (
+	OnlyInA Kind = iota // the object is missing in B
+	OnlyInB             // the object is missing in A
+	FieldMismatch
+	LoadError // the rows of the table cannot be read
+)
+
+func (k Kind) String() string {
+	switch k {
+	case OnlyInA:
+		return "only in A"
+	case OnlyInB:
+		return "only in B"
+	case FieldMismatch:
+		return "mismatch"
+	case LoadError:
+		return "load error"
+	}
+	return "unknown"
+}
+
+type Finding struct {
+	Kind  Kind
+	Table string
+	Ref   string
+	Label string // name__label of the object if any, to help reading reports
+	Field string // only set for FieldMismatch
+	A     string // value in A (raw, as stored in the DB), or its load error
+	B     string // value in B, or its load error
+}
+
+type Report struct {
+	AName    string
+	BName    string
+	Findings []Finding
+}
+
+// Only tables present in both databases are compared.
+type Options struct {
+	// IgnoreFields are never compared, whatever the table.
+	IgnoreFields map[string]bool
+	// Rows of tables for which IgnoreTableFields returns true are checked
+	// for missing objects but their fields are not compared.
+	IgnoreTableFields func(table string) bool
+	// Strict also reports fields present on one side only. By default they
+	// are skipped as the API and the database don't expose exactly the same
+	// fields.
+	Strict bool
+}
+
+// DefaultOptions ignores the fields that change all the time and are not
+// relevant to know if two databases describe the same pool.
+func DefaultOptions() Options {
+	return Options{
+		IgnoreFields: map[string]bool{
+			"__ctime":      true,
+			"__mtime":      true,
+			"last_updated": true,
+		},
+		IgnoreTableFields: func(table string) bool {
+			return strings.HasSuffix(table, "_metrics")
+		},
+	}
+}
+
+// Compare reports objects missing on either side and fields whose value
+// differ. The tables not loaded yet are loaded first, the ones that cannot
+// be read are reported and not compared. Both databases are read locked
+// during the comparison.
+func Compare(a, b *xapidb.DB, opts Options) *Report {
+	errsA, errsB := load(a), load(b)
+
+	a.RLock()
+	defer a.RUnlock()
+	b.RLock()
+	defer b.RUnlock()
+
+	r := &Report{}
+
+	failed := map[string]bool{}
+	for name := range errsA {
+		failed[name] = true
+	}
+	for name := range errsB {
+		failed[name] = true
+	}
+	for _, name := range sortedKeys(failed) {
+		f := Finding{Kind: LoadError, Table: name}
+		if err := errsA[name]; err != nil {
+			f.A = err.Error()
+		}
+		if err := errsB[name]; err != nil {
+			f.B = err.Error()
+		}
+		r.Findings = append(r.Findings, f)
+	}
+
+	for _, name := range tableNames(a, b) {
+		// Only a part of the rows may have been read
+		if failed[name] {
+			continue
+		}
+
+		ta := table(a, name)
+		tb := table(b, name)
+
+		rowsA := rowsByRef(ta)
+		rowsB := rowsByRef(tb)
+
+		for _, ref := range sortedKeys(rowsA) {
+			rowA := rowsA[ref]
+			rowB, ok := rowsB[ref]
+			if !ok {
+				r.Findings = append(r.Findings, Finding{Kind: OnlyInA, Table: name, Ref: ref, Label: label(rowA)})
+				continue
+			}
+
+			if opts.IgnoreTableFields != nil && opts.IgnoreTableFields(name) {
+				continue
+			}
+			r.Findings = append(r.Findings, compareRows(name, ref, rowA, rowB, opts)...)
+		}
+
+		for _, ref := range sortedKeys(rowsB) {
+			if _, ok := rowsA[ref]; !ok {
+				r.Findings = append(r.Findings, Finding{Kind: OnlyInB, Table: name, Ref: ref, Label: label(rowsB[ref])})
+			}
+		}
+	}
+
+	return r
+}
+
+// load reads the rows of the tables of a database opened lazily and returns
+// the error of each table that cannot be read.
+func load(db *xapidb.DB) map[string]error {
+	db.RLock()
+	tables := slices.Clone(db.Root.Children)
+	db.RUnlock()
+
+	errs := map[string]error{}
+	for _, t := range tables {
+		if err := t.Load(); err != nil {
+			errs[t.Attr["name"]] = err
+		}
+	}
+	return errs
+}
+
+func compareRows(table, ref string, a, b *xapidb.Node, opts Options) []Finding {
+	findings := []Finding{}
+
+	fields := map[string]bool{}
+	for k := range a.Attr {
+		fields[k] = true
+	}
+	for k := range b.Attr {
+		fields[k] = true
+	}
+
+	for _, f := range sortedKeys(fields) {
+		if opts.IgnoreFields[f] {
+			continue
+		}
+
+		va, okA := a.Attr[f]
+		vb, okB := b.Attr[f]
+		if (!okA || !okB) && !opts.Strict {
+			continue
+		}
+
+		if okA != okB || !Equal(va, vb) {
+			findings = append(findings, Finding{
+				Kind:  FieldMismatch,
+				Table: table,
+				Ref:   ref,
+				Label: label(a),
+				Field: f,
+				A:     va,
+				B:     vb,
+			})
+		}
+	}
+
+	return findings
+}
+
+// Equal compares two raw values. Sets and maps are equal if they have the
+// same elements whatever their order and numbers are compared by value
+// (1 and 1.000 are equal).
+func Equal(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	a = xapidb.UnescapeValue(a)
+	b = xapidb.UnescapeValue(b)
+
+	if fa, err := strconv.ParseFloat(a, 64); err == nil {
+		if fb, err := strconv.ParseFloat(b, 64); err == nil {
+			return fa == fb
+		}
+	}
+
+	ea, errA := xapidb.ParseSExpr(a)
+	eb, errB := xapidb.ParseSExpr(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+
+	return normalize(ea) == normalize(eb)
+}
+
+// normalize returns the s-expression with elements sorted.
+func normalize(e xapidb.SExpr) string {
+	if !e.IsList {
+		return e.String()
+	}
+
+	items := make([]string, 0, len(e.List))
+	for _, item := range e.List {
+		items = append(items, normalize(item))
+	}
+	sort.Strings(items)
+
+	return "(" + strings.Join(items, " ") + ")"
+}
+
+func tableNames(a, b *xapidb.DB) []string {
+	inA := map[string]bool{}
+	for _, t := range a.Root.Children {
+		if t.Name == "table" {
+			inA[t.Attr["name"]] = true
+		}
+	}
+
+	names := []string{}
+	for _, t := range b.Root.Children {
+		if t.Name == "table" && inA[t.Attr["name"]] {
+			names = append(names, t.Attr["name"])
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func table(db *xapidb.DB, name string) *xapidb.Node {
+	for _, t := range db.Root.Children {
+		if t.Name == "table" && strings.EqualFold(t.Attr["name"], name) {
+			return t
+		}
+	}
+	return nil
+}
+
+func rowsByRef(table *xapidb.Node) map[string]*xapidb.Node {
+	rows := map[string]*xapidb.Node{}
+	if table == nil {
+		return rows
+	}
+	for _, row := range table.Children {
+		rows[row.Attr["ref"]] = row
+	}
+	return rows
+}
+
+func label(row *xapidb.Node) string {
+	return xapidb.UnescapeValue(row.Attr["name__label"])
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Print writes the report in a human readable form.
+func (r *Report) Print(w io.Writer) {
+	if len(r.Findings) == 0 {
+		fmt.Fprintf(w, "%s and %s match\n", r.AName, r.BName)
+		return
+	}
+
+	fmt.Fprintf(w, "A: %s\nB: %s\n\n", r.AName, r.BName)
+
+	for _, f := range r.Findings {
+		name := f.Ref
+		if f.Label != "" {
+			name += " (" + f.Label + ")"
+		}
+
+		switch f.Kind {
+		case OnlyInA:
+			fmt.Fprintf(w, "- %s %s: missing in B\n", f.Table, name)
+		case OnlyInB:
+			fmt.Fprintf(w, "+ %s %s: missing in A\n", f.Table, name)
+		case FieldMismatch:
+			fmt.Fprintf(w, "~ %s %s: %s\n    A: %s\n    B: %s\n", f.Table, name, f.Field,
+				xapidb.UnescapeValue(f.A), xapidb.UnescapeValue(f.B))
+		case LoadError:
+			fmt.Fprintf(w, "! %s: rows cannot be read\n", f.Table)
+			if f.A != "" {
+				fmt.Fprintf(w, "    A: %s\n", f.A)
+			}
+			if f.B != "" {
+				fmt.Fprintf(w, "    B: %s\n", f.B)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d difference(s)\n", len(r.Findings))
+}
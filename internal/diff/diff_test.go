@@ -0,0 +1,163 @@
+package diff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+func TestEqual(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want bool
+	}{
+		{"debian", "debian", true},
+		{"debian", "ubuntu", false},
+		{"1", "1.000", true},
+		{"1", "2", false},
+		{"1e3", "1000", true},
+		{"debian%.12", "debian 12", true},
+		{"('a'%.'b')", "('b'%.'a')", true},
+		{"('a'%.'b')", "('a')", false},
+		{"(('k1'%.'v1')%.('k2'%.'v2'))", "(('k2'%.'v2')%.('k1'%.'v1'))", true},
+		{"(('k1'%.'v1'))", "(('k1'%.'v2'))", false},
+		{"()", "()", true},
+		{"()", "", false},
+		{"(unbalanced", "(unbalanced", true},
+		{"(unbalanced", "(other", false},
+	} {
+		if got := Equal(tt.a, tt.b); got != tt.want {
+			t.Errorf("Equal(%q, %q) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+const dbA = `<?xml version="1.0" encoding="UTF-8"?>
+<database>
+  <table name="VM">
+    <row ref="OpaqueRef:vm1" name__label="vm1" memory__static_max="1024" tags="('a'%.'b')" __mtime="1"/>
+    <row ref="OpaqueRef:vm2" name__label="vm2" power_state="Running"/>
+  </table>
+  <table name="VM_metrics">
+    <row ref="OpaqueRef:m1" VCPUs__number="1"/>
+  </table>
+  <table name="SR">
+    <row ref="OpaqueRef:sr1" name__label="local"/>
+  </table>
+</database>`
+
+const dbB = `<?xml version="1.0" encoding="UTF-8"?>
+<database>
+  <table name="VM">
+    <row ref="OpaqueRef:vm1" name__label="vm1" memory__static_max="2048" tags="('b'%.'a')" __mtime="2" uuid="u1"/>
+    <row ref="OpaqueRef:vm3" name__label="vm3"/>
+  </table>
+  <table name="VM_metrics">
+    <row ref="OpaqueRef:m1" VCPUs__number="2"/>
+  </table>
+</database>`
+
+func parse(t *testing.T, data string) *xapidb.DB {
+	t.Helper()
+	db, err := xapidb.ParseXapiDB([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// findings returns the findings in a compact form to compare them.
+func findings(r *Report) []string {
+	out := []string{}
+	for _, f := range r.Findings {
+		out = append(out, strings.Join([]string{f.Kind.String(), f.Table, f.Ref, f.Field}, " "))
+	}
+	return out
+}
+
+func TestCompare(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		strict bool
+		want   []string
+	}{
+		{
+			name: "default",
+			want: []string{
+				"mismatch VM OpaqueRef:vm1 memory__static_max",
+				"only in A VM OpaqueRef:vm2 ",
+				"only in B VM OpaqueRef:vm3 ",
+			},
+		},
+		{
+			name:   "strict",
+			strict: true,
+			want: []string{
+				"mismatch VM OpaqueRef:vm1 memory__static_max",
+				"mismatch VM OpaqueRef:vm1 uuid",
+				"only in A VM OpaqueRef:vm2 ",
+				"only in B VM OpaqueRef:vm3 ",
+			},
+		},
+	} {
+		opts := DefaultOptions()
+		opts.Strict = tt.strict
+
+		got := findings(Compare(parse(t, dbA), parse(t, dbB), opts))
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCompareSame(t *testing.T) {
+	r := Compare(parse(t, dbA), parse(t, dbA), DefaultOptions())
+	r.AName, r.BName = "a.db", "a.db"
+	if len(r.Findings) != 0 {
+		t.Errorf("got %q", findings(r))
+	}
+
+	var out strings.Builder
+	r.Print(&out)
+	if out.String() != "a.db and a.db match\n" {
+		t.Errorf("got %q", out.String())
+	}
+}
+
+func TestCompareLazy(t *testing.T) {
+	// The SR table of A cannot be read
+	broken := strings.Replace(dbA, `<row ref="OpaqueRef:sr1" name__label="local"/>`,
+		`<row ref="OpaqueRef:sr1" name__label="local"></rowx>`, 1)
+
+	a, err := xapidb.OpenXapiDB(bytes.NewReader([]byte(broken)), int64(len(broken)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr := strings.Replace(dbB, `</database>`, `  <table name="SR">
+    <row ref="OpaqueRef:sr1" name__label="local"/>
+  </table>
+</database>`, 1)
+	b, err := xapidb.OpenXapiDB(bytes.NewReader([]byte(sr)), int64(len(sr)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := Compare(a, b, DefaultOptions())
+
+	// The rows not loaded yet are compared, the SR rows are not reported
+	// as missing in A
+	want := []string{
+		"load error SR  ",
+		"mismatch VM OpaqueRef:vm1 memory__static_max",
+		"only in A VM OpaqueRef:vm2 ",
+		"only in B VM OpaqueRef:vm3 ",
+	}
+	if got := findings(r); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if f := r.Findings[0]; !strings.Contains(f.A, "rowx") || f.B != "" {
+		t.Errorf("load error: got A %q, B %q", f.A, f.B)
+	}
+}
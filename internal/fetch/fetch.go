@@ -1,13 +1,57 @@
 package fetch
 
-import "example.com/readxapidb/internal/args"
+import (
+	"context"
+
+	"example.com/readxapidb/internal/args"
+	"example.com/readxapidb/internal/cache"
+)
 
 func DB(a args.Args) ([]byte, error) {
+	return DBContext(context.Background(), a, nil)
+}
+
+// DBContext is like DB but remote downloads can be cancelled and report
+// their progress.
+func DBContext(ctx context.Context, a args.Args, progress func(Progress)) ([]byte, error) {
+	if a.Offline {
+		// Reopen the last database fetched from the host
+		c := cache.Cache{Dir: a.CacheDir}
+		s, err := c.Latest(a.Hostname)
+		if err != nil {
+			return nil, err
+		}
+		return Local(s.Path)
+	}
+
 	if a.Hostname == "" {
 		// Local database is used
 		return Local(a.FileName)
 	}
 
-	return FileSFTP(a.Username, a.Password, a.Hostname, a.FileName)
+	return FileSSH(ctx, SSHOptionsFrom(a, a.Hostname), progress)
+}
+
+// SSHOptionsFrom returns the options to fetch the database of host.
+func SSHOptionsFrom(a args.Args, host string) SSHOptions {
+	o := SSHOptions{
+		Username:  a.Username,
+		Password:  a.Password,
+		Host:      host,
+		Path:      a.FileName,
+		Transport: Transport(a.Transport),
+		Gzip:      a.Gzip,
+		Dump:      a.Dump,
+
+		Sudo:         a.Sudo,
+		SudoPassword: a.SudoPassword,
 
+		KeyFile:  a.KeyFile,
+		Agent:    a.Agent,
+		JumpHost: a.JumpHost,
+	}
+	if j := a.JumpAuth; j != nil {
+		o.JumpAuth = &JumpAuth{Password: j.Password, KeyFile: j.KeyFile, Agent: j.Agent}
+	}
+	return o
 }
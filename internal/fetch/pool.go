@@ -0,0 +1,67 @@
+package fetch
+
+import (
+	"context"
+	"sync"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+type HostResult struct {
+	Host string
+	Data []byte
+	Err  error
+}
+
+// Hosts fetches the database from all hosts using at most parallel
+// connections at the same time. o gives the options common to all hosts.
+// Results are in the same order as hosts and errors are reported per host.
+func Hosts(o SSHOptions, hosts []string, parallel int) []HostResult {
+	results := make([]HostResult, len(hosts))
+	sem := make(chan struct{}, max(parallel, 1))
+
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			ho := o
+			ho.Host = host
+			data, err := FileSSH(context.Background(), ho, nil)
+			results[i] = HostResult{Host: host, Data: data, Err: err}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// PoolMembers returns the address of all hosts of the pool except the
+// master, as found in the host table.
+func PoolMembers(db *xapidb.DB) []string {
+	master := ""
+	if pool := db.Table("pool"); pool != nil && len(pool.Children) > 0 {
+		master = pool.Children[0].Attr["master"]
+	}
+
+	table := db.Table("host")
+	if table == nil {
+		return nil
+	}
+
+	addresses := []string{}
+	for _, row := range table.Children {
+		if row.Attr["ref"] == master {
+			continue
+		}
+		if addr := row.Attr["address"]; addr != "" {
+			addresses = append(addresses, addr)
+		}
+	}
+
+	return addresses
+}
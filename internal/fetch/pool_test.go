@@ -0,0 +1,87 @@
+package fetch
+
+import (
+	"bytes"
+	"net"
+	"slices"
+	"testing"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+func TestPoolMembers(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		db   string
+		want []string
+	}{
+		{
+			name: "pool",
+			db: `<database>
+  <table name="pool"><row ref="OpaqueRef:p" master="OpaqueRef:h1"/></table>
+  <table name="host">
+    <row ref="OpaqueRef:h1" address="10.0.0.1"/>
+    <row ref="OpaqueRef:h2" address="10.0.0.2"/>
+    <row ref="OpaqueRef:h3" address=""/>
+    <row ref="OpaqueRef:h4" address="10.0.0.4"/>
+  </table>
+</database>`,
+			want: []string{"10.0.0.2", "10.0.0.4"},
+		},
+		{
+			name: "no pool",
+			db: `<database>
+  <table name="host"><row ref="OpaqueRef:h1" address="10.0.0.1"/></table>
+</database>`,
+			want: []string{"10.0.0.1"},
+		},
+		{
+			name: "single host",
+			db: `<database>
+  <table name="pool"><row ref="OpaqueRef:p" master="OpaqueRef:h1"/></table>
+  <table name="host"><row ref="OpaqueRef:h1" address="10.0.0.1"/></table>
+</database>`,
+			want: []string{},
+		},
+		{
+			name: "no host table",
+			db:   `<database><table name="pool"/></database>`,
+		},
+	} {
+		db, err := xapidb.ParseXapiDB([]byte(tt.db))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := PoolMembers(db); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHosts(t *testing.T) {
+	// Nothing listens on the address of a closed listener
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := l.Addr().String()
+	l.Close()
+
+	hosts := []string{(&server{}).start(t), down, (&server{}).start(t)}
+	results := Hosts(options(""), hosts, 2)
+
+	if len(results) != len(hosts) {
+		t.Fatalf("got %d results, want %d", len(results), len(hosts))
+	}
+	for i, r := range results {
+		if r.Host != hosts[i] {
+			t.Errorf("result %d is for %s, want %s", i, r.Host, hosts[i])
+		}
+		if failed := r.Err != nil; failed != (r.Host == down) {
+			t.Errorf("%s: got error %v", r.Host, r.Err)
+		}
+		if r.Err == nil && !bytes.Equal(r.Data, stateDB) {
+			t.Errorf("%s: got %d bytes, want %d", r.Host, len(r.Data), len(stateDB))
+		}
+	}
+}
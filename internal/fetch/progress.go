@@ -0,0 +1,110 @@
+package fetch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+type Progress struct {
+	Done    int64
+	Total   int64 // -1 if unknown
+	Elapsed time.Duration
+	// Finished is set on the last call, once the whole file is received
+	Finished bool
+}
+
+// Rate returns the number of bytes received per second.
+func (p Progress) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Done) / p.Elapsed.Seconds()
+}
+
+// ETA returns the estimated remaining time, or -1 if it is unknown.
+func (p Progress) ETA() time.Duration {
+	rate := p.Rate()
+	if p.Total < 0 || rate == 0 {
+		return -1
+	}
+	return time.Duration(float64(p.Total-p.Done) / rate * float64(time.Second))
+}
+
+func (p Progress) String() string {
+	s := humanBytes(p.Done)
+	if p.Total >= 0 {
+		s += " / " + humanBytes(p.Total)
+	}
+	s += fmt.Sprintf("  %s/s", humanBytes(int64(p.Rate())))
+	if eta := p.ETA(); eta >= 0 && !p.Finished {
+		s += fmt.Sprintf("  ETA %s", eta.Round(time.Second))
+	}
+	return s
+}
+
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// newProgressWriter returns a writer appending to buf and reporting the
+// progress if it is not nil.
+func newProgressWriter(buf *bytes.Buffer, total int64, start time.Time, progress func(Progress)) io.Writer {
+	if progress == nil {
+		return buf
+	}
+	return &progressWriter{w: buf, total: total, start: start, progress: progress}
+}
+
+type progressWriter struct {
+	w        *bytes.Buffer
+	total    int64
+	start    time.Time
+	last     time.Time
+	progress func(Progress)
+}
+
+func (pw *progressWriter) Write(b []byte) (int, error) {
+	n, err := pw.w.Write(b)
+
+	// Don't flood the callback, a few updates per second are enough
+	now := time.Now()
+	if now.Sub(pw.last) >= 100*time.Millisecond || int64(pw.w.Len()) == pw.total {
+		pw.last = now
+		pw.progress(Progress{Done: int64(pw.w.Len()), Total: pw.total, Elapsed: now.Sub(pw.start)})
+	}
+
+	return n, err
+}
+
+// TerminalProgress returns a progress callback that prints the progress on
+// a single line of w (the terminal), ended when the transfer is finished.
+// end ends the line if the transfer failed after some progress was printed.
+func TerminalProgress(w io.Writer, name string) (progress func(Progress), end func()) {
+	open := false
+	progress = func(p Progress) {
+		fmt.Fprintf(w, "\r\033[KFetching %s: %s", name, p)
+		open = !p.Finished
+		if p.Finished {
+			fmt.Fprintln(w)
+		}
+	}
+	end = func() {
+		if open {
+			fmt.Fprintln(w)
+			open = false
+		}
+	}
+	return progress, end
+}
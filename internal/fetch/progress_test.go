@@ -0,0 +1,49 @@
+package fetch
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTerminalProgress(t *testing.T) {
+	for _, total := range []int64{-1, 2048} {
+		var out strings.Builder
+		progress, end := TerminalProgress(&out, "state.db")
+
+		progress(Progress{Done: 1024, Total: total, Elapsed: time.Second})
+		progress(Progress{Done: 2048, Total: total, Elapsed: 2 * time.Second})
+		if strings.Contains(out.String(), "\n") {
+			t.Errorf("total %d: line ended before the end of the transfer: %q", total, out.String())
+		}
+
+		progress(Progress{Done: 2048, Total: 2048, Elapsed: 2 * time.Second, Finished: true})
+		if !strings.HasSuffix(out.String(), "Fetching state.db: 2.0 KiB / 2.0 KiB  1.0 KiB/s\n") {
+			t.Errorf("total %d: got %q", total, out.String())
+		}
+
+		// The line is already ended
+		end()
+		if strings.HasSuffix(out.String(), "\n\n") {
+			t.Errorf("total %d: line ended twice: %q", total, out.String())
+		}
+	}
+}
+
+func TestTerminalProgressEnd(t *testing.T) {
+	var out strings.Builder
+	progress, end := TerminalProgress(&out, "state.db")
+
+	// Nothing was printed, the error message goes on the first line
+	end()
+	if out.String() != "" {
+		t.Errorf("got %q", out.String())
+	}
+
+	progress(Progress{Done: 1024, Total: 2048, Elapsed: time.Second})
+	end()
+	end()
+	if !strings.HasSuffix(out.String(), "1.0 KiB/s  ETA 1s\n") || strings.Count(out.String(), "\n") != 1 {
+		t.Errorf("got %q", out.String())
+	}
+}
@@ -1,39 +1,97 @@
 package fetch
 
 import (
-	"io"
-	"time"
+	"context"
+	"fmt"
+	"net"
+	"strings"
 
-	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
 )
 
 func FileSFTP(username, password, host, filePath string) ([]byte, error) {
-	config := &ssh.ClientConfig{
-		User: username,
-		Auth: []ssh.AuthMethod{
-			ssh.Password(password),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Timeout:         5 * time.Second,
+	return FileSFTPContext(context.Background(), username, password, host, filePath, nil)
+}
+
+// FileSFTPContext downloads the file using SFTP, see FileSSH.
+func FileSFTPContext(
+	ctx context.Context,
+	username, password, host, filePath string,
+	progress func(Progress),
+) ([]byte, error) {
+	return FileSSH(ctx, SSHOptions{
+		Username:  username,
+		Password:  password,
+		Host:      host,
+		Path:      filePath,
+		Transport: TransportSFTP,
+	}, progress)
+}
+
+// dialSSH connects to host, through jump if it is not empty. The jump host is
+// authenticated with jumpConfig, or with the same methods as host if it is
+// nil. The user given in jump ([user@]host[:port]) takes precedence.
+func dialSSH(ctx context.Context, host, jump string, config, jumpConfig *ssh.ClientConfig) (*ssh.Client, error) {
+	addr := sshAddr(host)
+
+	if jump == "" {
+		d := net.Dialer{Timeout: config.Timeout}
+		netConn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		return newSSHClient(netConn, addr, config)
 	}
 
-	conn, err := ssh.Dial("tcp", host+":22", config)
+	if jumpConfig == nil {
+		jumpConfig = config
+	}
+	jc := *jumpConfig
+	if user, h, ok := strings.Cut(jump, "@"); ok {
+		jc.User = user
+		jump = h
+	}
+
+	jumpClient, err := dialSSH(ctx, jump, "", &jc, nil)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("jump host %s: %w", jump, err)
 	}
 
-	sftpClient, err := sftp.NewClient(conn)
+	netConn, err := jumpClient.DialContext(ctx, "tcp", addr)
 	if err != nil {
+		jumpClient.Close()
+		return nil, fmt.Errorf("jump host %s: %w", jump, err)
+	}
+
+	client, err := newSSHClient(netConn, addr, config)
+	if err != nil {
+		jumpClient.Close()
 		return nil, err
 	}
-	defer sftpClient.Close()
 
-	f, err := sftpClient.Open(filePath)
+	// The jump connection lives as long as the connection to the host
+	go func() {
+		client.Wait()
+		jumpClient.Close()
+	}()
+
+	return client, nil
+}
+
+// sshAddr returns host with the default port if it has none (host:2222).
+func sshAddr(host string) string {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		return net.JoinHostPort(host, "22")
+	}
+	return host
+}
+
+func newSSHClient(netConn net.Conn, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	c, chans, reqs, err := ssh.NewClientConn(netConn, addr, config)
 	if err != nil {
+		netConn.Close()
 		return nil, err
 	}
-	defer f.Close()
 
-	return io.ReadAll(f)
+	return ssh.NewClient(c, chans, reqs), nil
 }
@@ -0,0 +1,393 @@
+package fetch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+type Transport string
+
+const (
+	// TransportAuto uses SFTP and falls back to exec if the SFTP subsystem
+	// is disabled on the host.
+	TransportAuto Transport = "auto"
+	TransportSFTP Transport = "sftp"
+	// TransportExec reads the file with commands run on the host (cat).
+	TransportExec Transport = "exec"
+)
+
+type SSHOptions struct {
+	Username  string
+	Password  string
+	Host      string
+	Path      string
+	Transport Transport
+	// Gzip compresses the file on the host before sending it (exec only).
+	Gzip bool
+	// Dump gets a consistent dump with "xe pool-dump-database" instead of
+	// reading Path (exec only).
+	Dump bool
+	// Sudo runs the commands with sudo to read files owned by root when
+	// root logins are forbidden (exec only). SudoPassword defaults to
+	// Password, if both are empty sudo must not ask for a password
+	// (NOPASSWD).
+	Sudo         bool
+	SudoPassword string
+	// KeyFile and Agent add public key authentications to the password.
+	KeyFile string
+	Agent   bool
+	// JumpHost ([user@]host[:port]) is an SSH host used to reach Host. It
+	// is authenticated with JumpAuth, or like Host if JumpAuth is nil.
+	JumpHost string
+	JumpAuth *JumpAuth
+}
+
+// JumpAuth are the credentials of a jump host which has its own profile.
+type JumpAuth struct {
+	Password string
+	KeyFile  string
+	Agent    bool
+}
+
+var (
+	ErrAuthFailed       = errors.New("SSH authentication failed")
+	ErrSudoFailed       = errors.New("sudo failed")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// command returns the command to run, with sudo if needed, and what must
+// be sent on its standard input.
+func (o SSHOptions) command(cmd string) (string, io.Reader) {
+	if !o.Sudo {
+		return cmd, nil
+	}
+
+	password := o.SudoPassword
+	if password == "" {
+		password = o.Password
+	}
+
+	// Run through sh as the command can be a pipeline
+	cmd = "sh -c " + shellQuote(cmd)
+	if password == "" {
+		return "sudo -n " + cmd, nil
+	}
+	// -S reads the password from stdin, -p '' removes the prompt
+	return "sudo -S -p '' " + cmd, strings.NewReader(password + "\n")
+}
+
+// authMethods returns the key based methods first as they don't count as
+// failed password attempts. done must be called once connected.
+func authMethods(password, keyFile string, useAgent bool) (auth []ssh.AuthMethod, done func(), err error) {
+	done = func() {}
+
+	if keyFile != "" {
+		key, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", keyFile, err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	if useAgent {
+		sock := os.Getenv("SSH_AUTH_SOCK")
+		if sock == "" {
+			return nil, nil, errors.New("SSH agent is not running (SSH_AUTH_SOCK is not set)")
+		}
+		conn, err := net.Dial("unix", sock)
+		if err != nil {
+			return nil, nil, err
+		}
+		auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		done = func() { conn.Close() }
+	}
+
+	if password != "" || len(auth) == 0 {
+		auth = append(auth, ssh.Password(password))
+	}
+
+	return auth, done, nil
+}
+
+// classify wraps err with the sentinel error matching the output of a
+// failed command.
+func classify(err error, stderr string) error {
+	switch {
+	case strings.Contains(stderr, "incorrect password"),
+		strings.Contains(stderr, "a password is required"),
+		strings.Contains(stderr, "not in the sudoers"),
+		strings.Contains(stderr, "is not allowed to execute"),
+		strings.Contains(stderr, "sudo: command not found"):
+		return fmt.Errorf("%w: %w", ErrSudoFailed, err)
+	case strings.Contains(stderr, "Permission denied"):
+		return fmt.Errorf("%w (try -sudo): %w", ErrPermissionDenied, err)
+	}
+	return err
+}
+
+// Number of times the download is resumed after a connection failure.
+const sshRetries = 3
+
+// fileVersion identifies the content of the remote file: a download is
+// only resumed if it has not changed.
+type fileVersion struct {
+	size  int64
+	mtime int64
+}
+
+// resume returns the offset where the download continues: buf is emptied
+// unless the file is in the version prev that was partially read. ok is
+// false if the version cur could not be read.
+func resume(buf *bytes.Buffer, prev *fileVersion, cur fileVersion, ok bool) int64 {
+	if !ok {
+		// No file has this size, it won't match the next time either
+		cur = fileVersion{size: -1}
+	}
+	if !ok || cur != *prev {
+		buf.Reset()
+	}
+	*prev = cur
+	return int64(buf.Len())
+}
+
+// FileSSH downloads the file, calling progress (if not nil) while data is
+// received. The download is resumed where it stopped if the connection is
+// lost and the file is unchanged, otherwise it starts again. Cancelling the
+// context aborts the download.
+func FileSSH(ctx context.Context, o SSHOptions, progress func(Progress)) ([]byte, error) {
+	var buf bytes.Buffer
+	var version fileVersion
+	start := time.Now()
+
+	var err error
+	for attempt := 0; attempt <= sshRetries; attempt++ {
+		err = readSSH(ctx, o, &buf, &version, start, progress)
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+
+		// Nothing received, retrying won't help (auth failure, no such file, ...)
+		if buf.Len() == 0 {
+			break
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if progress != nil {
+		done := int64(buf.Len())
+		progress(Progress{Done: done, Total: done, Elapsed: time.Since(start), Finished: true})
+	}
+	return buf.Bytes(), nil
+}
+
+// readSSH appends the content of the file starting at the length of buf if
+// it is still in the version read before.
+func readSSH(
+	ctx context.Context,
+	o SSHOptions,
+	buf *bytes.Buffer,
+	version *fileVersion,
+	start time.Time,
+	progress func(Progress),
+) error {
+	auth, done, err := authMethods(o.Password, o.KeyFile, o.Agent)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	config := &ssh.ClientConfig{
+		User:            o.Username,
+		Auth:            auth,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	}
+
+	var jumpConfig *ssh.ClientConfig
+	if o.JumpHost != "" && o.JumpAuth != nil {
+		jumpAuth, jumpDone, err := authMethods(o.JumpAuth.Password, o.JumpAuth.KeyFile, o.JumpAuth.Agent)
+		if err != nil {
+			return fmt.Errorf("jump host %s: %w", o.JumpHost, err)
+		}
+		defer jumpDone()
+
+		c := *config
+		c.Auth = jumpAuth
+		jumpConfig = &c
+	}
+
+	conn, err := dialSSH(ctx, o.Host, o.JumpHost, config, jumpConfig)
+	if err != nil {
+		if strings.Contains(err.Error(), "unable to authenticate") {
+			return fmt.Errorf("%w for %s@%s: %w", ErrAuthFailed, o.Username, o.Host, err)
+		}
+		return err
+	}
+	defer conn.Close()
+
+	// Closing the connection aborts all pending requests
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if o.Transport == TransportExec || o.Dump || o.Sudo {
+		return readExec(conn, o, buf, version, start, progress)
+	}
+
+	sftpClient, err := sftp.NewClient(conn, sftp.UseConcurrentReads(true))
+	if err != nil {
+		if o.Transport == TransportSFTP {
+			return fmt.Errorf("SFTP is not available on %s (try the exec transport): %w", o.Host, err)
+		}
+		// The SFTP subsystem is probably disabled on this host
+		return readExec(conn, o, buf, version, start, progress)
+	}
+	defer sftpClient.Close()
+
+	f, err := sftpClient.Open(o.Path)
+	if errors.Is(err, os.ErrPermission) {
+		return fmt.Errorf("%w (try -sudo): %s: %w", ErrPermissionDenied, o.Path, err)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var total int64 = -1
+	var cur fileVersion
+	info, statErr := f.Stat()
+	if statErr == nil {
+		total = info.Size()
+		cur = fileVersion{size: info.Size(), mtime: info.ModTime().Unix()}
+	}
+
+	offset := resume(buf, version, cur, statErr == nil)
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	_, err = f.WriteTo(newProgressWriter(buf, total, start, progress))
+	return err
+}
+
+// readExec reads the file using commands run on the host.
+func readExec(
+	conn *ssh.Client,
+	o SSHOptions,
+	buf *bytes.Buffer,
+	version *fileVersion,
+	start time.Time,
+	progress func(Progress),
+) error {
+	var cmd string
+	var total int64 = -1
+
+	if o.Dump {
+		// Each dump is different so it cannot be resumed
+		buf.Reset()
+
+		send := `cat "$f"`
+		if o.Gzip {
+			send = `gzip -c "$f"`
+		}
+		cmd = `f=$(mktemp) && xe pool-dump-database file-name="$f" >/dev/null && ` + send + `; rc=$?; rm -f "$f"; exit $rc`
+	} else {
+		var cur fileVersion
+		ok := false
+		if out, err := runExec(conn, o, "stat -c '%s %Y' -- "+shellQuote(o.Path)); err == nil {
+			_, err := fmt.Sscan(string(out), &cur.size, &cur.mtime)
+			ok = err == nil
+		}
+		if ok {
+			total = cur.size
+		}
+
+		// tail starts at offset+1 which allows to resume the download
+		offset := resume(buf, version, cur, ok)
+		cmd = fmt.Sprintf("tail -c +%d -- %s", offset+1, shellQuote(o.Path))
+		if o.Gzip {
+			cmd += " | gzip -c"
+		}
+	}
+
+	session, err := conn.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+
+	cmd, session.Stdin = o.command(cmd)
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := session.Start(cmd); err != nil {
+		return err
+	}
+
+	var r io.Reader = stdout
+	if o.Gzip {
+		gz, err := gzip.NewReader(stdout)
+		if err != nil {
+			return execError(session.Wait(), &stderr, err)
+		}
+		r = gz
+	}
+
+	_, copyErr := io.Copy(newProgressWriter(buf, total, start, progress), r)
+	return execError(session.Wait(), &stderr, copyErr)
+}
+
+// execError returns the most useful error of a command: its stderr if it
+// failed.
+func execError(waitErr error, stderr *bytes.Buffer, err error) error {
+	if waitErr != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return classify(fmt.Errorf("%s: %w", msg, waitErr), msg)
+		}
+		return waitErr
+	}
+	return err
+}
+
+// runExec runs a command and returns its output.
+func runExec(conn *ssh.Client, o SSHOptions, cmd string) ([]byte, error) {
+	session, err := conn.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	cmd, session.Stdin = o.command(cmd)
+	return session.Output(cmd)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,502 @@
+package fetch
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	statePath  = "/var/lib/xcp/state.db"
+	stateMTime = 1743494400
+)
+
+var (
+	stateDB = bytes.Repeat([]byte(`<row ref="OpaqueRef:vm" name__label="vm"/>`+"\n"), 5000)
+	dumpDB  = []byte(`<database><table name="VM"/></database>`)
+)
+
+// server is an SSH host where the SFTP subsystem is disabled: it only runs
+// the commands used by readExec.
+type server struct {
+	// rootOnly makes state.db readable only with sudo.
+	rootOnly bool
+	// sudoPassword is the password asked by sudo.
+	sudoPassword string
+	// drop is the number of bytes of state.db sent before the connection
+	// is dropped, the first time it is read.
+	drop int
+	// next is the content of state.db once the connection was dropped,
+	// it is then modified a second later.
+	next []byte
+	// password of admin, "secret" if empty.
+	password string
+	// jump forwards TCP connections like a bastion.
+	jump bool
+
+	mu       sync.Mutex
+	commands []string
+	dropped  bool
+}
+
+var tailCommand = regexp.MustCompile(`^tail -c \+(\d+) -- ('.*?')( \| gzip -c)?$`)
+
+func (s *server) start(t *testing.T) string {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			want := s.password
+			if want == "" {
+				want = "secret"
+			}
+			if c.User() != "admin" || string(password) != want {
+				return nil, errors.New("wrong password")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			netConn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(netConn, config)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func (s *server) serve(netConn net.Conn, config *ssh.ServerConfig) {
+	conn, chans, reqs, err := ssh.NewServerConn(netConn, config)
+	if err != nil {
+		netConn.Close()
+		return
+	}
+	defer conn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	for nc := range chans {
+		if nc.ChannelType() == "direct-tcpip" && s.jump {
+			go forward(nc)
+			continue
+		}
+		if nc.ChannelType() != "session" {
+			nc.Reject(ssh.UnknownChannelType, nc.ChannelType())
+			continue
+		}
+		ch, reqs, err := nc.Accept()
+		if err != nil {
+			return
+		}
+		go s.session(conn, ch, reqs)
+	}
+}
+
+func (s *server) session(conn ssh.Conn, ch ssh.Channel, reqs <-chan *ssh.Request) {
+	defer ch.Close()
+
+	for req := range reqs {
+		// The sftp subsystem, shells, ... are refused
+		if req.Type != "exec" {
+			req.Reply(false, nil)
+			continue
+		}
+
+		var exec struct{ Command string }
+		if err := ssh.Unmarshal(req.Payload, &exec); err != nil {
+			req.Reply(false, nil)
+			return
+		}
+		req.Reply(true, nil)
+
+		status, ok := s.exec(conn, ch, exec.Command)
+		if ok {
+			ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+		}
+		return
+	}
+}
+
+// file returns the content of state.db and its modification time.
+func (s *server) file() ([]byte, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dropped && s.next != nil {
+		return s.next, stateMTime + 1
+	}
+	return stateDB, stateMTime
+}
+
+// exec runs cmd and returns its exit status, ok is false if the connection
+// was dropped.
+func (s *server) exec(conn ssh.Conn, ch ssh.Channel, cmd string) (status uint32, ok bool) {
+	s.mu.Lock()
+	s.commands = append(s.commands, cmd)
+	s.mu.Unlock()
+
+	sudo := false
+	if rest, found := strings.CutPrefix(cmd, "sudo -S -p '' sh -c "); found {
+		password, _ := bufio.NewReader(ch).ReadString('\n')
+		if password != s.sudoPassword+"\n" {
+			fmt.Fprintln(ch.Stderr(), "sudo: 1 incorrect password attempt")
+			return 1, true
+		}
+		cmd, sudo = unquote(rest), true
+	} else if rest, found := strings.CutPrefix(cmd, "sudo -n sh -c "); found {
+		if s.sudoPassword != "" {
+			fmt.Fprintln(ch.Stderr(), "sudo: a password is required")
+			return 1, true
+		}
+		cmd, sudo = unquote(rest), true
+	}
+
+	if strings.HasPrefix(cmd, "f=$(mktemp) && xe pool-dump-database ") {
+		send(ch, dumpDB, strings.Contains(cmd, `gzip -c "$f"`))
+		return 0, true
+	}
+
+	var path string
+	var offset int
+	var gz, stat bool
+	if rest, found := strings.CutPrefix(cmd, "stat -c '%s %Y' -- "); found {
+		path, stat = unquote(rest), true
+	} else if m := tailCommand.FindStringSubmatch(cmd); m != nil {
+		offset, _ = strconv.Atoi(m[1])
+		path, gz = unquote(m[2]), m[3] != ""
+	} else {
+		fmt.Fprintf(ch.Stderr(), "sh: %s: not found\n", cmd)
+		return 127, true
+	}
+
+	if path != statePath {
+		fmt.Fprintf(ch.Stderr(), "%s: No such file or directory\n", path)
+		return 1, true
+	}
+	if s.rootOnly && !sudo {
+		fmt.Fprintf(ch.Stderr(), "%s: Permission denied\n", path)
+		return 1, true
+	}
+
+	data, mtime := s.file()
+	if stat {
+		fmt.Fprintln(ch, len(data), mtime)
+		return 0, true
+	}
+
+	data = data[min(offset-1, len(data)):]
+	s.mu.Lock()
+	drop := s.drop
+	s.drop = 0
+	s.dropped = s.dropped || drop > 0
+	s.mu.Unlock()
+
+	if drop > 0 && drop < len(data) {
+		ch.Write(data[:drop])
+		// The client replies once it has received the data sent before
+		conn.SendRequest("keepalive@openssh.com", true, nil)
+		conn.Close()
+		return 0, false
+	}
+
+	send(ch, data, gz)
+	return 0, true
+}
+
+// forward connects a direct-tcpip channel to its destination.
+func forward(nc ssh.NewChannel) {
+	var dest struct {
+		Addr     string
+		Port     uint32
+		OrigAddr string
+		OrigPort uint32
+	}
+	if err := ssh.Unmarshal(nc.ExtraData(), &dest); err != nil {
+		nc.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	conn, err := net.Dial("tcp", net.JoinHostPort(dest.Addr, strconv.Itoa(int(dest.Port))))
+	if err != nil {
+		nc.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	ch, reqs, err := nc.Accept()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	go func() {
+		io.Copy(conn, ch)
+		conn.Close()
+	}()
+	io.Copy(ch, conn)
+	ch.Close()
+}
+
+func send(w io.Writer, data []byte, gz bool) {
+	if !gz {
+		w.Write(data)
+		return
+	}
+	zw := gzip.NewWriter(w)
+	zw.Write(data)
+	zw.Close()
+}
+
+// unquote reverses shellQuote.
+func unquote(s string) string {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "'"), "'")
+	return strings.ReplaceAll(s, `'\''`, "'")
+}
+
+// ran returns the commands run by the clients.
+func (s *server) ran() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.commands)
+}
+
+func options(addr string) SSHOptions {
+	return SSHOptions{
+		Username:  "admin",
+		Password:  "secret",
+		Host:      addr,
+		Path:      statePath,
+		Transport: TransportAuto,
+	}
+}
+
+func TestFileSSH(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		server *server
+		modify func(o *SSHOptions)
+		want   []byte
+		cmd    string
+	}{
+		{
+			name: "fallback",
+			want: stateDB,
+			cmd:  "tail -c +1 -- '" + statePath + "'",
+		},
+		{
+			name:   "gzip",
+			modify: func(o *SSHOptions) { o.Gzip = true },
+			want:   stateDB,
+			cmd:    "tail -c +1 -- '" + statePath + "' | gzip -c",
+		},
+		{
+			name:   "sudo",
+			server: &server{rootOnly: true, sudoPassword: "secret"},
+			modify: func(o *SSHOptions) { o.Sudo = true },
+			want:   stateDB,
+			cmd:    `sudo -S -p '' sh -c 'tail -c +1 -- '\''` + statePath + `'\'''`,
+		},
+		{
+			name:   "sudo password",
+			server: &server{rootOnly: true, sudoPassword: "root"},
+			modify: func(o *SSHOptions) { o.Sudo, o.SudoPassword = true, "root" },
+			want:   stateDB,
+		},
+		{
+			name:   "dump",
+			modify: func(o *SSHOptions) { o.Dump = true },
+			want:   dumpDB,
+		},
+		{
+			name:   "dump gzip",
+			modify: func(o *SSHOptions) { o.Dump, o.Gzip = true, true },
+			want:   dumpDB,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.server == nil {
+				tt.server = &server{}
+			}
+			o := options(tt.server.start(t))
+			if tt.modify != nil {
+				tt.modify(&o)
+			}
+
+			data, err := FileSSH(context.Background(), o, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, tt.want) {
+				t.Errorf("got %d bytes, want %d", len(data), len(tt.want))
+			}
+			if ran := tt.server.ran(); tt.cmd != "" && !slices.Contains(ran, tt.cmd) {
+				t.Errorf("%q not run, commands: %q", tt.cmd, ran)
+			}
+		})
+	}
+}
+
+func TestFileSSHErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		server *server
+		modify func(o *SSHOptions)
+		want   error
+	}{
+		{
+			name:   "wrong password",
+			modify: func(o *SSHOptions) { o.Password = "wrong" },
+			want:   ErrAuthFailed,
+		},
+		{
+			name:   "root only",
+			server: &server{rootOnly: true},
+			want:   ErrPermissionDenied,
+		},
+		{
+			name:   "wrong sudo password",
+			server: &server{rootOnly: true, sudoPassword: "root"},
+			modify: func(o *SSHOptions) { o.Sudo = true },
+			want:   ErrSudoFailed,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.server == nil {
+				tt.server = &server{}
+			}
+			o := options(tt.server.start(t))
+			if tt.modify != nil {
+				tt.modify(&o)
+			}
+
+			if _, err := FileSSH(context.Background(), o, nil); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileSSHNoSFTP(t *testing.T) {
+	var s server
+	o := options(s.start(t))
+	o.Transport = TransportSFTP
+
+	if _, err := FileSSH(context.Background(), o, nil); err == nil || !strings.Contains(err.Error(), "try the exec transport") {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestFileSSHResume(t *testing.T) {
+	const drop = 100000
+	for _, tt := range []struct {
+		name string
+		next []byte
+		// resume is the offset of the second read
+		resume int
+	}{
+		{
+			name:   "unchanged",
+			resume: drop,
+		},
+		{
+			name:   "modified",
+			next:   bytes.Replace(stateDB, []byte(`name__label="vm"`), []byte(`name__label="xx"`), 1),
+			resume: 0,
+		},
+		{
+			name:   "truncated",
+			next:   stateDB[:drop/2],
+			resume: 0,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{drop: drop, next: tt.next}
+			o := options(s.start(t))
+
+			want := stateDB
+			if tt.next != nil {
+				want = tt.next
+			}
+
+			var last Progress
+			data, err := FileSSH(context.Background(), o, func(p Progress) { last = p })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, want) {
+				t.Errorf("got %d bytes, want %d", len(data), len(want))
+			}
+
+			resume := fmt.Sprintf("tail -c +%d -- '%s'", tt.resume+1, statePath)
+			if ran := s.ran(); len(ran) != 4 || ran[3] != resume {
+				t.Errorf("%q not run last, commands: %q", resume, ran)
+			}
+			if !last.Finished || last.Done != int64(len(want)) {
+				t.Errorf("last progress: %+v", last)
+			}
+		})
+	}
+}
+
+func TestFileSSHJumpHost(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		auth *JumpAuth
+		user string
+		err  error
+	}{
+		{name: "own credentials", auth: &JumpAuth{Password: "bastion"}},
+		{name: "user in address", auth: &JumpAuth{Password: "bastion"}, user: "admin@"},
+		{name: "same credentials", err: ErrAuthFailed},
+		{name: "wrong user", auth: &JumpAuth{Password: "bastion"}, user: "jump@", err: ErrAuthFailed},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			jump := &server{jump: true, password: "bastion"}
+			target := &server{}
+			o := options(target.start(t))
+			o.JumpHost = tt.user + jump.start(t)
+			o.JumpAuth = tt.auth
+
+			data, err := FileSSH(context.Background(), o, nil)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+			if err == nil && !bytes.Equal(data, stateDB) {
+				t.Errorf("got %d bytes, want %d", len(data), len(stateDB))
+			}
+			if err != nil && !strings.Contains(err.Error(), "jump host") {
+				t.Errorf("error does not name the jump host: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package fetch
+
+import (
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchLocal calls changed each time the file at path is written or
+// replaced. The directory is watched rather than the file because xapi
+// writes the database to a temporary file and renames it.
+func WatchLocal(path string, changed func()) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	path = filepath.Clean(path)
+	if err := w.Add(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case e, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			// A rename onto the file is reported as a creation
+			if filepath.Clean(e.Name) == path && e.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+				changed()
+			}
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package schema
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Schema describes the classes of the XAPI datamodel: the fields stored in
+// the database for each class (table) with their type, documentation and
+// the release that introduced them.
+//
+// A default schema is embedded, a JSON file with the same format can add or
+// replace classes, fields and enums:
+//
+//	{
+//	  "schema_major_vsn": 5,
+//	  "schema_minor_vsn": 790,
+//	  "enums": { "vm_power_state": ["Halted", "Paused", "Running", "Suspended"] },
+//	  "classes": {
+//	    "VM": {
+//	      "doc": "A virtual machine (or 'guest').",
+//	      "fields": {
+//	        "power_state": {
+//	          "type": "enum vm_power_state",
+//	          "release": "rio",
+//	          "doc": "Current power state of the machine"
+//	        },
+//	        "groups": {
+//	          "type": "set ref VM_group",
+//	          "release": "24.19.0",
+//	          "doc": "VM groups associated with the VM",
+//	          "optional": true
+//	        }
+//	      }
+//	    }
+//	  }
+//	}
+type Schema struct {
+	Major int `json:"schema_major_vsn"`
+	Minor int `json:"schema_minor_vsn"`
+	// Values of the enums, an enum without values is not checked
+	Enums   map[string][]string `json:"enums"`
+	Classes map[string]*Class   `json:"classes"`
+}
+
+type Class struct {
+	Name   string            `json:"-"`
+	Doc    string            `json:"doc"`
+	Fields map[string]*Field `json:"fields"`
+}
+
+// Field of a class, the name is the one of the database where fields of
+// records are joined by "__" (name__label, memory__total).
+type Field struct {
+	Name    string `json:"-"`
+	Type    Type   `json:"type"`
+	Release string `json:"release"`
+	Doc     string `json:"doc"`
+	// Optional fields were added after the class and may be missing in
+	// databases written by older releases.
+	Optional bool `json:"optional,omitempty"`
+}
+
+//go:embed xapi.json
+var embedded []byte
+
+// Current is the schema used by the views, see Set.
+var Current = Default()
+
+func Set(s *Schema) {
+	Current = s
+}
+
+// Default returns the embedded schema.
+func Default() *Schema {
+	s, err := Parse(embedded)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded schema: %s", err))
+	}
+	return s
+}
+
+func Parse(data []byte) (*Schema, error) {
+	s := &Schema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	s.init()
+	return s, nil
+}
+
+// Load returns the embedded schema updated with the JSON file at path.
+func Load(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	override, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	s := Default()
+	s.Merge(override)
+	return s, nil
+}
+
+// Merge adds the classes, fields and enums of other to s, replacing the
+// existing ones.
+func (s *Schema) Merge(other *Schema) {
+	if other.Major != 0 || other.Minor != 0 {
+		s.Major, s.Minor = other.Major, other.Minor
+	}
+	for name, values := range other.Enums {
+		s.Enums[name] = values
+	}
+	for name, c := range other.Classes {
+		existing := s.Class(name)
+		if existing == nil {
+			s.Classes[name] = c
+			continue
+		}
+		if c.Doc != "" {
+			existing.Doc = c.Doc
+		}
+		for fname, f := range c.Fields {
+			existing.Fields[fname] = f
+		}
+	}
+	s.init()
+}
+
+// init fills the names, the maps missing in the JSON and the values of the
+// enums.
+func (s *Schema) init() {
+	if s.Enums == nil {
+		s.Enums = map[string][]string{}
+	}
+	if s.Classes == nil {
+		s.Classes = map[string]*Class{}
+	}
+	for name, c := range s.Classes {
+		c.Name = name
+		if c.Fields == nil {
+			c.Fields = map[string]*Field{}
+		}
+		for fname, f := range c.Fields {
+			f.Name = fname
+			f.Type.resolve(s.Enums)
+		}
+	}
+}
+
+// Class returns the class with the given name, names are compared without
+// case like table names (vm and VM). It returns nil for unknown classes.
+func (s *Schema) Class(name string) *Class {
+	if c, ok := s.Classes[name]; ok {
+		return c
+	}
+	for n, c := range s.Classes {
+		if strings.EqualFold(n, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Field returns the field of the class. The fields added to all rows by the
+// database (ref, __ctime, __mtime) are known for all classes.
+func (s *Schema) Field(class, field string) (*Field, bool) {
+	c := s.Class(class)
+	if c == nil {
+		return nil, false
+	}
+
+	switch field {
+	case "ref", "_ref":
+		return &Field{Name: field, Type: Type{Kind: KindRef, Name: c.Name}, Doc: "Reference of the object"}, true
+	case "__ctime":
+		return &Field{Name: field, Type: Type{Kind: KindInt}, Doc: "Generation count at which the object was created"}, true
+	case "__mtime":
+		return &Field{Name: field, Type: Type{Kind: KindInt}, Doc: "Generation count at which the object was last modified"}, true
+	}
+
+	f, ok := c.Fields[field]
+	return f, ok
+}
@@ -0,0 +1,170 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindFloat
+	KindBool
+	KindDateTime
+	KindEnum
+	KindRef
+	KindSet
+	KindMap
+)
+
+// Type of a field. Types are written in the JSON schema as:
+//
+//	string, int, float, bool, datetime
+//	enum <name>         (values are in the enums of the schema)
+//	ref <class>
+//	set <type>          (set ref VBD)
+//	map <type> <type>   (map string string, map ref VIF string)
+type Type struct {
+	Kind Kind
+	// Name is the class of a Ref or the name of an Enum
+	Name string
+	// Elem is the type of the elements of a Set or of the values of a Map
+	Elem *Type
+	// Key is the type of the keys of a Map
+	Key *Type
+	// Values of an Enum, filled from the enums of the schema
+	Values []string
+}
+
+func ParseType(s string) (Type, error) {
+	words := strings.Fields(s)
+	t, rest, err := parseType(words)
+	if err != nil {
+		return Type{}, fmt.Errorf("invalid type %q: %w", s, err)
+	}
+	if len(rest) > 0 {
+		return Type{}, fmt.Errorf("invalid type %q: unexpected %s", s, strings.Join(rest, " "))
+	}
+	return t, nil
+}
+
+// parseType parses the type at the start of words and returns the words
+// left.
+func parseType(words []string) (Type, []string, error) {
+	if len(words) == 0 {
+		return Type{}, nil, fmt.Errorf("missing type")
+	}
+
+	switch words[0] {
+	case "string":
+		return Type{Kind: KindString}, words[1:], nil
+	case "int":
+		return Type{Kind: KindInt}, words[1:], nil
+	case "float":
+		return Type{Kind: KindFloat}, words[1:], nil
+	case "bool":
+		return Type{Kind: KindBool}, words[1:], nil
+	case "datetime":
+		return Type{Kind: KindDateTime}, words[1:], nil
+
+	case "enum", "ref":
+		if len(words) < 2 {
+			return Type{}, nil, fmt.Errorf("missing name after %s", words[0])
+		}
+		kind := KindEnum
+		if words[0] == "ref" {
+			kind = KindRef
+		}
+		return Type{Kind: kind, Name: words[1]}, words[2:], nil
+
+	case "set":
+		elem, rest, err := parseType(words[1:])
+		if err != nil {
+			return Type{}, nil, err
+		}
+		return Type{Kind: KindSet, Elem: &elem}, rest, nil
+
+	case "map":
+		key, rest, err := parseType(words[1:])
+		if err != nil {
+			return Type{}, nil, err
+		}
+		elem, rest, err := parseType(rest)
+		if err != nil {
+			return Type{}, nil, err
+		}
+		return Type{Kind: KindMap, Key: &key, Elem: &elem}, rest, nil
+	}
+
+	return Type{}, nil, fmt.Errorf("unknown type %s", words[0])
+}
+
+func (t Type) String() string {
+	switch t.Kind {
+	case KindString:
+		return "string"
+	case KindInt:
+		return "int"
+	case KindFloat:
+		return "float"
+	case KindBool:
+		return "bool"
+	case KindDateTime:
+		return "datetime"
+	case KindEnum:
+		return "enum " + t.Name
+	case KindRef:
+		return "ref " + t.Name
+	case KindSet:
+		return "set " + t.Elem.String()
+	case KindMap:
+		return "map " + t.Key.String() + " " + t.Elem.String()
+	}
+	return "unknown"
+}
+
+// HasRefs returns true if values of the type contain references.
+func (t Type) HasRefs() bool {
+	switch t.Kind {
+	case KindRef:
+		return true
+	case KindSet:
+		return t.Elem.HasRefs()
+	case KindMap:
+		return t.Key.HasRefs() || t.Elem.HasRefs()
+	}
+	return false
+}
+
+// resolve sets the values of the enums of t.
+func (t *Type) resolve(enums map[string][]string) {
+	switch t.Kind {
+	case KindEnum:
+		t.Values = enums[t.Name]
+	case KindSet:
+		t.Elem.resolve(enums)
+	case KindMap:
+		t.Key.resolve(enums)
+		t.Elem.resolve(enums)
+	}
+}
+
+func (t *Type) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseType(s)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+func (t Type) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
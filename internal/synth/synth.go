@@ -0,0 +1,590 @@
+// Package synth generates synthetic XAPI databases: a pool with hosts,
+// storage, networks and VMs with their disks, interfaces and snapshots,
+// messages and tasks. The objects reference each other consistently (a VBD
+// in VM.VBDs has the VM in VBD.VM), every field of the schema is filled so
+// the database validates, and the same options and seed always give the
+// same database.
+//
+// Defects found in real databases can be injected to test the features that
+// detect them, each one is reported with Generate.
+package synth
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/readxapidb/internal/schema"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+type Options struct {
+	Seed uint64
+
+	Hosts int
+	// SRs are shared by all the hosts, each host also has a local SR
+	SRs int
+	// Networks have a PIF on each host
+	Networks   int
+	VMs        int
+	DisksPerVM int
+	VIFsPerVM  int
+	// SnapshotChains VMs have a chain of SnapshotDepth snapshots, each one
+	// with copies of the disks of the VM
+	SnapshotChains int
+	SnapshotDepth  int
+	Messages       int
+	Tasks          int
+
+	Defects Defects
+}
+
+// Defects are the number of defects of each kind to inject, there may be
+// less if there are not enough objects.
+type Defects struct {
+	// References to objects that don't exist (VBD.VDI, VIF.network, ...)
+	DanglingRefs int
+	// Links set on one side only (VBD.VM set but the VBD is missing from
+	// VM.VBDs)
+	AsymmetricLinks int
+	// current_operations of VMs with a task that doesn't exist
+	StuckOperations int
+}
+
+// DefaultOptions returns the options of a small pool.
+func DefaultOptions() Options {
+	return Options{
+		Seed:           1,
+		Hosts:          3,
+		SRs:            2,
+		Networks:       2,
+		VMs:            20,
+		DisksPerVM:     2,
+		VIFsPerVM:      1,
+		SnapshotChains: 4,
+		SnapshotDepth:  2,
+		Messages:       100,
+		Tasks:          50,
+	}
+}
+
+type DefectKind int
+
+const (
+	DanglingRef DefectKind = iota
+	AsymmetricLink
+	StuckOperation
+)
+
+func (k DefectKind) String() string {
+	switch k {
+	case DanglingRef:
+		return "dangling ref"
+	case AsymmetricLink:
+		return "asymmetric link"
+	case StuckOperation:
+		return "stuck operation"
+	}
+	return "unknown"
+}
+
+// Defect is an injected defect: the field of the row that has it and the
+// value involved (the dangling reference, the reference missing from the
+// set or the task of the stuck operation).
+type Defect struct {
+	Kind  DefectKind
+	Table string
+	Ref   string
+	Field string
+	Value string
+}
+
+func (d Defect) String() string {
+	return fmt.Sprintf("%s: %s %s %s: %s", d.Kind, d.Table, d.Ref, d.Field, d.Value)
+}
+
+// start is the time of the first object, the others are a few seconds
+// apart.
+var start = time.Date(2025, 3, 31, 8, 0, 0, 0, time.UTC)
+
+const null = "OpaqueRef:NULL"
+
+type generator struct {
+	rng    *rand.Rand
+	schema *schema.Schema
+	db     *xapidb.DB
+	// generation is the __ctime of the next object
+	generation int64
+	// sets are the references to add to the set fields of the rows (ref,
+	// field), written once everything is created
+	sets map[string]map[string][]string
+	// links are the pairs of fields referencing each other, to inject
+	// asymmetric links
+	links []link
+}
+
+// link is an object referenced by a set field of another one, and
+// referencing it back with its field back.
+type link struct {
+	table, ref, field string
+	toTable, to, back string
+}
+
+// Generate returns a database generated with the options and the defects
+// injected in it. The rows have all the fields of the classes of
+// schema.Current.
+func Generate(o Options) (*xapidb.DB, []Defect) {
+	g := &generator{
+		rng:    rand.New(rand.NewPCG(o.Seed, o.Seed^0x9e3779b97f4a7c15)),
+		schema: schema.Current,
+		sets:   map[string]map[string][]string{},
+	}
+	g.init()
+
+	pool := g.row("pool", map[string]string{"name__label": "pool0"})
+
+	var hosts, localSRs []string
+	for i := range o.Hosts {
+		host := g.row("host", map[string]string{
+			"name__label":        fmt.Sprintf("host%d", i),
+			"hostname":           fmt.Sprintf("host%d", i),
+			"address":            fmt.Sprintf("10.0.%d.%d", i/250, i%250+1),
+			"enabled":            "true",
+			"memory__overhead":   "1073741824",
+			"API_version__major": "2",
+			"API_version__minor": "21",
+		})
+		hosts = append(hosts, host)
+
+		dom0 := g.vm(fmt.Sprintf("Control domain on host: host%d", i), host, "Running")
+		g.set(dom0, map[string]string{"is_control_domain": "true"})
+		g.set(host, map[string]string{"control_domain": dom0})
+
+		sr := g.row("SR", map[string]string{
+			"name__label":   fmt.Sprintf("Local storage on host%d", i),
+			"type":          "lvm",
+			"content_type":  "user",
+			"shared":        "false",
+			"physical_size": "1000203091968",
+		})
+		localSRs = append(localSRs, sr)
+		g.pbd(sr, host)
+	}
+	if len(hosts) > 0 {
+		g.set(pool, map[string]string{"master": hosts[0]})
+	}
+
+	var sharedSRs []string
+	for i := range o.SRs {
+		sr := g.row("SR", map[string]string{
+			"name__label":   fmt.Sprintf("NFS storage %d", i),
+			"type":          "nfs",
+			"content_type":  "user",
+			"shared":        "true",
+			"physical_size": "10995116277760",
+		})
+		sharedSRs = append(sharedSRs, sr)
+		for _, host := range hosts {
+			g.pbd(sr, host)
+		}
+	}
+	if len(sharedSRs) > 0 {
+		g.set(pool, map[string]string{"default_SR": sharedSRs[0]})
+	}
+
+	var networks []string
+	for i := range o.Networks {
+		network := g.row("network", map[string]string{
+			"name__label": fmt.Sprintf("Pool-wide network associated with eth%d", i),
+			"bridge":      fmt.Sprintf("xenbr%d", i),
+			"MTU":         "1500",
+		})
+		networks = append(networks, network)
+		for _, host := range hosts {
+			pif := g.row("PIF", map[string]string{
+				"device":                fmt.Sprintf("eth%d", i),
+				"MAC":                   g.mac(),
+				"MTU":                   "1500",
+				"VLAN":                  "-1",
+				"physical":              "true",
+				"currently_attached":    "true",
+				"management":            strconv.FormatBool(i == 0),
+				"ip_configuration_mode": "DHCP",
+			})
+			g.link("network", network, "PIFs", "PIF", pif, "network")
+			g.link("host", host, "PIFs", "PIF", pif, "host")
+		}
+	}
+
+	var vms []string
+	for i := range o.VMs {
+		// One VM out of 5 is halted, the others run on the hosts in turn
+		host, power := null, "Halted"
+		if len(hosts) > 0 && i%5 != 4 {
+			host, power = hosts[i%len(hosts)], "Running"
+		}
+		vm := g.vm(fmt.Sprintf("vm%d", i), host, power)
+		vms = append(vms, vm)
+
+		srs := sharedSRs
+		if len(srs) == 0 && host != null {
+			srs = []string{localSRs[slices.Index(hosts, host)]}
+		} else if len(srs) == 0 && len(localSRs) > 0 {
+			srs = localSRs[:1]
+		}
+		for d := range o.DisksPerVM {
+			sr := null
+			if len(srs) > 0 {
+				sr = srs[(i+d)%len(srs)]
+			}
+			vdi := g.vdi(fmt.Sprintf("vm%d disk %d", i, d), sr)
+			g.vbd(vm, vdi, d, power == "Running")
+		}
+
+		for v := range o.VIFsPerVM {
+			vif := g.row("VIF", map[string]string{
+				"device":             strconv.Itoa(v),
+				"MAC":                g.mac(),
+				"MTU":                "1500",
+				"currently_attached": strconv.FormatBool(power == "Running"),
+				"locking_mode":       "network_default",
+			})
+			g.link("VM", vm, "VIFs", "VIF", vif, "VM")
+			if len(networks) > 0 {
+				g.link("network", networks[(i+v)%len(networks)], "VIFs", "VIF", vif, "network")
+			}
+		}
+	}
+
+	for i := range min(o.SnapshotChains, len(vms)) {
+		g.snapshots(vms[i], o.SnapshotDepth)
+	}
+
+	messages := []string{"VM_STARTED", "VM_SHUTDOWN", "VM_REBOOTED", "VM_SNAPSHOTTED"}
+	for i := range o.Messages {
+		if len(vms) == 0 {
+			break
+		}
+		vm := g.db.RefIndex[vms[i%len(vms)]].Attr
+		name := messages[i%len(messages)]
+		g.row("message", map[string]string{
+			"name":      name,
+			"priority":  "5",
+			"cls":       "VM",
+			"obj_uuid":  vm["uuid"],
+			"timestamp": g.time(0),
+			"body":      xapidb.EscapeValue(fmt.Sprintf("VM '%s' %s", xapidb.UnescapeValue(vm["name__label"]), strings.ToLower(name[3:]))),
+		})
+	}
+
+	for range o.Tasks {
+		host := null
+		if len(hosts) > 0 {
+			host = g.pick(hosts)
+		}
+		g.row("task", map[string]string{
+			"name__label": "Async.VM.start",
+			"status":      "success",
+			"progress":    "1.",
+			"created":     g.time(0),
+			"finished":    g.time(2 * time.Second),
+			"resident_on": host,
+		})
+	}
+
+	defects := g.inject(o.Defects, vms)
+	g.finish()
+	return g.db, defects
+}
+
+// init creates the database with the manifest and a table per class of the
+// schema, like xapi writes all the tables even empty.
+func (g *generator) init() {
+	root := &xapidb.Node{Name: "database", Attr: map[string]string{}, Children: []*xapidb.Node{}}
+	g.db = &xapidb.DB{Root: root, RefIndex: map[string]*xapidb.Node{}}
+
+	manifest := &xapidb.Node{Name: "manifest", Attr: map[string]string{}, Children: []*xapidb.Node{}, Parent: root}
+	root.Children = append(root.Children, manifest)
+
+	classes := make([]string, 0, len(g.schema.Classes))
+	for name := range g.schema.Classes {
+		classes = append(classes, name)
+	}
+	sort.Strings(classes)
+	for _, name := range classes {
+		root.Children = append(root.Children, &xapidb.Node{
+			Name:     "table",
+			Attr:     map[string]string{"name": name},
+			Children: []*xapidb.Node{},
+			Parent:   root,
+		})
+	}
+}
+
+// row adds a row to the table with the default value of all the fields of
+// the class, replaced by fields. It returns its reference.
+func (g *generator) row(table string, fields map[string]string) string {
+	ref := "OpaqueRef:" + g.uuid()
+	g.generation++
+	generation := strconv.FormatInt(g.generation, 10)
+
+	attrs := map[string]string{"ref": ref, "_ref": ref, "__ctime": generation, "__mtime": generation}
+	if class := g.schema.Class(table); class != nil {
+		for name, f := range class.Fields {
+			attrs[name] = zero(f.Type)
+		}
+	}
+	if _, ok := attrs["uuid"]; ok {
+		attrs["uuid"] = g.uuid()
+	}
+	for name, value := range fields {
+		attrs[name] = value
+	}
+
+	g.db.SetRow(table, ref, attrs)
+	return ref
+}
+
+// set replaces fields of a row.
+func (g *generator) set(ref string, fields map[string]string) {
+	row := g.db.RefIndex[ref]
+	for name, value := range fields {
+		row.Attr[name] = value
+	}
+}
+
+// link makes the object to reference the other one with field and adds
+// it to the set field of the other one.
+func (g *generator) link(table, ref, setField, toTable, to, field string) {
+	g.set(to, map[string]string{field: ref})
+	g.add(ref, setField, to)
+	g.links = append(g.links, link{table: table, ref: ref, field: setField, toTable: toTable, to: to, back: field})
+}
+
+// add adds a reference to a set field, see finish.
+func (g *generator) add(ref, field, value string) {
+	if g.sets[ref] == nil {
+		g.sets[ref] = map[string][]string{}
+	}
+	g.sets[ref][field] = append(g.sets[ref][field], value)
+}
+
+// finish writes the set fields and the manifest.
+func (g *generator) finish() {
+	for ref, fields := range g.sets {
+		row, ok := g.db.RefIndex[ref]
+		if !ok {
+			continue
+		}
+		for field, values := range fields {
+			row.Attr[field] = set(values)
+		}
+	}
+
+	manifest := g.db.Root.Children[0]
+	for _, pair := range [][2]string{
+		{"schema_major_vsn", strconv.Itoa(g.schema.Major)},
+		{"schema_minor_vsn", strconv.Itoa(g.schema.Minor)},
+		{"generation_count", strconv.FormatInt(g.generation, 10)},
+	} {
+		manifest.Children = append(manifest.Children, &xapidb.Node{
+			Name:     "pair",
+			Attr:     map[string]string{"key": pair[0], "value": pair[1]},
+			Children: []*xapidb.Node{},
+			Parent:   manifest,
+		})
+	}
+}
+
+func (g *generator) vm(label, host, power string) string {
+	vm := g.row("VM", map[string]string{
+		"name__label":         xapidb.EscapeValue(label),
+		"power_state":         power,
+		"is_a_template":       "false",
+		"memory__static_max":  "4294967296",
+		"memory__dynamic_max": "4294967296",
+		"memory__dynamic_min": "2147483648",
+		"memory__static_min":  "1073741824",
+		"VCPUs__max":          "2",
+		"VCPUs__at_startup":   "2",
+		"domid":               "-1",
+		"allowed_operations":  set(vmOperations(power)),
+	})
+	if host != null {
+		g.link("host", host, "resident_VMs", "VM", vm, "resident_on")
+		g.set(vm, map[string]string{"domid": strconv.Itoa(g.rng.IntN(1000) + 1)})
+	}
+	return vm
+}
+
+func vmOperations(power string) []string {
+	if power == "Running" {
+		return []string{"pause", "clean_shutdown", "clean_reboot", "hard_shutdown", "snapshot"}
+	}
+	return []string{"start", "clone", "copy", "export", "snapshot", "destroy"}
+}
+
+func (g *generator) pbd(sr, host string) {
+	pbd := g.row("PBD", map[string]string{"currently_attached": "true"})
+	g.link("SR", sr, "PBDs", "PBD", pbd, "SR")
+	g.link("host", host, "PBDs", "PBD", pbd, "host")
+}
+
+func (g *generator) vdi(label, sr string) string {
+	vdi := g.row("VDI", map[string]string{
+		"name__label":  xapidb.EscapeValue(label),
+		"virtual_size": "21474836480",
+		"type":         "user",
+		"managed":      "true",
+		"location":     g.uuid(),
+	})
+	if sr != null {
+		g.link("SR", sr, "VDIs", "VDI", vdi, "SR")
+	}
+	return vdi
+}
+
+func (g *generator) vbd(vm, vdi string, device int, attached bool) string {
+	vbd := g.row("VBD", map[string]string{
+		"device":             "xvd" + string(rune('a'+device%26)),
+		"userdevice":         strconv.Itoa(device),
+		"bootable":           strconv.FormatBool(device == 0),
+		"mode":               "RW",
+		"type":               "Disk",
+		"currently_attached": strconv.FormatBool(attached),
+	})
+	g.link("VM", vm, "VBDs", "VBD", vbd, "VM")
+	g.link("VDI", vdi, "VBDs", "VBD", vbd, "VDI")
+	return vbd
+}
+
+// snapshots adds a chain of snapshots to the VM: each snapshot is the parent
+// of the next one and the last one is the parent of the VM. The disks of
+// the VM are copied in each snapshot.
+func (g *generator) snapshots(vm string, depth int) {
+	attrs := g.db.RefIndex[vm].Attr
+	label := xapidb.UnescapeValue(attrs["name__label"])
+
+	parent := null
+	for i := range depth {
+		snapshot := g.vm(fmt.Sprintf("%s snapshot %d", label, i), null, "Halted")
+		g.set(snapshot, map[string]string{
+			"is_a_snapshot": "true",
+			"snapshot_time": g.time(0),
+			"parent":        parent,
+		})
+		g.link("VM", vm, "snapshots", "VM", snapshot, "snapshot_of")
+		if parent != null {
+			g.add(parent, "children", snapshot)
+		}
+
+		for d, vbd := range g.sets[vm]["VBDs"] {
+			disk := g.db.RefIndex[g.db.RefIndex[vbd].Attr["VDI"]].Attr
+			vdi := g.vdi(fmt.Sprintf("%s snapshot %d", xapidb.UnescapeValue(disk["name__label"]), i), disk["SR"])
+			g.set(vdi, map[string]string{"is_a_snapshot": "true", "snapshot_time": g.time(0)})
+			g.link("VDI", disk["ref"], "snapshots", "VDI", vdi, "snapshot_of")
+			g.vbd(snapshot, vdi, d, false)
+		}
+		parent = snapshot
+	}
+
+	if parent != null {
+		g.set(vm, map[string]string{"parent": parent})
+		g.add(parent, "children", vm)
+	}
+}
+
+// inject adds the defects to the database.
+func (g *generator) inject(d Defects, vms []string) []Defect {
+	var defects []Defect
+
+	// The objects of a link reference an object that doesn't exist
+	// instead, the set still has them.
+	for _, i := range g.perm(len(g.links), d.DanglingRefs) {
+		l := g.links[i]
+		dangling := "OpaqueRef:" + g.uuid()
+		g.set(l.to, map[string]string{l.back: dangling})
+		defects = append(defects, Defect{Kind: DanglingRef, Table: l.toTable, Ref: l.to, Field: l.back, Value: dangling})
+	}
+
+	for _, i := range g.perm(len(g.links), d.AsymmetricLinks) {
+		l := g.links[i]
+		refs := g.sets[l.ref][l.field]
+		g.sets[l.ref][l.field] = slices.DeleteFunc(slices.Clone(refs), func(r string) bool { return r == l.to })
+		defects = append(defects, Defect{Kind: AsymmetricLink, Table: l.table, Ref: l.ref, Field: l.field, Value: l.to})
+	}
+
+	for _, i := range g.perm(len(vms), d.StuckOperations) {
+		vm := vms[i]
+		task := "OpaqueRef:" + g.uuid()
+		g.set(vm, map[string]string{"current_operations": xapidb.EscapeValue(
+			"((" + xapidb.QuoteSExpr(task) + " " + xapidb.QuoteSExpr("clean_shutdown") + "))")})
+		defects = append(defects, Defect{Kind: StuckOperation, Table: "VM", Ref: vm, Field: "current_operations", Value: task})
+	}
+
+	return defects
+}
+
+// perm returns n distinct indexes lower than count.
+func (g *generator) perm(count, n int) []int {
+	return g.rng.Perm(count)[:min(n, count)]
+}
+
+func (g *generator) pick(refs []string) string {
+	return refs[g.rng.IntN(len(refs))]
+}
+
+func (g *generator) uuid() string {
+	var b [16]byte
+	for i := range b {
+		b[i] = byte(g.rng.Uint32())
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func (g *generator) mac() string {
+	return fmt.Sprintf("00:16:3e:%02x:%02x:%02x", g.rng.IntN(256), g.rng.IntN(256), g.rng.IntN(256))
+}
+
+// time returns the time of the current generation plus d.
+func (g *generator) time(d time.Duration) string {
+	return start.Add(time.Duration(g.generation)*3*time.Second + d).Format("20060102T15:04:05Z")
+}
+
+// zero returns the default value of a field of the type.
+func zero(t schema.Type) string {
+	switch t.Kind {
+	case schema.KindInt:
+		return "0"
+	case schema.KindFloat:
+		return "0."
+	case schema.KindBool:
+		return "false"
+	case schema.KindDateTime:
+		return "19700101T00:00:00Z"
+	case schema.KindEnum:
+		if len(t.Values) > 0 {
+			return t.Values[0]
+		}
+	case schema.KindRef:
+		return null
+	case schema.KindSet, schema.KindMap:
+		return "()"
+	}
+	return ""
+}
+
+// set returns the escaped value of a set of strings.
+func set(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = xapidb.QuoteSExpr(v)
+	}
+	return xapidb.EscapeValue("(" + strings.Join(quoted, " ") + ")")
+}
@@ -0,0 +1,105 @@
+package synth
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"example.com/readxapidb/internal/schema"
+	"example.com/readxapidb/internal/validate"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// roundTrip writes the database and reads it back.
+func roundTrip(t *testing.T, db *xapidb.DB) (*xapidb.DB, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := xapidb.WriteXapiDB(&buf, db); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	read, err := xapidb.ReadXapiDB(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return read, data
+}
+
+func TestGenerateValid(t *testing.T) {
+	db, defects := Generate(DefaultOptions())
+	if len(defects) != 0 {
+		t.Errorf("got %d defects without asking for them", len(defects))
+	}
+
+	read, data := roundTrip(t, db)
+
+	// Namespaced fields must have the names of the database
+	if !strings.Contains(string(data), ` memory__static_max="`) {
+		t.Error("no memory__static_max in the VMs")
+	}
+
+	r := validate.Validate(read, schema.Default())
+	for _, f := range r.Findings {
+		t.Errorf("%s %s %s %s: %s", f.Severity, f.Kind, f.Table, f.Field, f.Message)
+	}
+
+	// Writing the database read back gives the same file
+	if _, again := roundTrip(t, read); !bytes.Equal(data, again) {
+		t.Error("the database written after reading it differs")
+	}
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	_, a := roundTrip(t, func() *xapidb.DB { db, _ := Generate(DefaultOptions()); return db }())
+	_, b := roundTrip(t, func() *xapidb.DB { db, _ := Generate(DefaultOptions()); return db }())
+	if !bytes.Equal(a, b) {
+		t.Error("two databases generated with the same seed differ")
+	}
+}
+
+func TestGenerateDefects(t *testing.T) {
+	o := DefaultOptions()
+	o.Defects = Defects{DanglingRefs: 2, AsymmetricLinks: 2, StuckOperations: 2}
+	db, defects := Generate(o)
+
+	read, _ := roundTrip(t, db)
+
+	count := map[DefectKind]int{}
+	for _, d := range defects {
+		count[d.Kind]++
+
+		row, ok := read.RefIndex[d.Ref]
+		if !ok {
+			t.Errorf("%s: no row", d)
+			continue
+		}
+		value := xapidb.UnescapeValue(row.Attr[d.Field])
+		switch d.Kind {
+		case DanglingRef:
+			if value != d.Value {
+				t.Errorf("%s: the field is %s", d, value)
+			}
+			if _, ok := read.RefIndex[d.Value]; ok {
+				t.Errorf("%s: the reference exists", d)
+			}
+		case AsymmetricLink:
+			if strings.Contains(value, d.Value) {
+				t.Errorf("%s: the set still has the reference", d)
+			}
+		case StuckOperation:
+			if !strings.Contains(value, d.Value) {
+				t.Errorf("%s: the field is %s", d, value)
+			}
+		}
+	}
+	for _, kind := range []DefectKind{DanglingRef, AsymmetricLink, StuckOperation} {
+		if count[kind] != 2 {
+			t.Errorf("%s: got %d defects, want 2", kind, count[kind])
+		}
+	}
+
+	// The defects are in the references, the values are still valid
+	if r := validate.Validate(read, schema.Default()); r.Errors() > 0 {
+		t.Errorf("got %d validation errors", r.Errors())
+	}
+}
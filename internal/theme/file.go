@@ -0,0 +1,133 @@
+package theme
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Example of theme file, colors are names ("red", "default") or "#rrggbb":
+//
+//	name = "solarized"
+//	base = "gruvbox"    # colors not given are taken from this theme
+//
+//	[ui]                # tview.Theme
+//	primitive_background = "#002b36"
+//	primary_text = "#839496"
+//
+//	[colors]            # meaning of the colors in the views
+//	row = "#268bd2"
+//	selected_background = "#073642"
+
+type themeFile struct {
+	Name   string            `toml:"name"`
+	Base   string            `toml:"base"`
+	UI     map[string]string `toml:"ui"`
+	Colors map[string]string `toml:"colors"`
+}
+
+// Load reads a theme file. The name of the theme defaults to the name of
+// the file without extension.
+func Load(path string) (*Theme, error) {
+	var f themeFile
+	if _, err := toml.DecodeFile(path, &f); err != nil {
+		return nil, err
+	}
+
+	base := Gruvbox
+	if f.Base != "" {
+		var ok bool
+		if base, ok = themes[f.Base]; !ok {
+			return nil, fmt.Errorf("%s: unknown base theme %s", path, f.Base)
+		}
+	}
+
+	t := *base
+	t.Name = f.Name
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	ui := map[string]*tcell.Color{
+		"primitive_background":     &t.UI.PrimitiveBackgroundColor,
+		"contrast_background":      &t.UI.ContrastBackgroundColor,
+		"more_contrast_background": &t.UI.MoreContrastBackgroundColor,
+		"border":                   &t.UI.BorderColor,
+		"title":                    &t.UI.TitleColor,
+		"graphics":                 &t.UI.GraphicsColor,
+		"primary_text":             &t.UI.PrimaryTextColor,
+		"secondary_text":           &t.UI.SecondaryTextColor,
+		"tertiary_text":            &t.UI.TertiaryTextColor,
+		"inverse_text":             &t.UI.InverseTextColor,
+		"contrast_secondary_text":  &t.UI.ContrastSecondaryTextColor,
+	}
+	if err := setColors(ui, f.UI); err != nil {
+		return nil, fmt.Errorf("%s: [ui]: %w", path, err)
+	}
+
+	colors := map[string]*tcell.Color{
+		"database":            &t.Database,
+		"table":               &t.Table,
+		"row":                 &t.Row,
+		"node":                &t.Node,
+		"highlight":           &t.Highlight,
+		"heading":             &t.Heading,
+		"key":                 &t.Key,
+		"value":               &t.Value,
+		"ref":                 &t.Ref,
+		"border":              &t.Border,
+		"focus_border":        &t.FocusBorder,
+		"selected_background": &t.SelectedBackground,
+		"selected_foreground": &t.SelectedForeground,
+		"error":               &t.Error,
+		"warning":             &t.Warning,
+		"success":             &t.Success,
+		"info":                &t.Info,
+	}
+	if err := setColors(colors, f.Colors); err != nil {
+		return nil, fmt.Errorf("%s: [colors]: %w", path, err)
+	}
+
+	return &t, nil
+}
+
+func setColors(fields map[string]*tcell.Color, values map[string]string) error {
+	for name, value := range values {
+		field, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("unknown color %s", name)
+		}
+		if value == "default" {
+			*field = tcell.ColorDefault
+			continue
+		}
+		c := tcell.GetColor(value)
+		if c == tcell.ColorDefault {
+			return fmt.Errorf("invalid color %q for %s", value, name)
+		}
+		*field = c
+	}
+	return nil
+}
+
+// LoadDir registers the themes of all the .toml files of dir. A missing
+// directory is not an error.
+func LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.toml"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range files {
+		t, err := Load(path)
+		if err != nil {
+			return err
+		}
+		Register(t)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func writeTheme(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeTheme(t, "solarized.toml", `
+base = "light"
+
+[ui]
+primitive_background = "#002b36"
+
+[colors]
+row = "blue"
+selected_background = "default"
+`)
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := *Light
+	want.Name = "solarized"
+	want.UI.PrimitiveBackgroundColor = tcell.NewHexColor(0x002b36)
+	want.Row = tcell.ColorBlue
+	want.SelectedBackground = tcell.ColorDefault
+	if *got != want {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+	if Light.Row == tcell.ColorBlue {
+		t.Error("base theme modified")
+	}
+}
+
+func TestLoadName(t *testing.T) {
+	got, err := Load(writeTheme(t, "dark.toml", `name = "night"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := *Gruvbox
+	want.Name = "night"
+	if *got != want {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	for _, tt := range []struct {
+		content string
+		want    string
+	}{
+		{`base = "solarized"`, "unknown base theme solarized"},
+		{"[ui]\nborder_color = \"red\"", "[ui]: unknown color border_color"},
+		{"[colors]\nrow = \"reddish\"", `[colors]: invalid color "reddish" for row`},
+		{`name = `, "expected value"},
+	} {
+		_, err := Load(writeTheme(t, "broken.toml", tt.content))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: got %v, want %s", tt.content, err, tt.want)
+		}
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.toml")); !os.IsNotExist(err) {
+		t.Errorf("missing file: got %v", err)
+	}
+}
@@ -21,3 +21,29 @@ var GruvboxDark = tview.Theme{
 	InverseTextColor:           tcell.NewHexColor(0x282828), // bg
 	ContrastSecondaryTextColor: tcell.NewHexColor(0xfe8019), // orange
 }
+
+var Gruvbox = &Theme{
+	Name: "gruvbox",
+	UI:   GruvboxDark,
+
+	Database:  tcell.NewHexColor(0xfb4934), // red
+	Table:     tcell.NewHexColor(0xb8bb26), // green
+	Row:       tcell.NewHexColor(0x83a598), // blue
+	Node:      tcell.NewHexColor(0xebdbb2), // fg1
+	Highlight: tcell.NewHexColor(0xfabd2f), // yellow
+
+	Heading: tcell.NewHexColor(0xfabd2f), // yellow
+	Key:     tcell.NewHexColor(0xfe8019), // orange
+	Value:   tcell.NewHexColor(0xebdbb2), // fg1
+	Ref:     tcell.NewHexColor(0x83a598), // blue
+
+	Border:             tcell.NewHexColor(0xebdbb2), // fg1
+	FocusBorder:        tcell.NewHexColor(0xb8bb26), // green
+	SelectedBackground: tcell.NewHexColor(0x504945), // bg2
+	SelectedForeground: tcell.NewHexColor(0xfabd2f), // yellow
+
+	Error:   tcell.NewHexColor(0xfb4934), // red
+	Warning: tcell.NewHexColor(0xfe8019), // orange
+	Success: tcell.NewHexColor(0xb8bb26), // green
+	Info:    tcell.NewHexColor(0x83a598), // blue
+}
@@ -0,0 +1,50 @@
+package theme
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// GruvboxLight is used for terminals with a light background.
+var GruvboxLight = tview.Theme{
+	PrimitiveBackgroundColor:    tcell.NewHexColor(0xfbf1c7), // bg0_hard
+	ContrastBackgroundColor:     tcell.NewHexColor(0xebdbb2), // bg1
+	MoreContrastBackgroundColor: tcell.NewHexColor(0xd5c4a1), // bg2
+
+	BorderColor:   tcell.NewHexColor(0x3c3836), // fg1
+	TitleColor:    tcell.NewHexColor(0x076678), // blue
+	GraphicsColor: tcell.NewHexColor(0x8f3f71), // purple
+
+	PrimaryTextColor:   tcell.NewHexColor(0x3c3836), // fg1
+	SecondaryTextColor: tcell.NewHexColor(0xb57614), // yellow
+	TertiaryTextColor:  tcell.NewHexColor(0x79740e), // green
+
+	InverseTextColor:           tcell.NewHexColor(0xfbf1c7), // bg
+	ContrastSecondaryTextColor: tcell.NewHexColor(0xaf3a03), // orange
+}
+
+var Light = &Theme{
+	Name: "light",
+	UI:   GruvboxLight,
+
+	Database:  tcell.NewHexColor(0x9d0006), // red
+	Table:     tcell.NewHexColor(0x79740e), // green
+	Row:       tcell.NewHexColor(0x076678), // blue
+	Node:      tcell.NewHexColor(0x3c3836), // fg1
+	Highlight: tcell.NewHexColor(0xaf3a03), // orange
+
+	Heading: tcell.NewHexColor(0xb57614), // yellow
+	Key:     tcell.NewHexColor(0xaf3a03), // orange
+	Value:   tcell.NewHexColor(0x3c3836), // fg1
+	Ref:     tcell.NewHexColor(0x076678), // blue
+
+	Border:             tcell.NewHexColor(0x7c6f64), // fg4
+	FocusBorder:        tcell.NewHexColor(0x79740e), // green
+	SelectedBackground: tcell.NewHexColor(0xd5c4a1), // bg2
+	SelectedForeground: tcell.NewHexColor(0x3c3836), // fg1
+
+	Error:   tcell.NewHexColor(0x9d0006), // red
+	Warning: tcell.NewHexColor(0xaf3a03), // orange
+	Success: tcell.NewHexColor(0x79740e), // green
+	Info:    tcell.NewHexColor(0x076678), // blue
+}
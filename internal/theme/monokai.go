@@ -18,3 +18,29 @@ var Monokai = tview.Theme{
 	InverseTextColor:            tcell.NewHexColor(0x272822), // background
 	ContrastSecondaryTextColor:  tcell.NewHexColor(0xFD971F), // orange
 }
+
+var MonokaiTheme = &Theme{
+	Name: "monokai",
+	UI:   Monokai,
+
+	Database:  tcell.NewHexColor(0xF92672), // pink
+	Table:     tcell.NewHexColor(0xA6E22E), // green
+	Row:       tcell.NewHexColor(0x66D9EF), // blue
+	Node:      tcell.NewHexColor(0xF8F8F2), // foreground
+	Highlight: tcell.NewHexColor(0xE6DB74), // yellow
+
+	Heading: tcell.NewHexColor(0xE6DB74), // yellow
+	Key:     tcell.NewHexColor(0xFD971F), // orange
+	Value:   tcell.NewHexColor(0xF8F8F2), // foreground
+	Ref:     tcell.NewHexColor(0x66D9EF), // blue
+
+	Border:             tcell.NewHexColor(0xF8F8F2), // foreground
+	FocusBorder:        tcell.NewHexColor(0xA6E22E), // green
+	SelectedBackground: tcell.NewHexColor(0x49483E), // even darker
+	SelectedForeground: tcell.NewHexColor(0xE6DB74), // yellow
+
+	Error:   tcell.NewHexColor(0xF92672), // pink
+	Warning: tcell.NewHexColor(0xFD971F), // orange
+	Success: tcell.NewHexColor(0xA6E22E), // green
+	Info:    tcell.NewHexColor(0x66D9EF), // blue
+}
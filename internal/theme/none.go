@@ -0,0 +1,46 @@
+package theme
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// None uses the colors of the terminal, the selection is shown in reverse
+// video.
+var None = &Theme{
+	Name: "none",
+	UI: tview.Theme{
+		PrimitiveBackgroundColor:    tcell.ColorDefault,
+		ContrastBackgroundColor:     tcell.ColorDefault,
+		MoreContrastBackgroundColor: tcell.ColorDefault,
+		BorderColor:                 tcell.ColorDefault,
+		TitleColor:                  tcell.ColorDefault,
+		GraphicsColor:               tcell.ColorDefault,
+		PrimaryTextColor:            tcell.ColorDefault,
+		SecondaryTextColor:          tcell.ColorDefault,
+		TertiaryTextColor:           tcell.ColorDefault,
+		InverseTextColor:            tcell.ColorDefault,
+		ContrastSecondaryTextColor:  tcell.ColorDefault,
+	},
+
+	Database:  tcell.ColorDefault,
+	Table:     tcell.ColorDefault,
+	Row:       tcell.ColorDefault,
+	Node:      tcell.ColorDefault,
+	Highlight: tcell.ColorDefault,
+
+	Heading: tcell.ColorDefault,
+	Key:     tcell.ColorDefault,
+	Value:   tcell.ColorDefault,
+	Ref:     tcell.ColorDefault,
+
+	Border:             tcell.ColorDefault,
+	FocusBorder:        tcell.ColorDefault,
+	SelectedBackground: tcell.ColorDefault,
+	SelectedForeground: tcell.ColorDefault,
+
+	Error:   tcell.ColorDefault,
+	Warning: tcell.ColorDefault,
+	Success: tcell.ColorDefault,
+	Info:    tcell.ColorDefault,
+}
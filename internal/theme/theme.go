@@ -0,0 +1,141 @@
+package theme
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Theme gives the colors of the tview primitives and the colors with a
+// meaning in the views (kind of tree nodes, references, errors, ...).
+type Theme struct {
+	Name string
+	UI   tview.Theme
+
+	// Tree nodes
+	Database tcell.Color
+	Table    tcell.Color
+	Row      tcell.Color
+	Node     tcell.Color
+	// Rows modified by events or a refetch
+	Highlight tcell.Color
+
+	// Status view
+	Heading tcell.Color
+	Key     tcell.Color
+	Value   tcell.Color
+	Ref     tcell.Color
+
+	Border             tcell.Color
+	FocusBorder        tcell.Color
+	SelectedBackground tcell.Color
+	SelectedForeground tcell.Color
+
+	// Messages
+	Error   tcell.Color
+	Warning tcell.Color
+	Success tcell.Color
+	Info    tcell.Color
+}
+
+// Current is the theme used by the views.
+var Current = Gruvbox
+
+var (
+	themes = map[string]*Theme{}
+	// Names in registration order, used to cycle through themes
+	names = []string{}
+)
+
+func init() {
+	Register(Gruvbox)
+	Register(MonokaiTheme)
+	Register(Light)
+	Register(None)
+}
+
+// Register adds t to the themes, replacing the one with the same name.
+func Register(t *Theme) {
+	if _, ok := themes[t.Name]; !ok {
+		names = append(names, t.Name)
+	}
+	themes[t.Name] = t
+}
+
+func Get(name string) (*Theme, bool) {
+	t, ok := themes[name]
+	return t, ok
+}
+
+func Names() []string {
+	return names
+}
+
+// Set makes t the current theme. Primitives created before keep their
+// colors, see ui.ApplyTheme.
+func Set(t *Theme) {
+	Current = t
+	tview.Styles = t.UI
+}
+
+// Next returns the theme registered after the current one.
+func Next() *Theme {
+	for i, name := range names {
+		if name == Current.Name {
+			return themes[names[(i+1)%len(names)]]
+		}
+	}
+	return themes[names[0]]
+}
+
+// Resolve returns the theme with the given name or loads it if name is the
+// path of a theme file.
+func Resolve(name string) (*Theme, error) {
+	if t, ok := themes[name]; ok {
+		return t, nil
+	}
+	if strings.HasSuffix(name, ".toml") {
+		t, err := Load(name)
+		if err != nil {
+			return nil, err
+		}
+		Register(t)
+		return t, nil
+	}
+	return nil, fmt.Errorf("unknown theme %s (available: %s)", name, strings.Join(names, ", "))
+}
+
+// SelectedStyle is the style of the selected line of tables and trees.
+func (t *Theme) SelectedStyle() tcell.Style {
+	style := tcell.StyleDefault.
+		Background(t.SelectedBackground).
+		Foreground(t.SelectedForeground)
+	if t.SelectedBackground == tcell.ColorDefault {
+		// Without colors the selection is only visible in reverse video
+		style = style.Reverse(true)
+	}
+	return style
+}
+
+// Colorize replaces the color tags of messages written for the default
+// palette ([red], [green], ...) by the colors of the theme with the same
+// meaning.
+func (t *Theme) Colorize(s string) string {
+	return strings.NewReplacer(
+		"[red]", tag(t.Error),
+		"[orange]", tag(t.Warning),
+		"[green]", tag(t.Success),
+		"[blue]", tag(t.Info),
+		"[yellow]", tag(t.Heading),
+		"[white]", tag(t.Value),
+	).Replace(s)
+}
+
+func tag(c tcell.Color) string {
+	if c == tcell.ColorDefault {
+		return "[-]"
+	}
+	return fmt.Sprintf("[#%06x]", c.Hex())
+}
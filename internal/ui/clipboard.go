@@ -0,0 +1,23 @@
+package ui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/atotto/clipboard"
+)
+
+// CopyToClipboard uses the system clipboard (xclip, xsel, wl-copy, ...) and
+// falls back to the OSC 52 escape sequence, which most terminals support and
+// which also works through SSH.
+func CopyToClipboard(text string) error {
+	if !clipboard.Unsupported {
+		if err := clipboard.WriteAll(text); err == nil {
+			return nil
+		}
+	}
+
+	_, err := fmt.Fprintf(os.Stdout, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	return err
+}
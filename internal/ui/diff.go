@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/rivo/tview"
+
+	"example.com/readxapidb/internal/diff"
+	"example.com/readxapidb/internal/theme"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// MakeDiffView creates a table listing the findings of the report, one per
+// line. The reference of each line is its finding.
+func MakeDiffView(report *diff.Report) *tview.Table {
+	dv := tview.NewTable()
+	dv.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0).
+		SetBorder(true).
+		SetTitle(fmt.Sprintf("Diff: A=%s B=%s (%d)", report.AName, report.BName, len(report.Findings)))
+
+	for col, h := range []string{"Kind", "Table", "Object", "Field", "A", "B"} {
+		dv.SetCell(0, col, tview.NewTableCell(h).SetTextColor(theme.Current.Heading).SetSelectable(false))
+	}
+
+	for i, f := range report.Findings {
+		row := i + 1
+
+		color := theme.Current.Warning
+		switch f.Kind {
+		case diff.OnlyInA, diff.LoadError:
+			color = theme.Current.Error
+		case diff.OnlyInB:
+			color = theme.Current.Success
+		}
+
+		object := f.Ref
+		if f.Label != "" {
+			object = f.Label
+		}
+
+		dv.SetCell(row, 0, tview.NewTableCell(f.Kind.String()).SetTextColor(color).SetReference(f))
+		dv.SetCell(row, 1, tview.NewTableCell(f.Table))
+		dv.SetCell(row, 2, tview.NewTableCell(object))
+		dv.SetCell(row, 3, tview.NewTableCell(f.Field))
+		dv.SetCell(row, 4, tview.NewTableCell(xapidb.UnescapeValue(f.A)).SetMaxWidth(40))
+		dv.SetCell(row, 5, tview.NewTableCell(xapidb.UnescapeValue(f.B)).SetMaxWidth(40))
+	}
+
+	return dv
+}
+
+// SelectedDiffCallback is called when a finding is selected. It goes back to
+// the tree and selects the object if it exists in the browsed database.
+func SelectedDiffCallback(
+	app *tview.Application,
+	tree *tview.TreeView,
+	status *tview.Table,
+	debugView *tview.TextView,
+	pages *tview.Pages,
+	db *xapidb.DB,
+	diffView *tview.Table,
+	history *History,
+) func(row, column int) {
+	return func(row, column int) {
+		ref := diffView.GetCell(row, 0).GetReference()
+		if ref == nil {
+			return
+		}
+		f := ref.(diff.Finding)
+
+		pages.SwitchToPage("normal")
+		app.SetFocus(tree)
+
+		debugView.Clear()
+		Logf(debugView, "[yellow]%s[white] %s %s %s\n", f.Kind, f.Table, f.Ref, f.Field)
+
+		if result := JumpTo(app, tree, status, db, history, f.Ref); result != "done" {
+			Logf(debugView, "[red]%s", result)
+		}
+	}
+}
+
+// SetDiffPage creates (or replaces) the "diff" page showing the report.
+func SetDiffPage(
+	app *tview.Application,
+	tree *tview.TreeView,
+	status *tview.Table,
+	debugView *tview.TextView,
+	pages *tview.Pages,
+	help *tview.TextView,
+	db *xapidb.DB,
+	report *diff.Report,
+	history *History,
+) {
+	diffView := MakeDiffView(report)
+	diffView.SetSelectedFunc(SelectedDiffCallback(app, tree, status, debugView, pages, db, diffView, history))
+
+	diffLayout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(diffView, 0, 1, true).
+		AddItem(help, 1, 0, false)
+
+	pages.AddPage("diff", diffLayout, true, false)
+}
@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"example.com/readxapidb/internal/schema"
+	"example.com/readxapidb/internal/theme"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// FieldInfo shows the type and the documentation of the attribute selected
+// in the status, or of the class of the selected table.
+type FieldInfo struct {
+	*tview.TextView
+	tree   *tview.TreeView
+	status *tview.Table
+}
+
+func NewFieldInfo(tree *tview.TreeView, status *tview.Table) *FieldInfo {
+	fi := &FieldInfo{
+		TextView: tview.NewTextView(),
+		tree:     tree,
+		status:   status,
+	}
+	fi.SetDynamicColors(true).
+		SetWordWrap(true).
+		SetBorder(true).
+		SetTitle("Field")
+	return fi
+}
+
+// Draw updates the text with the current selection, so it follows the
+// status without hooking all the places that change it.
+func (fi *FieldInfo) Draw(screen tcell.Screen) {
+	fi.SetTextColor(theme.Current.UI.PrimaryTextColor)
+	fi.SetText(theme.Current.Colorize(fi.describe()))
+	fi.TextView.Draw(screen)
+}
+
+func (fi *FieldInfo) describe() string {
+	tn := fi.tree.GetCurrentNode()
+	if tn == nil {
+		return ""
+	}
+	n, ok := tn.GetReference().(*xapidb.Node)
+	if !ok {
+		return ""
+	}
+
+	switch n.Name {
+	case "table":
+		return describeClass(n.Attr["name"])
+	case "row":
+	default:
+		return ""
+	}
+
+	row, _ := fi.status.GetSelection()
+	field, ok := fi.status.GetCell(row, 0).GetReference().(string)
+	if !ok {
+		return describeClass(n.Parent.Attr["name"])
+	}
+
+	f, ok := schema.Current.Field(n.Parent.Attr["name"], field)
+	if !ok {
+		return fmt.Sprintf("[yellow]%s[white] is not in the schema %d.%d",
+			tview.Escape(field), schema.Current.Major, schema.Current.Minor)
+	}
+	return describeField(f)
+}
+
+func describeClass(name string) string {
+	c := schema.Current.Class(name)
+	if c == nil {
+		return fmt.Sprintf("[yellow]%s[white] is not in the schema %d.%d",
+			tview.Escape(name), schema.Current.Major, schema.Current.Minor)
+	}
+	return fmt.Sprintf("[yellow]%s[white] (%d fields): %s", tview.Escape(c.Name), len(c.Fields), tview.Escape(c.Doc))
+}
+
+func describeField(f *schema.Field) string {
+	text := fmt.Sprintf("[yellow]%s[white] [blue]%s[white]", tview.Escape(f.Name), tview.Escape(f.Type.String()))
+	if f.Release != "" {
+		text += fmt.Sprintf(" (since %s)", tview.Escape(f.Release))
+	}
+	if values := enumValues(f.Type); len(values) > 0 {
+		text += fmt.Sprintf("\nValues: %s", tview.Escape(strings.Join(values, ", ")))
+	}
+	if f.Doc != "" {
+		text += "\n" + tview.Escape(f.Doc)
+	}
+	return text
+}
+
+// enumValues returns the values of the enum of a field, also for sets and
+// maps of enums.
+func enumValues(t schema.Type) []string {
+	switch t.Kind {
+	case schema.KindEnum:
+		return t.Values
+	case schema.KindSet:
+		return enumValues(*t.Elem)
+	case schema.KindMap:
+		if values := enumValues(*t.Elem); len(values) > 0 {
+			return values
+		}
+		return enumValues(*t.Key)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// RawValues shows the values of the attributes as stored in the database
+// instead of their human-friendly rendering.
+var RawValues = false
+
+// FormatValue returns the human-friendly rendering of the value of an
+// attribute, or false if it is shown as it is. gen is the generation count
+// of the database (-1 if unknown).
+func FormatValue(field, value string, gen int64) (string, bool) {
+	switch field {
+	case "__ctime", "__mtime":
+		return formatGeneration(value, gen)
+	}
+
+	if t, ok := parseTimestamp(value); ok {
+		if t.Unix() == 0 {
+			return "never", true
+		}
+		return fmt.Sprintf("%s (%s)", t.Local().Format("2006-01-02 15:04:05"), age(time.Since(t))), true
+	}
+
+	if isSizeField(field) {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil && n >= 1024 {
+			return FormatSize(n), true
+		}
+	}
+
+	return "", false
+}
+
+func parseTimestamp(value string) (time.Time, bool) {
+	// Quick check before trying the layouts
+	if len(value) < 17 || value[8] != 'T' {
+		return time.Time{}, false
+	}
+	t, err := xapidb.ParseDateTime(value)
+	return t, err == nil
+}
+
+// age returns a duration as "3 days ago" (or "in 3 days" for the future).
+func age(d time.Duration) string {
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var s string
+	switch {
+	case d < time.Minute:
+		s = plural(int64(d/time.Second), "second")
+	case d < time.Hour:
+		s = plural(int64(d/time.Minute), "minute")
+	case d < 24*time.Hour:
+		s = plural(int64(d/time.Hour), "hour")
+	case d < 365*24*time.Hour:
+		s = plural(int64(d/(24*time.Hour)), "day")
+	default:
+		s = plural(int64(d/(365*24*time.Hour)), "year")
+	}
+
+	if future {
+		return "in " + s
+	}
+	return s + " ago"
+}
+
+func plural(n int64, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// isSizeField returns true for the fields holding a number of bytes
+// (memory__total, virtual_size, physical_utilisation, ...).
+func isSizeField(field string) bool {
+	if strings.HasPrefix(field, "memory") {
+		return true
+	}
+	for _, suffix := range []string{"size", "utilisation", "allocation"} {
+		if strings.HasSuffix(field, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatSize returns a number of bytes in the largest binary unit
+// (8584744960 is "8.00 GiB").
+func FormatSize(n int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	size := float64(n)
+	unit := 0
+	for size >= 1024 && unit < len(units)-1 {
+		size /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.2f %s", size, units[unit])
+}
+
+// formatGeneration shows a generation relative to the generation count of
+// the manifest.
+func formatGeneration(value string, gen int64) (string, bool) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || gen < 0 {
+		return "", false
+	}
+	switch {
+	case n == gen:
+		return fmt.Sprintf("%d (current generation)", n), true
+	case n < gen:
+		return fmt.Sprintf("%d (%s before %d)", n, plural(gen-n, "generation"), gen), true
+	}
+	return fmt.Sprintf("%d (after the manifest %d)", n, gen), true
+}
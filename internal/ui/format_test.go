@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestFormatValue(t *testing.T) {
+	local := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = local })
+
+	for _, tt := range []struct {
+		field string
+		value string
+		gen   int64
+		// want is a regexp, the age of dates depends on today
+		want string
+		ok   bool
+	}{
+		{field: "__mtime", value: "12", gen: 12, want: `^12 \(current generation\)$`, ok: true},
+		{field: "__ctime", value: "10", gen: -1},
+		{field: "snapshot_time", value: "20250401T08:00:00Z", want: `^2025-04-01 08:00:00 \(\d+ (day|year)s? ago\)$`, ok: true},
+		{field: "expiry", value: "21000101T00:00:00+02:00", want: `^2099-12-31 22:00:00 \(in \d+ years\)$`, ok: true},
+		{field: "snapshot_time", value: "19700101T00:00:00Z", want: `^never$`, ok: true},
+		{field: "name__label", value: "20250401T08:00", gen: 12},
+		{field: "memory__total", value: "8584744960", want: `^8\.00 GiB$`, ok: true},
+		{field: "virtual_size", value: "1536", want: `^1\.50 KiB$`, ok: true},
+		{field: "memory__overhead", value: "1023"},
+		{field: "physical_utilisation", value: "-1"},
+		{field: "name__label", value: "8584744960"},
+		{field: "uuid", value: "2b7f8e4c-1d3a-4f5b-9c6d-7e8f9a0b1c2d"},
+	} {
+		got, ok := FormatValue(tt.field, tt.value, tt.gen)
+		if ok != tt.ok || (ok && !regexp.MustCompile(tt.want).MatchString(got)) {
+			t.Errorf("FormatValue(%q, %q, %d) = %q, %t, want %s", tt.field, tt.value, tt.gen, got, ok, tt.want)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for _, tt := range []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.00 KiB"},
+		{37748736, "36.00 MiB"},
+		{8584744960, "8.00 GiB"},
+		{1 << 40, "1.00 TiB"},
+		{1 << 62, "4096.00 PiB"},
+		{-2048, "-2048 B"},
+	} {
+		if got := FormatSize(tt.n); got != tt.want {
+			t.Errorf("FormatSize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFormatGeneration(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		gen   int64
+		want  string
+		ok    bool
+	}{
+		{"12", 12, "12 (current generation)", true},
+		{"11", 12, "11 (1 generation before 12)", true},
+		{"2", 12, "2 (10 generations before 12)", true},
+		{"13", 12, "13 (after the manifest 12)", true},
+		{"12", -1, "", false},
+		{"", 12, "", false},
+		{"twelve", 12, "", false},
+	} {
+		got, ok := formatGeneration(tt.value, tt.gen)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("formatGeneration(%q, %d) = %q, %t, want %q, %t", tt.value, tt.gen, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAge(t *testing.T) {
+	day := 24 * time.Hour
+	for _, tt := range []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0 seconds ago"},
+		{time.Second, "1 second ago"},
+		{59 * time.Second, "59 seconds ago"},
+		{time.Minute, "1 minute ago"},
+		{90 * time.Minute, "1 hour ago"},
+		{23 * time.Hour, "23 hours ago"},
+		{day, "1 day ago"},
+		{364 * day, "364 days ago"},
+		{365 * day, "1 year ago"},
+		{3 * 365 * day, "3 years ago"},
+		{-2 * time.Minute, "in 2 minutes"},
+		{-400 * day, "in 1 year"},
+	} {
+		if got := age(tt.d); got != tt.want {
+			t.Errorf("age(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"example.com/readxapidb/internal/schema"
+	"example.com/readxapidb/internal/theme"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// Grid shows the rows of a table as lines and their fields as columns. The
+// selected cell gives the column to sort or filter.
+type Grid struct {
+	app       *tview.Application
+	tree      *tview.TreeView
+	status    *tview.Table
+	debugView *tview.TextView
+	pages     *tview.Pages
+	db        *xapidb.DB
+	history   *History
+	keys      Keymap
+
+	// Columns chosen by table, saveColumns is called when they change
+	columns     map[string][]string
+	saveColumns func() error
+
+	layout  *tview.Flex
+	inner   *tview.Pages
+	view    *tview.Table
+	filter  *tview.InputField
+	chooser *tview.Table
+	help    *tview.TextView
+
+	table   *xapidb.Node
+	fields  []string
+	sortBy  string
+	desc    bool
+	filters map[string]string
+}
+
+// NewGrid creates the grid, it is added to pages as "grid" and shown with
+// Open.
+func NewGrid(
+	app *tview.Application,
+	tree *tview.TreeView,
+	status *tview.Table,
+	debugView *tview.TextView,
+	pages *tview.Pages,
+	db *xapidb.DB,
+	history *History,
+	keys Keymap,
+	columns map[string][]string,
+	saveColumns func() error,
+) *Grid {
+	g := &Grid{
+		app:         app,
+		tree:        tree,
+		status:      status,
+		debugView:   debugView,
+		pages:       pages,
+		db:          db,
+		history:     history,
+		keys:        keys,
+		columns:     columns,
+		saveColumns: saveColumns,
+	}
+
+	g.view = tview.NewTable()
+	g.view.SetSelectable(true, true).
+		SetFixed(1, 1).
+		SetBorder(true)
+	g.view.SetSelectedFunc(func(row, column int) { g.openRow(row) })
+
+	g.filter = tview.NewInputField()
+	g.filter.SetDoneFunc(g.doneFilter)
+
+	g.chooser = tview.NewTable()
+	g.chooser.SetSelectable(true, false).
+		SetBorder(true)
+	g.chooser.SetSelectedFunc(func(row, column int) { g.toggleColumn(row) })
+
+	g.help = tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter)
+
+	g.inner = tview.NewPages().
+		AddPage("table", g.view, true, true).
+		AddPage("columns", g.chooser, true, false)
+
+	g.layout = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(g.inner, 0, 1, true).
+		AddItem(g.filter, 0, 0, false).
+		AddItem(g.help, 1, 0, false)
+	g.layout.SetInputCapture(g.inputCapture)
+
+	pages.AddPage("grid", g.layout, true, false)
+	return g
+}
+
+// Open shows the rows of table.
+func (g *Grid) Open(table *xapidb.Node) {
+	if g.table != table {
+		g.table = table
+		g.sortBy = ""
+		g.desc = false
+		g.filters = map[string]string{}
+	}
+
+	if err := table.Load(); err != nil {
+		Logf(g.debugView, "[red]%s", tview.Escape(err.Error()))
+	}
+
+	// Fields of all rows, rows of live tables don't always have the same
+	fields := map[string]bool{}
+	for _, row := range table.Children {
+		for k := range row.Attr {
+			fields[k] = true
+		}
+	}
+	g.fields = make([]string, 0, len(fields))
+	for k := range fields {
+		g.fields = append(g.fields, k)
+	}
+	sort.Strings(g.fields)
+
+	SetHelp(g.help, fmt.Sprintf(
+		"[yellow]'Enter'[white]=open row | [yellow]'%s'[white]=sort | [yellow]'%s'[white]=filter | [yellow]'%s'[white]=columns | [yellow]'%s'[white]=close",
+		g.keys.Keys(ActionSort), g.keys.Keys(ActionFilter), g.keys.Keys(ActionColumns), g.keys.Keys(ActionBack)))
+
+	g.inner.SwitchToPage("table")
+	g.render()
+	g.view.Select(1, 1)
+	g.pages.SwitchToPage("grid")
+	g.app.SetFocus(g.view)
+}
+
+// shownColumns returns the chosen columns of the table, all its fields by
+// default.
+func (g *Grid) shownColumns() []string {
+	chosen, ok := g.columns[g.table.Attr["name"]]
+	if !ok {
+		return g.fields
+	}
+	return slices.DeleteFunc(slices.Clone(chosen), func(c string) bool {
+		return !slices.Contains(g.fields, c)
+	})
+}
+
+// rowLabel is shown in the first column.
+func rowLabel(row *xapidb.Node) string {
+	for _, k := range []string{"name__label", "uuid", "ref"} {
+		if v := row.Attr[k]; v != "" {
+			return xapidb.UnescapeValue(v)
+		}
+	}
+	return ""
+}
+
+// rows returns the rows matching the filters, sorted.
+func (g *Grid) rows() []*xapidb.Node {
+	rows := []*xapidb.Node{}
+	for _, row := range g.table.Children {
+		match := true
+		for field, f := range g.filters {
+			value := xapidb.UnescapeValue(row.Attr[field])
+			if !strings.Contains(strings.ToLower(value), strings.ToLower(f)) {
+				match = false
+				break
+			}
+		}
+		if match {
+			rows = append(rows, row)
+		}
+	}
+
+	if g.sortBy != "" {
+		sort.SliceStable(rows, func(i, j int) bool {
+			c := compareValues(rows[i].Attr[g.sortBy], rows[j].Attr[g.sortBy])
+			if g.desc {
+				return c > 0
+			}
+			return c < 0
+		})
+	}
+
+	return rows
+}
+
+// compareValues compares numbers by value and other values as strings.
+func compareValues(a, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+func (g *Grid) render() {
+	t := theme.Current
+	columns := g.shownColumns()
+	rows := g.rows()
+
+	g.view.Clear()
+	g.view.SetSelectedStyle(t.SelectedStyle())
+	g.view.SetTitle(fmt.Sprintf("%s (%d/%d rows)", g.table.Attr["name"], len(rows), len(g.table.Children)))
+
+	g.view.SetCell(0, 0, tview.NewTableCell("").SetSelectable(false))
+	for col, field := range columns {
+		header := field
+		if field == g.sortBy {
+			if g.desc {
+				header += " ▼"
+			} else {
+				header += " ▲"
+			}
+		}
+		if f, ok := g.filters[field]; ok {
+			header += fmt.Sprintf(" ~%q", f)
+		}
+		g.view.SetCell(0, col+1, tview.NewTableCell(header).
+			SetTextColor(t.Heading).
+			SetSelectable(false).
+			SetReference(field))
+	}
+
+	for i, row := range rows {
+		g.view.SetCell(i+1, 0, tview.NewTableCell(rowLabel(row)).
+			SetTextColor(t.Row).
+			SetMaxWidth(30).
+			SetReference(row))
+		for col, field := range columns {
+			v := row.Attr[field]
+			cell := tview.NewTableCell(xapidb.UnescapeValue(v)).
+				SetTextColor(t.Value).
+				SetMaxWidth(40)
+			if _, ok := xapidb.FieldRef(schema.Current, row, field); ok {
+				cell.SetTextColor(t.Ref)
+			}
+			g.view.SetCell(i+1, col+1, cell)
+		}
+	}
+}
+
+// selectedField returns the field of the selected column.
+func (g *Grid) selectedField() (string, bool) {
+	_, col := g.view.GetSelection()
+	field, ok := g.view.GetCell(0, col).GetReference().(string)
+	return field, ok
+}
+
+// openRow shows the row in the tree and the status.
+func (g *Grid) openRow(line int) {
+	row, ok := g.view.GetCell(line, 0).GetReference().(*xapidb.Node)
+	if !ok {
+		return
+	}
+
+	g.pages.SwitchToPage("normal")
+	g.app.SetFocus(g.tree)
+
+	g.debugView.Clear()
+	if result := JumpTo(g.app, g.tree, g.status, g.db, g.history, row.Attr["ref"]); result != "done" {
+		Logf(g.debugView, "[red]%s", result)
+	}
+}
+
+func (g *Grid) close() {
+	g.pages.SwitchToPage("normal")
+	g.app.SetFocus(g.tree)
+}
+
+// inputCapture handles the keys of the grid, the filter field gets all
+// keys while it is edited.
+func (g *Grid) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	if g.app.GetFocus() == g.filter {
+		return event
+	}
+
+	action, ok := g.keys.Action(event)
+	if !ok {
+		return event
+	}
+
+	if front, _ := g.inner.GetFrontPage(); front == "columns" {
+		if action == ActionBack || action == ActionColumns {
+			g.closeChooser()
+			return nil
+		}
+		return event
+	}
+
+	switch action {
+	case ActionBack, ActionGrid:
+		g.close()
+		return nil
+
+	case ActionSort:
+		if field, ok := g.selectedField(); ok {
+			if g.sortBy == field {
+				g.desc = !g.desc
+			} else {
+				g.sortBy = field
+				g.desc = false
+			}
+			g.render()
+		}
+		return nil
+
+	case ActionFilter:
+		if field, ok := g.selectedField(); ok {
+			g.filter.SetLabel(fmt.Sprintf("Filter %s: ", field)).
+				SetText(g.filters[field])
+			g.layout.ResizeItem(g.filter, 1, 0)
+			g.app.SetFocus(g.filter)
+		}
+		return nil
+
+	case ActionColumns:
+		g.openChooser()
+		return nil
+	}
+
+	return event
+}
+
+// doneFilter sets the filter of the selected column, an empty filter
+// removes it.
+func (g *Grid) doneFilter(key tcell.Key) {
+	if key == tcell.KeyEnter {
+		if field, ok := g.selectedField(); ok {
+			if text := g.filter.GetText(); text != "" {
+				g.filters[field] = text
+			} else {
+				delete(g.filters, field)
+			}
+			g.render()
+			if g.view.GetRowCount() > 1 {
+				_, col := g.view.GetSelection()
+				g.view.Select(1, col)
+			}
+		}
+	}
+
+	g.layout.ResizeItem(g.filter, 0, 0)
+	g.app.SetFocus(g.view)
+}
+
+func (g *Grid) openChooser() {
+	shown := g.shownColumns()
+
+	g.chooser.Clear()
+	g.chooser.SetTitle(fmt.Sprintf("Columns of %s (Enter to toggle)", g.table.Attr["name"]))
+	g.chooser.SetSelectedStyle(theme.Current.SelectedStyle())
+	for i, field := range g.fields {
+		g.chooser.SetCell(i, 0, checkbox(slices.Contains(shown, field)))
+		g.chooser.SetCell(i, 1, tview.NewTableCell(field).SetTextColor(theme.Current.Key))
+	}
+
+	g.inner.SwitchToPage("columns")
+	g.app.SetFocus(g.chooser)
+}
+
+// checkbox returns a cell showing whether the column is chosen, the state
+// is its reference.
+func checkbox(checked bool) *tview.TableCell {
+	text := " "
+	if checked {
+		text = "✓"
+	}
+	return tview.NewTableCell(text).SetReference(checked)
+}
+
+func (g *Grid) toggleColumn(row int) {
+	checked := g.chooser.GetCell(row, 0).GetReference().(bool)
+	g.chooser.SetCell(row, 0, checkbox(!checked))
+}
+
+// closeChooser remembers the chosen columns of the table.
+func (g *Grid) closeChooser() {
+	chosen := []string{}
+	for i, field := range g.fields {
+		if g.chooser.GetCell(i, 0).GetReference().(bool) {
+			chosen = append(chosen, field)
+		}
+	}
+	if len(chosen) == len(g.fields) {
+		// Keep showing all fields, even new ones
+		delete(g.columns, g.table.Attr["name"])
+	} else {
+		g.columns[g.table.Attr["name"]] = chosen
+	}
+
+	if err := g.saveColumns(); err != nil {
+		Logf(g.debugView, "\n[red]Failed to save the columns: %s", err)
+	}
+
+	g.inner.SwitchToPage("table")
+	g.render()
+	g.app.SetFocus(g.view)
+}
@@ -1,12 +1,13 @@
 package ui
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
+	"example.com/readxapidb/internal/schema"
+	"example.com/readxapidb/internal/validate"
 	"example.com/readxapidb/internal/xapidb"
 )
 
@@ -14,14 +15,16 @@ import (
 // by hitting Enter when selected
 func SelectedTreeCallback(status *tview.Table) func(tn *tview.TreeNode) {
 	return func(tn *tview.TreeNode) {
-		// We are always setting a reference so let panic
-		// if it is not the case...
-		node := tn.GetReference().(*xapidb.Node)
+		// Load errors don't reference a node
+		node, ok := tn.GetReference().(*xapidb.Node)
+		if !ok {
+			return
+		}
 
 		UpdateStatus(status, node)
 
 		// Load children if not already loaded
-		if len(tn.GetChildren()) == 0 && len(node.Children) > 0 {
+		if len(tn.GetChildren()) == 0 && node.Len() > 0 {
 			LoadChildren(tn, node)
 			tn.SetExpanded(false)
 		}
@@ -39,6 +42,7 @@ func SelectedStatusCallback(
 	app *tview.Application,
 	tree *tview.TreeView,
 	db *xapidb.DB,
+	history *History,
 ) func(row, column int) {
 	return func(row, column int) {
 		valueCell := status.GetCell(row, 1)
@@ -56,20 +60,20 @@ func SelectedStatusCallback(
 		}
 
 		debugView.Clear()
-		fmt.Fprintf(debugView, "Text: %s (len=%d)", text, len(text))
+		Logf(debugView, "Text: %s (len=%d)", text, len(text))
 		if len(text) > 0 {
 			preview := text[:min(3, len(text))]
-			fmt.Fprintf(debugView, "\nFirst 3 chars: %q", preview)
+			Logf(debugView, "\nFirst 3 chars: %q", preview)
 		}
 
-		if strings.HasPrefix(text, "OpaqueRef") {
-			if retString := FollowOpaqueRef(app, tree, db, text); retString == "done" {
-				fmt.Fprintf(debugView, "\n[green]Found the opaque reference")
+		if ref, ok := selectedRef(tree, status); ok {
+			if retString := JumpTo(app, tree, status, db, history, ref.Ref); retString == "done" {
+				Logf(debugView, "\n[green]Found the opaque reference")
 			} else {
-				fmt.Fprintf(debugView, "\n[red]%s", retString)
+				Logf(debugView, "\n[red]%s", retString)
 			}
 		} else {
-			fmt.Fprintf(debugView, "\n[blue]No match")
+			Logf(debugView, "\n[blue]No match")
 		}
 	}
 }
@@ -84,34 +88,32 @@ func DoneSearchCallback(
 	debugView *tview.TextView,
 	db *xapidb.DB,
 	pages *tview.Pages,
+	history *History,
 ) func(key tcell.Key) {
 	return func(key tcell.Key) {
 		switch key {
 		case tcell.KeyEnter:
 			query := searchInput.GetText()
 
-			if strings.HasPrefix(query, "OpaqueRef") {
+			ref, isUUID := db.RefByUUID(query)
+			if strings.HasPrefix(query, "OpaqueRef") || isUUID {
+				if !isUUID {
+					ref = query
+				}
 				// Follow the reference
-				result := FollowOpaqueRef(app, tree, db, query)
+				result := JumpTo(app, tree, status, db, history, ref)
 				debugView.Clear()
-				fmt.Fprintf(debugView, "[yellow]Search:[white] %s\n", query)
+				Logf(debugView, "[yellow]Search:[white] %s\n", query)
 				if result == "done" {
-					// Update status
-					if currentNode := tree.GetCurrentNode(); currentNode != nil {
-						if ref := currentNode.GetReference(); ref != nil {
-							node := ref.(*xapidb.Node)
-							UpdateStatus(status, node)
-						}
-					}
-					fmt.Fprintf(debugView, "[green]Found reference!")
+					Logf(debugView, "[green]Found reference!")
 				} else {
-					fmt.Fprintf(debugView, "[red]%s", result)
+					Logf(debugView, "[red]%s", result)
 				}
 			} else {
 				// TODO: General text search in nodes
 				debugView.Clear()
-				fmt.Fprintf(debugView, "[yellow]Searching for:[white] %s\n", query)
-				fmt.Fprintf(debugView, "[blue]Text search not implemented yet")
+				Logf(debugView, "[yellow]Searching for:[white] %s\n", query)
+				Logf(debugView, "[blue]Text search not implemented yet")
 			}
 
 		case tcell.KeyEscape:
@@ -123,55 +125,329 @@ func DoneSearchCallback(
 	}
 }
 
-// InputCaptureCallback handles global keyboard input
+// InputCaptureCallback handles global keyboard input using the actions of
+// the keymap. refresh is used to reload the selected row from its source, it
+// is nil when the source cannot be refreshed (database file). reload loads
+// the whole database again, it is nil if it is not supported.
 func InputCaptureCallback(
 	app *tview.Application,
 	tree *tview.TreeView,
 	status *tview.Table,
 	searchInput *tview.InputField,
+	debugView *tview.TextView,
 	pages *tview.Pages,
 	currentFocus *tview.Primitive,
+	refresh RefreshFunc,
+	db *xapidb.DB,
+	reload ReloadFunc,
+	keys Keymap,
+	history *History,
+	grid *Grid,
+	viewer *ValueViewer,
 ) func(event *tcell.EventKey) *tcell.EventKey {
+	followRef := SelectedStatusCallback(status, debugView, app, tree, db, history)
+
+	backToTree := func() {
+		pages.SwitchToPage("normal")
+		*currentFocus = tree
+		app.SetFocus(tree)
+	}
+
 	return func(event *tcell.EventKey) *tcell.EventKey {
 		currentPage, _ := pages.GetFrontPage()
 		inSearchMode := currentPage == "search"
 
-		switch event.Key() {
-		case tcell.KeyRune:
-			switch event.Rune() {
-			case 'q':
-				if *currentFocus != searchInput {
-					app.Stop()
-					return nil
-				}
+		action, ok := keys.Action(event)
+		if !ok {
+			return event
+		}
 
-			case '/':
-				// switch to search mode if not already in
-				if !inSearchMode {
-					pages.SwitchToPage("search")
-					*currentFocus = searchInput
-					app.SetFocus(*currentFocus)
-				} else {
-					pages.SwitchToPage("normal")
-					*currentFocus = tree
-					app.SetFocus(*currentFocus)
-				}
+		// The grid and the value viewer handle their own keys
+		if currentPage == "grid" || currentPage == "value" {
+			if _, typing := app.GetFocus().(*tview.InputField); action == ActionQuit && !typing {
+				app.Stop()
 				return nil
+			}
+			return event
+		}
 
-			case 'h', 'l':
-				*currentFocus = ToggleFocus(app, currentFocus, tree, status)
+		// The diff, validation, hosts and help views only handle leaving
+		// them, other keys are for their widget.
+		if currentPage == "diff" || currentPage == "validation" || currentPage == "hosts" || currentPage == "help" || currentPage == "history" {
+			if action == ActionBack && event.Key() == tcell.KeyBackspace2 {
+				// Backspace is used to edit the filters of some views
+				return event
+			}
+			switch {
+			case action == ActionBack,
+				action == ActionDiff && currentPage == "diff",
+				action == ActionValidate && currentPage == "validation",
+				action == ActionHosts && currentPage == "hosts",
+				action == ActionHelp && currentPage == "help",
+				action == ActionHistory && currentPage == "history":
+				backToTree()
+				return nil
+			case action == ActionQuit:
+				app.Stop()
 				return nil
 			}
+			return event
+		}
+
+		// Letters are typed in the search bar, only the search key closes it
+		if *currentFocus == searchInput && action != ActionSearch && isEditingKey(event) {
+			return event
+		}
+
+		switch action {
+		case ActionQuit:
+			app.Stop()
+			return nil
+
+		case ActionSearch:
+			// switch to search mode if not already in
+			if !inSearchMode {
+				pages.SwitchToPage("search")
+				*currentFocus = searchInput
+				app.SetFocus(*currentFocus)
+			} else {
+				backToTree()
+			}
+			return nil
 
-		case tcell.KeyTab:
+		case ActionBack:
+			if inSearchMode {
+				searchInput.SetText("")
+				backToTree()
+			} else {
+				MoveInHistory(app, tree, status, debugView, db, history, false)
+			}
+			return nil
+
+		case ActionForward:
+			MoveInHistory(app, tree, status, debugView, db, history, true)
+			return nil
+
+		case ActionHistory:
+			if list, ok := pages.GetPage("history").(*tview.List); ok {
+				SetHistoryList(list, db, history)
+				pages.SwitchToPage("history")
+				return nil
+			}
+
+		case ActionFocusSwitch:
+			*currentFocus = ToggleFocus(app, currentFocus, tree, status)
+			return nil
+
+		case ActionFocusNext:
 			if inSearchMode {
 				*currentFocus = ToggleFocus(app, currentFocus, tree, status, searchInput)
 			} else {
 				*currentFocus = ToggleFocus(app, currentFocus, tree, status)
 			}
 			return nil
+
+		case ActionFollowRef:
+			if *currentFocus == status {
+				row, column := status.GetSelection()
+				followRef(row, column)
+				return nil
+			}
+
+		case ActionDiff:
+			if pages.HasPage("diff") {
+				pages.SwitchToPage("diff")
+				return nil
+			}
+
+		case ActionValidate:
+			if pages.HasPage("validation") {
+				ShowValidation(pages, debugView, validate.Validate(db, schema.Current))
+				return nil
+			}
+
+		case ActionHosts:
+			if pages.HasPage("hosts") {
+				pages.SwitchToPage("hosts")
+				return nil
+			}
+
+		case ActionHelp:
+			if hv, ok := pages.GetPage("help").(*tview.Table); ok {
+				SetHelpView(hv, keys)
+				pages.SwitchToPage("help")
+				return nil
+			}
+
+		case ActionRefresh:
+			if refresh != nil {
+				RefreshCurrentRow(app, tree, status, debugView, db, refresh)
+				return nil
+			}
+
+		case ActionReload:
+			if reload != nil {
+				Reload(app, tree, status, debugView, db, reload)
+				return nil
+			}
+
+		case ActionValue, ActionCopy:
+			if *currentFocus == status {
+				if field, value, ok := selectedAttribute(tree, status); ok {
+					if action == ActionValue {
+						viewer.Open(field, value)
+					} else {
+						copyValue(debugView, xapidb.UnescapeValue(value))
+					}
+					return nil
+				}
+			}
+
+		case ActionRaw:
+			RawValues = !RawValues
+			if tn := tree.GetCurrentNode(); tn != nil {
+				if n, ok := tn.GetReference().(*xapidb.Node); ok {
+					UpdateStatus(status, n)
+				}
+			}
+			debugView.Clear()
+			if RawValues {
+				Logf(debugView, "[blue]Showing raw values")
+			} else {
+				Logf(debugView, "[blue]Showing human-friendly values")
+			}
+			return nil
+
+		case ActionGrid:
+			if tn := tree.GetCurrentNode(); tn != nil {
+				n, ok := tn.GetReference().(*xapidb.Node)
+				if ok && n.Name == "row" {
+					n = n.Parent
+				}
+				if ok && n.Name == "table" {
+					grid.Open(n)
+					return nil
+				}
+			}
+
+		case ActionTheme:
+			CycleTheme(app, pages, tree, status, debugView)
+			return nil
 		}
 
 		return event
 	}
 }
+
+// selectedAttribute returns the attribute selected in the status, with its
+// value as stored in the database.
+func selectedAttribute(tree *tview.TreeView, status *tview.Table) (field, value string, ok bool) {
+	tn := tree.GetCurrentNode()
+	if tn == nil {
+		return "", "", false
+	}
+	row, _ := status.GetSelection()
+	field, ok = status.GetCell(row, 0).GetReference().(string)
+	if !ok {
+		return "", "", false
+	}
+	n, ok := tn.GetReference().(*xapidb.Node)
+	if !ok {
+		return "", "", false
+	}
+	value, ok = n.Attr[field]
+	return field, value, ok
+}
+
+// selectedRef returns the reference held by the attribute selected in the
+// status.
+func selectedRef(tree *tview.TreeView, status *tview.Table) (xapidb.Reference, bool) {
+	field, _, ok := selectedAttribute(tree, status)
+	if !ok {
+		return xapidb.Reference{}, false
+	}
+	n, ok := tree.GetCurrentNode().GetReference().(*xapidb.Node)
+	if !ok {
+		return xapidb.Reference{}, false
+	}
+	return xapidb.FieldRef(schema.Current, n, field)
+}
+
+func copyValue(debugView *tview.TextView, text string) {
+	debugView.Clear()
+	if err := CopyToClipboard(text); err != nil {
+		Logf(debugView, "[red]Failed to copy: %s", err)
+		return
+	}
+	Logf(debugView, "[green]Copied %d characters", len(text))
+}
+
+// isEditingKey returns true for the keys used to type in an input field.
+func isEditingKey(event *tcell.EventKey) bool {
+	switch event.Key() {
+	case tcell.KeyRune, tcell.KeyBackspace, tcell.KeyBackspace2, tcell.KeyDelete,
+		tcell.KeyLeft, tcell.KeyRight, tcell.KeyHome, tcell.KeyEnd:
+		return true
+	}
+	return false
+}
+
+// RefreshFunc gets the attributes of a row again from its source. It is
+// called outside of the UI goroutine and must not change the database.
+type RefreshFunc func(n *xapidb.Node) (map[string]string, error)
+
+// RefreshCurrentRow reloads the row selected in the tree in the background,
+// then updates the database, its label and the status in the UI goroutine.
+func RefreshCurrentRow(
+	app *tview.Application,
+	tree *tview.TreeView,
+	status *tview.Table,
+	debugView *tview.TextView,
+	db *xapidb.DB,
+	refresh RefreshFunc,
+) {
+	tn := tree.GetCurrentNode()
+	if tn == nil {
+		return
+	}
+
+	node, ok := tn.GetReference().(*xapidb.Node)
+
+	debugView.Clear()
+	if !ok || node.Name != "row" {
+		Logf(debugView, "[blue]Only rows can be refreshed")
+		return
+	}
+
+	class, ref := node.Parent.Attr["name"], node.Attr["ref"]
+	Logf(debugView, "[yellow]Refreshing %s...", ref)
+
+	go func() {
+		attrs, err := refresh(node)
+
+		app.QueueUpdateDraw(func() {
+			if err != nil {
+				Logf(debugView, "\n[red]%s", err)
+				return
+			}
+
+			// An event may have deleted the row, or a reload replaced it,
+			// while it was fetched: it must not be added back
+			db.RLock()
+			current := db.RefIndex[ref]
+			db.RUnlock()
+			if current != node {
+				Logf(debugView, "\n[blue]%s changed while it was refreshed", ref)
+				return
+			}
+
+			db.SetRow(class, ref, attrs)
+			tn.SetText(NodeLabel(node))
+			// The selection may have moved while the row was fetched
+			if tree.GetCurrentNode() == tn {
+				UpdateStatus(status, node)
+			}
+			Logf(debugView, "\n[green]Refreshed %s", ref)
+		})
+	}()
+}
@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/rivo/tview"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// Location is a row of the database shown in the tree, with the line
+// selected in the status.
+type Location struct {
+	Ref       string
+	StatusRow int
+}
+
+// History records the jumps between rows (followed references, search
+// hits, ...) to go back and forth like in a browser.
+type History struct {
+	entries []Location
+	// Index of the current location, -1 when empty
+	pos int
+}
+
+// HistorySize is the maximum number of locations kept.
+const HistorySize = 100
+
+func NewHistory() *History {
+	return &History{pos: -1}
+}
+
+// Visit records a jump from one location to another. Locations after the
+// current one are dropped.
+func (h *History) Visit(from, to Location) {
+	h.entries = h.entries[:h.pos+1]
+
+	if from.Ref != "" {
+		if n := len(h.entries); n > 0 && h.entries[n-1].Ref == from.Ref {
+			// Remember where we were in the status
+			h.entries[n-1] = from
+		} else {
+			h.entries = append(h.entries, from)
+		}
+	}
+	if n := len(h.entries); n == 0 || h.entries[n-1].Ref != to.Ref {
+		h.entries = append(h.entries, to)
+	}
+
+	if len(h.entries) > HistorySize {
+		h.entries = h.entries[len(h.entries)-HistorySize:]
+	}
+	h.pos = len(h.entries) - 1
+}
+
+// Back returns the previous location. current is saved to come back to it
+// with Forward.
+func (h *History) Back(current Location) (Location, bool) {
+	if h.pos <= 0 {
+		return Location{}, false
+	}
+	h.save(current)
+	h.pos--
+	return h.entries[h.pos], true
+}
+
+func (h *History) Forward(current Location) (Location, bool) {
+	if h.pos < 0 || h.pos >= len(h.entries)-1 {
+		return Location{}, false
+	}
+	h.save(current)
+	h.pos++
+	return h.entries[h.pos], true
+}
+
+// save updates the current entry if it is still the displayed row.
+func (h *History) save(current Location) {
+	if h.entries[h.pos].Ref == current.Ref {
+		h.entries[h.pos] = current
+	}
+}
+
+// CurrentLocation returns the row selected in the tree.
+func CurrentLocation(tree *tview.TreeView, status *tview.Table) Location {
+	tn := tree.GetCurrentNode()
+	if tn == nil {
+		return Location{}
+	}
+	n, ok := tn.GetReference().(*xapidb.Node)
+	if !ok || n.Name != "row" {
+		return Location{}
+	}
+	row, _ := status.GetSelection()
+	return Location{Ref: n.Attr["ref"], StatusRow: row}
+}
+
+// JumpTo selects the row referenced by ref, updates the status and records
+// the jump in the history. It returns "done" or the reason of the failure
+// like FollowOpaqueRef.
+func JumpTo(
+	app *tview.Application,
+	tree *tview.TreeView,
+	status *tview.Table,
+	db *xapidb.DB,
+	history *History,
+	ref string,
+) string {
+	from := CurrentLocation(tree, status)
+	if result := Restore(app, tree, status, db, Location{Ref: ref}); result != "done" {
+		return result
+	}
+	history.Visit(from, Location{Ref: ref})
+	return "done"
+}
+
+// Restore selects the row of the location in the tree and the line of the
+// status.
+func Restore(
+	app *tview.Application,
+	tree *tview.TreeView,
+	status *tview.Table,
+	db *xapidb.DB,
+	loc Location,
+) string {
+	if result := FollowOpaqueRef(app, tree, db, loc.Ref); result != "done" {
+		return result
+	}
+	UpdateStatus(status, db.RefIndex[loc.Ref])
+	status.Select(loc.StatusRow, 0)
+	return "done"
+}
+
+// MoveInHistory goes back (or forward) and reports it in the debug view.
+func MoveInHistory(
+	app *tview.Application,
+	tree *tview.TreeView,
+	status *tview.Table,
+	debugView *tview.TextView,
+	db *xapidb.DB,
+	history *History,
+	forward bool,
+) {
+	current := CurrentLocation(tree, status)
+
+	var loc Location
+	var ok bool
+	if forward {
+		loc, ok = history.Forward(current)
+	} else {
+		loc, ok = history.Back(current)
+	}
+
+	debugView.Clear()
+	if !ok {
+		Logf(debugView, "[blue]No more history")
+		return
+	}
+	if result := Restore(app, tree, status, db, loc); result != "done" {
+		Logf(debugView, "[red]%s", result)
+		return
+	}
+	Logf(debugView, "[green]%s", LocationLabel(db, loc))
+}
+
+// LocationLabel describes a location by its table and name__label.
+func LocationLabel(db *xapidb.DB, loc Location) string {
+	row, ok := db.RefIndex[loc.Ref]
+	if !ok {
+		return loc.Ref + " (deleted)"
+	}
+
+	label := row.Attr["name__label"]
+	if label == "" {
+		label = row.Attr["uuid"]
+	}
+	if label == "" {
+		label = loc.Ref
+	}
+	return fmt.Sprintf("%s: %s", row.Parent.Attr["name"], xapidb.UnescapeValue(label))
+}
+
+// MakeHistoryList creates the list of the recent locations, the most
+// recent first. ENTER goes to the selected location.
+func MakeHistoryList(
+	app *tview.Application,
+	tree *tview.TreeView,
+	status *tview.Table,
+	debugView *tview.TextView,
+	pages *tview.Pages,
+	db *xapidb.DB,
+	history *History,
+) *tview.List {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle("History")
+
+	list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		pos := len(history.entries) - 1 - index
+		history.save(CurrentLocation(tree, status))
+		history.pos = pos
+
+		pages.SwitchToPage("normal")
+		app.SetFocus(tree)
+
+		debugView.Clear()
+		if result := Restore(app, tree, status, db, history.entries[pos]); result != "done" {
+			Logf(debugView, "[red]%s", result)
+		}
+	})
+
+	return list
+}
+
+// SetHistoryList fills the list with the entries of the history, it is
+// called each time the list is shown.
+func SetHistoryList(list *tview.List, db *xapidb.DB, history *History) {
+	list.Clear()
+	for i := len(history.entries) - 1; i >= 0; i-- {
+		text := LocationLabel(db, history.entries[i])
+		if i == history.pos {
+			text = "> " + text
+		} else {
+			text = "  " + text
+		}
+		list.AddItem(tview.Escape(text), "", 0, nil)
+	}
+	list.SetCurrentItem(len(history.entries) - 1 - history.pos)
+}
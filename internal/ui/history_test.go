@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+)
+
+func loc(ref string, row int) Location {
+	return Location{Ref: ref, StatusRow: row}
+}
+
+// step is an operation on the history: visit goes from current to to,
+// back and forward leave current and should return want.
+type step struct {
+	op      string
+	current Location
+	to      Location
+	want    Location
+	ok      bool
+}
+
+func TestHistory(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "empty",
+			steps: []step{
+				{op: "back", current: loc("a", 0)},
+				{op: "forward", current: loc("a", 0)},
+			},
+		},
+		{
+			name: "back and forth",
+			steps: []step{
+				{op: "visit", current: loc("a", 3), to: loc("b", 0)},
+				{op: "visit", current: loc("b", 5), to: loc("c", 0)},
+				{op: "forward", current: loc("c", 1)},
+				{op: "back", current: loc("c", 2), want: loc("b", 5), ok: true},
+				{op: "back", current: loc("b", 5), want: loc("a", 3), ok: true},
+				{op: "back", current: loc("a", 3)},
+				{op: "forward", current: loc("a", 4), want: loc("b", 5), ok: true},
+				// The line selected in c before going back is kept
+				{op: "forward", current: loc("b", 5), want: loc("c", 2), ok: true},
+				{op: "forward", current: loc("c", 2)},
+			},
+		},
+		{
+			name: "visit drops forward",
+			steps: []step{
+				{op: "visit", current: loc("a", 0), to: loc("b", 0)},
+				{op: "visit", current: loc("b", 0), to: loc("c", 0)},
+				{op: "back", current: loc("c", 0), want: loc("b", 0), ok: true},
+				{op: "visit", current: loc("b", 1), to: loc("d", 0)},
+				{op: "forward", current: loc("d", 0)},
+				{op: "back", current: loc("d", 0), want: loc("b", 1), ok: true},
+				{op: "back", current: loc("b", 1), want: loc("a", 0), ok: true},
+			},
+		},
+		{
+			name: "no duplicates",
+			steps: []step{
+				{op: "visit", current: loc("a", 0), to: loc("b", 0)},
+				// A search hit in the same row
+				{op: "visit", current: loc("b", 2), to: loc("b", 7)},
+				{op: "back", current: loc("b", 7), want: loc("a", 0), ok: true},
+			},
+		},
+		{
+			name: "not from a row",
+			steps: []step{
+				{op: "visit", current: Location{}, to: loc("a", 0)},
+				{op: "visit", current: loc("a", 1), to: loc("b", 0)},
+				{op: "back", current: loc("b", 0), want: loc("a", 1), ok: true},
+				{op: "back", current: loc("a", 1)},
+			},
+		},
+		{
+			name: "moved away",
+			steps: []step{
+				{op: "visit", current: loc("a", 0), to: loc("b", 0)},
+				// The tree was used to select c: b is not updated
+				{op: "back", current: loc("c", 4), want: loc("a", 0), ok: true},
+				{op: "forward", current: loc("a", 0), want: loc("b", 0), ok: true},
+			},
+		},
+	} {
+		h := NewHistory()
+		for i, s := range tt.steps {
+			var got Location
+			var ok bool
+			switch s.op {
+			case "visit":
+				h.Visit(s.current, s.to)
+				continue
+			case "back":
+				got, ok = h.Back(s.current)
+			case "forward":
+				got, ok = h.Forward(s.current)
+			}
+			if got != s.want || ok != s.ok {
+				t.Errorf("%s: step %d %s: got %v, %t, want %v, %t", tt.name, i, s.op, got, ok, s.want, s.ok)
+			}
+		}
+	}
+}
+
+func TestHistorySize(t *testing.T) {
+	h := NewHistory()
+	from := loc("row0", 0)
+	for i := 1; i <= HistorySize+10; i++ {
+		to := loc(fmt.Sprintf("row%d", i), 0)
+		h.Visit(from, to)
+		from = to
+	}
+
+	back := 0
+	current := from
+	for {
+		prev, ok := h.Back(current)
+		if !ok {
+			break
+		}
+		back++
+		current = prev
+	}
+	if back != HistorySize-1 || current.Ref != "row11" {
+		t.Errorf("went back %d times to %s", back, current.Ref)
+	}
+}
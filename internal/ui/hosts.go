@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"example.com/readxapidb/internal/diff"
+	"example.com/readxapidb/internal/theme"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// HostDB is a database fetched from a host of the pool. DB is nil if the
+// fetch or the parsing failed.
+type HostDB struct {
+	Host string
+	DB   *xapidb.DB
+	Err  error
+}
+
+// MakeHostList creates the list used to switch between the databases of the
+// pool. ENTER displays the database of the selected host, 'c' compares it
+// with the first one (the master) and shows the result in the diff page
+// ('d' toggles it).
+func MakeHostList(
+	app *tview.Application,
+	tree *tview.TreeView,
+	status *tview.Table,
+	debugView *tview.TextView,
+	pages *tview.Pages,
+	help *tview.TextView,
+	db *xapidb.DB,
+	hosts []HostDB,
+	history *History,
+) *tview.List {
+	list := tview.NewList().ShowSecondaryText(true)
+	list.SetBorder(true).SetTitle("Pool hosts ('c' compare with master)")
+
+	for _, h := range hosts {
+		var secondary string
+		if h.Err != nil {
+			secondary = theme.Current.Colorize("[red]") + h.Err.Error()
+		} else {
+			secondary = fmt.Sprintf("%d objects", len(h.DB.RefIndex))
+		}
+		list.AddItem(h.Host, secondary, 0, nil)
+	}
+
+	list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		h := hosts[index]
+
+		pages.SwitchToPage("normal")
+		app.SetFocus(tree)
+		debugView.Clear()
+
+		if h.Err != nil {
+			Logf(debugView, "[red]%s: %s", h.Host, h.Err)
+			return
+		}
+
+		db.Replace(h.DB)
+		ResetTree(tree, db)
+		status.Clear()
+		tree.SetTitle("XAPI DB: " + h.Host)
+		Logf(debugView, "[green]Displaying %s", h.Host)
+	})
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyRune || event.Rune() != 'c' {
+			return event
+		}
+
+		master := hosts[0]
+		h := hosts[list.GetCurrentItem()]
+		if master.Err != nil || h.Err != nil {
+			return nil
+		}
+
+		report := diff.Compare(master.DB, h.DB, diff.DefaultOptions())
+		report.AName = master.Host
+		report.BName = h.Host
+		SetDiffPage(app, tree, status, debugView, pages, help, db, report, history)
+		pages.SwitchToPage("diff")
+
+		return nil
+	})
+
+	return list
+}
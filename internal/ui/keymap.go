@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"example.com/readxapidb/internal/theme"
+)
+
+// Action is what a key does, keys are bound to actions by a Keymap.
+type Action string
+
+const (
+	ActionQuit        Action = "quit"
+	ActionSearch      Action = "search"
+	ActionFocusNext   Action = "focus-next"
+	ActionFocusSwitch Action = "focus-switch"
+	ActionFollowRef   Action = "follow-ref"
+	ActionBack        Action = "back"
+	ActionForward     Action = "forward"
+	ActionHistory     Action = "history"
+	ActionDiff        Action = "diff"
+	ActionHosts       Action = "hosts"
+	ActionValidate    Action = "validate"
+	ActionRefresh     Action = "refresh"
+	ActionReload      Action = "reload"
+	ActionValue       Action = "value"
+	ActionCopy        Action = "copy"
+	ActionRaw         Action = "raw"
+	ActionGrid        Action = "grid"
+	ActionSort        Action = "sort"
+	ActionFilter      Action = "filter"
+	ActionColumns     Action = "columns"
+	ActionTheme       Action = "theme"
+	ActionHelp        Action = "help"
+)
+
+// Actions in the order of the help.
+var actions = []struct {
+	action Action
+	keys   []string
+	doc    string
+}{
+	{ActionQuit, []string{"q"}, "Quit"},
+	{ActionSearch, []string{"/"}, "Open or close the search bar"},
+	{ActionFocusNext, []string{"Tab"}, "Focus the next view"},
+	{ActionFocusSwitch, []string{"h", "l"}, "Switch the focus between the tree and the attributes"},
+	{ActionFollowRef, []string{"Enter"}, "Follow the OpaqueRef selected in the attributes"},
+	{ActionBack, []string{"Esc", "Backspace", "["}, "Close the search bar or the current view, or go back in the history"},
+	{ActionForward, []string{"]"}, "Go forward in the history"},
+	{ActionHistory, []string{"H"}, "Show or hide the recent locations"},
+	{ActionDiff, []string{"d"}, "Show or hide the differences (compare and pool modes)"},
+	{ActionHosts, []string{"p"}, "Show or hide the pool hosts (pool mode)"},
+	{ActionValidate, []string{"V"}, "Validate the database against the schema and show or hide the findings"},
+	{ActionRefresh, []string{"r"}, "Refresh the selected row (live mode)"},
+	{ActionReload, []string{"R"}, "Reload the database"},
+	{ActionValue, []string{"v"}, "Show the selected attribute decoded (again for the raw value)"},
+	{ActionCopy, []string{"y"}, "Copy the selected value to the clipboard"},
+	{ActionRaw, []string{"u"}, "Switch between raw and human-friendly values (sizes, dates, generations)"},
+	{ActionGrid, []string{"g"}, "Show the rows of the selected table in a grid"},
+	{ActionSort, []string{"s"}, "Sort the grid by the selected column (again to reverse)"},
+	{ActionFilter, []string{"f"}, "Filter the grid on the selected column"},
+	{ActionColumns, []string{"c"}, "Choose the columns of the grid"},
+	{ActionTheme, []string{"T"}, "Switch to the next theme"},
+	{ActionHelp, []string{"?"}, "Show or hide the key bindings"},
+}
+
+// Keymap gives the keys bound to each action. Keys are written as runes
+// ("q", "?"), tcell key names ("Enter", "Ctrl-F", "F1") or "Space", with an
+// optional "Alt-" prefix.
+type Keymap map[Action][]string
+
+func DefaultKeymap() Keymap {
+	km := Keymap{}
+	for _, a := range actions {
+		km[a.action] = a.keys
+	}
+	return km
+}
+
+// Override replaces the keys of the actions given in overrides (from the
+// configuration). An empty list unbinds the action.
+func (km Keymap) Override(overrides map[string][]string) error {
+	for name, keys := range overrides {
+		action := Action(name)
+		if _, ok := km[action]; !ok {
+			return fmt.Errorf("unknown action %s", name)
+		}
+
+		normalized := make([]string, 0, len(keys))
+		for _, k := range keys {
+			key, err := parseKey(k)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			normalized = append(normalized, key)
+		}
+		km[action] = normalized
+	}
+
+	// A key can only do one thing
+	bound := map[string]Action{}
+	for _, a := range actions {
+		for _, k := range km[a.action] {
+			if other, ok := bound[k]; ok {
+				return fmt.Errorf("key %s is bound to %s and %s", k, other, a.action)
+			}
+			bound[k] = a.action
+		}
+	}
+
+	return nil
+}
+
+// Action returns the action bound to the key of the event.
+func (km Keymap) Action(event *tcell.EventKey) (Action, bool) {
+	name := keyName(event)
+	if name == "" {
+		return "", false
+	}
+	for action, keys := range km {
+		if slices.Contains(keys, name) {
+			return action, true
+		}
+	}
+	return "", false
+}
+
+// Keys returns the keys of the action for display ("h/l").
+func (km Keymap) Keys(action Action) string {
+	return strings.Join(km[action], "/")
+}
+
+// HelpLine returns the help footer for the given actions, unbound actions
+// are skipped.
+func (km Keymap) HelpLine(list ...Action) string {
+	parts := []string{}
+	for _, a := range list {
+		if len(km[a]) > 0 {
+			parts = append(parts, fmt.Sprintf("[yellow]'%s'[white]=%s", km.Keys(a), a))
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// MakeHelpView lists all actions with their keys.
+func MakeHelpView(km Keymap) *tview.Table {
+	hv := tview.NewTable()
+	hv.SetSelectable(true, false).
+		SetFixed(1, 0).
+		SetBorder(true).
+		SetTitle(fmt.Sprintf("Key bindings ('%s' to close)", km.Keys(ActionHelp)))
+
+	SetHelpView(hv, km)
+	return hv
+}
+
+// SetHelpView fills the help view. It is called each time the view is
+// shown so it uses the colors of the current theme.
+func SetHelpView(hv *tview.Table, km Keymap) {
+	hv.Clear()
+	for col, h := range []string{"Action", "Keys", ""} {
+		hv.SetCell(0, col, tview.NewTableCell(h).SetTextColor(theme.Current.Heading).SetSelectable(false))
+	}
+	for i, a := range actions {
+		keys := km.Keys(a.action)
+		if keys == "" {
+			keys = "(unbound)"
+		}
+		hv.SetCell(i+1, 0, tview.NewTableCell(string(a.action)).SetTextColor(theme.Current.Key))
+		hv.SetCell(i+1, 1, tview.NewTableCell(keys).SetTextColor(theme.Current.Value))
+		hv.SetCell(i+1, 2, tview.NewTableCell(a.doc).SetTextColor(theme.Current.Value))
+	}
+}
+
+// keyName returns the name of the key of the event in the Keymap format.
+func keyName(event *tcell.EventKey) string {
+	var name string
+	switch event.Key() {
+	case tcell.KeyRune:
+		name = string(event.Rune())
+		if event.Rune() == ' ' {
+			name = "Space"
+		}
+	case tcell.KeyBackspace2:
+		name = "Backspace"
+	default:
+		name = tcell.KeyNames[event.Key()]
+		if name == "" {
+			return ""
+		}
+	}
+
+	if event.Modifiers()&tcell.ModAlt != 0 {
+		name = "Alt-" + name
+	}
+	return name
+}
+
+// parseKey checks a key of the configuration and returns its canonical
+// name ("ctrl-f" is "Ctrl-F").
+func parseKey(key string) (string, error) {
+	if len([]rune(key)) == 1 {
+		return key, nil
+	}
+
+	alt := ""
+	if len(key) > 4 && strings.EqualFold(key[:4], "alt-") {
+		alt = "Alt-"
+		key = key[4:]
+		if len([]rune(key)) == 1 {
+			return alt + key, nil
+		}
+	}
+
+	for _, name := range tcell.KeyNames {
+		if strings.EqualFold(name, key) {
+			return alt + name, nil
+		}
+	}
+	for _, name := range []string{"Space", "Backspace"} {
+		if strings.EqualFold(name, key) {
+			return alt + name, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown key %q", key)
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestParseKey(t *testing.T) {
+	for _, tt := range []struct {
+		key  string
+		want string
+		err  bool
+	}{
+		{key: "q", want: "q"},
+		{key: "é", want: "é"},
+		{key: "enter", want: "Enter"},
+		{key: "ctrl-f", want: "Ctrl-F"},
+		{key: "F1", want: "F1"},
+		{key: "space", want: "Space"},
+		{key: "BACKSPACE", want: "Backspace"},
+		{key: "alt-x", want: "Alt-x"},
+		{key: "Alt-Enter", want: "Alt-Enter"},
+		{key: "alt-space", want: "Alt-Space"},
+		{key: "alt-", err: true},
+		{key: "", err: true},
+		{key: "ctrl-", err: true},
+		{key: "Hyper-a", err: true},
+	} {
+		got, err := parseKey(tt.key)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("parseKey(%q) = %q, %v", tt.key, got, err)
+		}
+	}
+}
+
+func TestOverride(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		overrides map[string][]string
+		err       string
+		// want are the keys of the actions after the override
+		want map[Action]string
+	}{
+		{
+			name:      "rebind",
+			overrides: map[string][]string{"quit": {"ctrl-q", "alt-q"}, "help": {"f1"}},
+			want:      map[Action]string{ActionQuit: "Ctrl-Q/Alt-q", ActionHelp: "F1", ActionSearch: "/"},
+		},
+		{
+			name:      "unbind",
+			overrides: map[string][]string{"theme": {}},
+			want:      map[Action]string{ActionTheme: ""},
+		},
+		{
+			name:      "swap",
+			overrides: map[string][]string{"quit": {"?"}, "help": {"q"}},
+			want:      map[Action]string{ActionQuit: "?", ActionHelp: "q"},
+		},
+		{
+			name:      "unknown action",
+			overrides: map[string][]string{"explode": {"x"}},
+			err:       "unknown action explode",
+		},
+		{
+			name:      "unknown key",
+			overrides: map[string][]string{"quit": {"ctrl-"}},
+			err:       `quit: unknown key "ctrl-"`,
+		},
+		{
+			name:      "conflict",
+			overrides: map[string][]string{"theme": {"q"}},
+			err:       "key q is bound to quit and theme",
+		},
+	} {
+		km := DefaultKeymap()
+		err := km.Override(tt.overrides)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: got %v, want %s", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for action, want := range tt.want {
+			if got := km.Keys(action); got != want {
+				t.Errorf("%s: %s is bound to %q, want %q", tt.name, action, got, want)
+			}
+		}
+	}
+}
+
+func TestOverrideDefaults(t *testing.T) {
+	// The default keymap is not changed by an override
+	if err := DefaultKeymap().Override(map[string][]string{"quit": {"x"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := DefaultKeymap().Keys(ActionQuit); got != "q" {
+		t.Errorf("default quit key is %s", got)
+	}
+}
+
+func TestAction(t *testing.T) {
+	km := DefaultKeymap()
+	if err := km.Override(map[string][]string{
+		"search": {"ctrl-f", "alt-/"},
+		"value":  {"space"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		event *tcell.EventKey
+		want  Action
+	}{
+		{tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone), ActionQuit},
+		{tcell.NewEventKey(tcell.KeyCtrlF, 0, tcell.ModCtrl), ActionSearch},
+		{tcell.NewEventKey(tcell.KeyRune, '/', tcell.ModAlt), ActionSearch},
+		{tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone), ActionValue},
+		{tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone), ActionBack},
+		{tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), ActionFollowRef},
+		{tcell.NewEventKey(tcell.KeyRune, '/', tcell.ModNone), ""},
+		{tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone), ""},
+	} {
+		got, ok := km.Action(tt.event)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("%s: got %q, %t, want %q", tt.event.Name(), got, ok, tt.want)
+		}
+	}
+}
+
+func TestHelpLine(t *testing.T) {
+	km := DefaultKeymap()
+	km[ActionDiff] = nil
+
+	got := km.HelpLine(ActionQuit, ActionDiff, ActionFocusSwitch)
+	if want := "[yellow]'q'[white]=quit | [yellow]'h/l'[white]=focus-switch"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Every action is documented once
+	seen := []Action{}
+	for _, a := range actions {
+		if slices.Contains(seen, a.action) || strings.TrimSpace(a.doc) == "" {
+			t.Errorf("%s documented twice or without doc", a.action)
+		}
+		seen = append(seen, a.action)
+	}
+}
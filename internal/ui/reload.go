@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// ReloadFunc loads the database again from its source. progress can be
+// called from any goroutine to report what is being done.
+type ReloadFunc func(progress func(msg string)) (*xapidb.DB, error)
+
+// TreeState is what is needed to restore the navigation in a new tree:
+// expanded nodes and the current node, identified by their key.
+type TreeState struct {
+	Expanded map[string]bool
+	Current  string
+}
+
+// nodeKey identifies a node across reloads: rows by their ref and tables by
+// their name.
+func nodeKey(n *xapidb.Node) string {
+	switch n.Name {
+	case "row":
+		return n.Attr["ref"]
+	case "table":
+		return "table:" + n.Attr["name"]
+	}
+	return n.Name
+}
+
+func SaveTreeState(tree *tview.TreeView) TreeState {
+	state := TreeState{Expanded: map[string]bool{}}
+
+	tree.GetRoot().Walk(func(tn, parent *tview.TreeNode) bool {
+		if n, ok := tn.GetReference().(*xapidb.Node); ok && tn.IsExpanded() && len(tn.GetChildren()) > 0 {
+			state.Expanded[nodeKey(n)] = true
+		}
+		return true
+	})
+
+	if tn := tree.GetCurrentNode(); tn != nil {
+		if n, ok := tn.GetReference().(*xapidb.Node); ok {
+			state.Current = nodeKey(n)
+		}
+	}
+
+	return state
+}
+
+// RestoreTreeState expands the nodes of the tree that were expanded and
+// selects the node that was current if they still exist.
+func RestoreTreeState(tree *tview.TreeView, state TreeState) {
+	root := tree.GetRoot()
+
+	var current *tview.TreeNode
+	for _, tableTreeNode := range root.GetChildren() {
+		table, ok := tableTreeNode.GetReference().(*xapidb.Node)
+		if !ok {
+			continue
+		}
+		if nodeKey(table) == state.Current {
+			current = tableTreeNode
+		}
+
+		if !state.Expanded[nodeKey(table)] {
+			continue
+		}
+
+		if len(tableTreeNode.GetChildren()) == 0 {
+			LoadChildren(tableTreeNode, table)
+		}
+		tableTreeNode.SetExpanded(true)
+
+		for _, rowTreeNode := range tableTreeNode.GetChildren() {
+			if row, ok := rowTreeNode.GetReference().(*xapidb.Node); ok && nodeKey(row) == state.Current {
+				current = rowTreeNode
+			}
+		}
+	}
+
+	if current != nil {
+		tree.SetCurrentNode(current)
+	}
+}
+
+var reloading atomic.Bool
+
+// Reload runs reload in the background, then rebuilds the tree keeping the
+// expanded nodes and the selected one.
+func Reload(
+	app *tview.Application,
+	tree *tview.TreeView,
+	status *tview.Table,
+	debugView *tview.TextView,
+	db *xapidb.DB,
+	reload ReloadFunc,
+) {
+	debugView.Clear()
+	if !reloading.CompareAndSwap(false, true) {
+		Logf(debugView, "[blue]Reload already in progress")
+		return
+	}
+	Logf(debugView, "[yellow]Reloading...")
+
+	progress := func(msg string) {
+		app.QueueUpdateDraw(func() {
+			Logf(debugView, "\n%s", msg)
+		})
+	}
+
+	go func() {
+		defer reloading.Store(false)
+
+		start := time.Now()
+		newDB, err := reload(progress)
+
+		app.QueueUpdateDraw(func() {
+			if err != nil {
+				Logf(debugView, "\n[red]Reload failed: %s", err)
+				return
+			}
+
+			state := SaveTreeState(tree)
+			db.Replace(newDB)
+			ResetTree(tree, db)
+			RestoreTreeState(tree, state)
+
+			if tn := tree.GetCurrentNode(); tn != nil {
+				if n, ok := tn.GetReference().(*xapidb.Node); ok {
+					UpdateStatus(status, n)
+				}
+			}
+
+			debugView.Clear()
+			Logf(debugView, "[green]Reloaded %d objects in %s", len(db.RefIndex), time.Since(start).Round(time.Millisecond))
+			LogParseErrors(debugView, db)
+		})
+	}()
+}
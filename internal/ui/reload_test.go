@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+const treeDB = `<?xml version="1.0" encoding="UTF-8"?>
+<database>
+  <table name="SR">
+    <row ref="OpaqueRef:sr1" name__label="local"/>
+  </table>
+  <table name="VM">
+    <row ref="OpaqueRef:vm1" name__label="vm1"/>
+    <row ref="OpaqueRef:vm2" name__label="vm2"/>
+  </table>
+</database>`
+
+// newTree returns the tree of a database read lazily, like a file opened
+// by the viewer.
+func newTree(t *testing.T, data string) *tview.TreeView {
+	t.Helper()
+	db, err := xapidb.OpenXapiDB(bytes.NewReader([]byte(data)), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := tview.NewTreeView()
+	ResetTree(tree, db)
+	return tree
+}
+
+// findKey returns the tree node of the table or row identified by key.
+func findKey(tree *tview.TreeView, key string) *tview.TreeNode {
+	var found *tview.TreeNode
+	tree.GetRoot().Walk(func(tn, parent *tview.TreeNode) bool {
+		if n, ok := tn.GetReference().(*xapidb.Node); ok && nodeKey(n) == key {
+			found = tn
+		}
+		return found == nil
+	})
+	return found
+}
+
+// expand opens the table named name as the tree does when it is selected.
+func expand(t *testing.T, tree *tview.TreeView, name string) {
+	t.Helper()
+	tn := findKey(tree, "table:"+name)
+	if tn == nil {
+		t.Fatalf("no table %s", name)
+	}
+	LoadChildren(tn, tn.GetReference().(*xapidb.Node))
+	tn.SetExpanded(true)
+}
+
+func TestTreeState(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		expand   []string
+		current  string
+		reloaded string
+		// want is the key of the current node after the reload
+		want string
+	}{
+		{
+			name:     "row",
+			expand:   []string{"VM"},
+			current:  "OpaqueRef:vm2",
+			reloaded: treeDB,
+			want:     "OpaqueRef:vm2",
+		},
+		{
+			name:     "table",
+			expand:   []string{"SR", "VM"},
+			current:  "table:VM",
+			reloaded: treeDB,
+			want:     "table:VM",
+		},
+		{
+			name:     "deleted row",
+			expand:   []string{"VM"},
+			current:  "OpaqueRef:vm2",
+			reloaded: strings.Replace(treeDB, `<row ref="OpaqueRef:vm2" name__label="vm2"/>`, "", 1),
+			want:     "table:SR",
+		},
+		{
+			name:    "deleted table",
+			expand:  []string{"SR"},
+			current: "OpaqueRef:sr1",
+			reloaded: strings.Replace(treeDB, `<table name="SR">
+    <row ref="OpaqueRef:sr1" name__label="local"/>
+  </table>`, "", 1),
+			want: "table:VM",
+		},
+	} {
+		tree := newTree(t, treeDB)
+		for _, name := range tt.expand {
+			expand(t, tree, name)
+		}
+		tree.SetCurrentNode(findKey(tree, tt.current))
+
+		state := SaveTreeState(tree)
+		if state.Current != tt.current {
+			t.Errorf("%s: saved current %s, want %s", tt.name, state.Current, tt.current)
+		}
+		for _, name := range tt.expand {
+			if !state.Expanded["table:"+name] {
+				t.Errorf("%s: table %s not saved as expanded", tt.name, name)
+			}
+		}
+
+		reloaded := newTree(t, tt.reloaded)
+		RestoreTreeState(reloaded, state)
+
+		if n, _ := reloaded.GetCurrentNode().GetReference().(*xapidb.Node); n == nil || nodeKey(n) != tt.want {
+			t.Errorf("%s: current %s, want %s", tt.name, reloaded.GetCurrentNode().GetText(), tt.want)
+		}
+		for _, tableTreeNode := range reloaded.GetRoot().GetChildren() {
+			table := tableTreeNode.GetReference().(*xapidb.Node)
+			// Tree nodes are expanded by default, the rows of the other
+			// tables are not loaded
+			want := 0
+			if state.Expanded[nodeKey(table)] {
+				want = table.Len()
+			}
+			if got := len(tableTreeNode.GetChildren()); got != want || !tableTreeNode.IsExpanded() {
+				t.Errorf("%s: %s has %d rows in the tree, want %d", tt.name, nodeKey(table), got, want)
+			}
+		}
+	}
+}
+
+func TestSaveTreeStateCollapsed(t *testing.T) {
+	tree := newTree(t, treeDB)
+	expand(t, tree, "VM")
+	findKey(tree, "table:VM").SetExpanded(false)
+
+	// A table that has been collapsed or never loaded is not expanded
+	// again
+	if state := SaveTreeState(tree); len(state.Expanded) != 1 || !state.Expanded["database"] {
+		t.Errorf("got %v", state.Expanded)
+	}
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"example.com/readxapidb/internal/theme"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// Logf writes a message using the colors of the current theme, the format
+// uses the default color tags ([red] for errors, [green] for success, ...).
+func Logf(w io.Writer, format string, a ...any) {
+	fmt.Fprintf(w, theme.Current.Colorize(format), a...)
+}
+
+// Texts of the help views before colorization, to colorize them again when
+// the theme changes.
+var helpTexts = map[*tview.TextView]string{}
+
+// SetHelp sets the text of a help view, see Logf for the color tags.
+func SetHelp(help *tview.TextView, text string) {
+	helpTexts[help] = text
+	help.SetText(theme.Current.Colorize(text))
+}
+
+// CycleTheme switches to the next theme.
+func CycleTheme(
+	app *tview.Application,
+	root tview.Primitive,
+	tree *tview.TreeView,
+	status *tview.Table,
+	debugView *tview.TextView,
+) {
+	theme.Set(theme.Next())
+	ApplyTheme(app, root)
+	Logf(debugView, "\n[blue]Theme %s", theme.Current.Name)
+
+	if current := tree.GetCurrentNode(); current != nil {
+		if n, ok := current.GetReference().(*xapidb.Node); ok {
+			UpdateStatus(status, n)
+		}
+	}
+}
+
+// ApplyTheme sets the colors of the current theme to root and all the
+// primitives it contains (pages and flex layouts are walked).
+func ApplyTheme(app *tview.Application, root tview.Primitive) {
+	t := theme.Current
+	focus := app.GetFocus()
+
+	var apply func(p tview.Primitive)
+	apply = func(p tview.Primitive) {
+		if box, ok := p.(interface {
+			SetBackgroundColor(tcell.Color) *tview.Box
+			SetBorderColor(tcell.Color) *tview.Box
+			SetTitleColor(tcell.Color) *tview.Box
+		}); ok {
+			box.SetBackgroundColor(t.UI.PrimitiveBackgroundColor)
+			box.SetTitleColor(t.UI.TitleColor)
+			if p == focus {
+				box.SetBorderColor(t.FocusBorder)
+			} else {
+				box.SetBorderColor(t.Border)
+			}
+		}
+
+		switch p := p.(type) {
+		case *tview.Pages:
+			for _, name := range p.GetPageNames(false) {
+				apply(p.GetPage(name))
+			}
+		case *tview.Flex:
+			for i := 0; i < p.GetItemCount(); i++ {
+				apply(p.GetItem(i))
+			}
+		case *tview.TreeView:
+			p.SetGraphicsColor(t.UI.GraphicsColor)
+			if root := p.GetRoot(); root != nil {
+				root.Walk(func(tn, parent *tview.TreeNode) bool {
+					if n, ok := tn.GetReference().(*xapidb.Node); ok {
+						tn.SetColor(NodeColor(n))
+					}
+					tn.SetSelectedTextStyle(t.SelectedStyle())
+					return true
+				})
+			}
+		case *tview.Table:
+			p.SetSelectedStyle(t.SelectedStyle())
+		case *tview.TextView:
+			p.SetTextColor(t.UI.PrimaryTextColor)
+			if text, ok := helpTexts[p]; ok {
+				// The help is drawn on the terminal background
+				p.SetBackgroundColor(tcell.ColorDefault)
+				p.SetText(t.Colorize(text))
+			}
+		case *tview.InputField:
+			p.SetFieldBackgroundColor(t.UI.ContrastBackgroundColor).
+				SetFieldTextColor(t.UI.PrimaryTextColor).
+				SetLabelColor(t.UI.SecondaryTextColor)
+		case *tview.List:
+			p.SetMainTextColor(t.UI.PrimaryTextColor).
+				SetSecondaryTextColor(t.UI.TertiaryTextColor).
+				SetSelectedTextColor(t.SelectedForeground).
+				SetSelectedBackgroundColor(t.SelectedBackground)
+		}
+	}
+
+	apply(root)
+}
@@ -6,16 +6,69 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
+	"example.com/readxapidb/internal/theme"
 	"example.com/readxapidb/internal/xapidb"
 )
 
+// LoadChildren adds the children of n to tn. If the rows of a table cannot
+// all be read, the error is shown in a node that cannot be selected and
+// has no reference.
 func LoadChildren(tn *tview.TreeNode, n *xapidb.Node) {
+	if err := n.Load(); err != nil {
+		tn.AddChild(tview.NewTreeNode(tview.Escape(err.Error())).
+			SetColor(theme.Current.Error).
+			SetSelectable(false))
+	}
 	for _, c := range n.Children {
 		tn.AddChild(MakeTreeNode(c))
 	}
 }
 
 func MakeTreeNode(n *xapidb.Node) *tview.TreeNode {
+	tn := tview.NewTreeNode(NodeLabel(n))
+	tn.SetReference(n) // This maps the tree view with our node
+	tn.SetSelectable(true)
+	tn.SetColor(NodeColor(n))
+	tn.SetSelectedTextStyle(theme.Current.SelectedStyle())
+
+	// Just create the node, we will add children later
+	return tn
+}
+
+func NodeColor(n *xapidb.Node) tcell.Color {
+	switch n.Name {
+	case "database":
+		return theme.Current.Database
+	case "table":
+		return theme.Current.Table
+	case "row":
+		return theme.Current.Row
+	default:
+		return theme.Current.Node
+	}
+}
+
+// FindTreeNode returns the tree node referencing n or nil if it has not
+// been loaded in the tree.
+func FindTreeNode(root *tview.TreeNode, n *xapidb.Node) *tview.TreeNode {
+	var found *tview.TreeNode
+
+	root.Walk(func(tn, parent *tview.TreeNode) bool {
+		if found != nil {
+			return false
+		}
+		if tn.GetReference() == n {
+			found = tn
+			return false
+		}
+		return true
+	})
+
+	return found
+}
+
+// NodeLabel returns the text displayed in the tree for the node.
+func NodeLabel(n *xapidb.Node) string {
 	var label string
 
 	// If there is a name attribute use it
@@ -27,8 +80,8 @@ func MakeTreeNode(n *xapidb.Node) *tview.TreeNode {
 
 	// If there is children print the number so you will know which
 	// node can be unfold
-	if len(n.Children) > 0 {
-		label += fmt.Sprintf(" (%d)", len(n.Children))
+	if n.Len() > 0 {
+		label += fmt.Sprintf(" (%d)", n.Len())
 	}
 
 	// If there is a name__label add it, it not check if there is a ref.
@@ -38,21 +91,34 @@ func MakeTreeNode(n *xapidb.Node) *tview.TreeNode {
 		label += fmt.Sprintf(" [%s]", ref)
 	}
 
-	tn := tview.NewTreeNode(label)
-	tn.SetReference(n) // This maps the tree view with our node
-	tn.SetSelectable(true)
+	return label
+}
 
-	switch n.Name {
-	case "database":
-		tn.SetColor(tcell.ColorRed)
-	case "table":
-		tn.SetColor(tcell.ColorGreen)
-	case "row":
-		tn.SetColor(tcell.ColorBlue)
-	default:
-		tn.SetColor(tcell.ColorWhite)
+// ResetTree rebuilds the tree from the root of the DB. The first table is
+// selected.
+func ResetTree(tree *tview.TreeView, db *xapidb.DB) {
+	rootTree := MakeTreeNode(db.Root)
+	if db.Partial() {
+		// What could not be parsed is missing, make it visible
+		rootTree.SetText(fmt.Sprintf("%s PARTIAL (%d errors)", NodeLabel(db.Root), len(db.ParseErrors)))
+		rootTree.SetColor(theme.Current.Error)
 	}
+	LoadChildren(rootTree, db.Root)
+	rootTree.SetExpanded(true)
 
-	// Just create the node, we will add children later
-	return tn
+	tree.SetRoot(rootTree)
+
+	// Set current node to first child if it exists
+	if len(rootTree.GetChildren()) > 0 {
+		tree.SetCurrentNode(rootTree.GetChildren()[0])
+	} else {
+		tree.SetCurrentNode(rootTree)
+	}
+}
+
+// LogParseErrors writes the errors of a partial database to the debug view.
+func LogParseErrors(debugView *tview.TextView, db *xapidb.DB) {
+	for _, perr := range db.ParseErrors {
+		Logf(debugView, "\n[red]Partial database, skipped: %s", tview.Escape(perr.Error()))
+	}
 }
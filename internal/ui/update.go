@@ -3,11 +3,13 @@ package ui
 import (
 	"fmt"
 	"sort"
-	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
+	"example.com/readxapidb/internal/schema"
+	"example.com/readxapidb/internal/theme"
 	"example.com/readxapidb/internal/xapidb"
 )
 
@@ -27,9 +29,9 @@ func ToggleFocus(app *tview.Application, current *tview.Primitive, items ...tvie
 	for i, item := range items {
 		if boxItem, ok := item.(interface{ SetBorderColor(tcell.Color) *tview.Box }); ok {
 			if i == nextIdx {
-				boxItem.SetBorderColor(tcell.ColorGreen)
+				boxItem.SetBorderColor(theme.Current.FocusBorder)
 			} else {
-				boxItem.SetBorderColor(tcell.ColorWhite)
+				boxItem.SetBorderColor(theme.Current.Border)
 			}
 		}
 	}
@@ -42,16 +44,17 @@ func ToggleFocus(app *tview.Application, current *tview.Primitive, items ...tvie
 
 func UpdateStatus(tv *tview.Table, n *xapidb.Node) {
 	tv.Clear()
+	t := theme.Current
 
 	row := 0
 
 	// Name
-	tv.SetCell(row, 0, tview.NewTableCell("Name").SetTextColor(tcell.ColorYellow))
-	tv.SetCell(row, 1, tview.NewTableCell(n.Name).SetTextColor(tcell.ColorWhite))
+	tv.SetCell(row, 0, tview.NewTableCell("Name").SetTextColor(t.Heading))
+	tv.SetCell(row, 1, tview.NewTableCell(n.Name).SetTextColor(t.Value))
 	row++
 
 	// Attributes
-	tv.SetCell(row, 0, tview.NewTableCell("Attributes").SetTextColor(tcell.ColorYellow))
+	tv.SetCell(row, 0, tview.NewTableCell("Attributes").SetTextColor(t.Heading))
 	row++
 
 	if len(n.Attr) > 0 {
@@ -62,16 +65,24 @@ func UpdateStatus(tv *tview.Table, n *xapidb.Node) {
 		}
 		sort.Strings(keys)
 
+		gen := xapidb.GenerationOf(n)
+
 		for _, k := range keys {
 			v := n.Attr[k]
-			keyCell := tview.NewTableCell("  " + k).SetTextColor(tcell.ColorOrange)
-			valCell := tview.NewTableCell(v).SetTextColor(tcell.ColorWhite)
+			keyCell := tview.NewTableCell("  " + k).SetTextColor(t.Key).SetReference(k)
+			valCell := tview.NewTableCell(v).SetTextColor(t.Value)
+
+			// The reference keeps the raw value when the text is rendered
+			if !RawValues {
+				if text, ok := FormatValue(k, v, gen); ok {
+					valCell.SetText(text).SetReference(v)
+				}
+			}
 
-			// Highlight OpaqueRefs that we will able to follow (WIP)
-			if strings.HasPrefix(v, "OpaqueRef:") {
-				valCell = tview.NewTableCell(v).SetTextColor(tcell.ColorBlue)
+			// Highlight the references that we are able to follow
+			if _, ok := xapidb.FieldRef(schema.Current, n, k); ok {
+				valCell = tview.NewTableCell(v).SetTextColor(t.Ref)
 				valCell.SetReference(v) // Store the raw string to be able to follow the OpaqueRef
-				// TODO: as we are now using SetTextColor to set color using name should be ok
 				valCell.SetSelectable(true)
 			}
 
@@ -83,8 +94,8 @@ func UpdateStatus(tv *tview.Table, n *xapidb.Node) {
 	}
 
 	// Children count
-	tv.SetCell(row, 0, tview.NewTableCell("Children").SetTextColor(tcell.ColorYellow))
-	tv.SetCell(row, 1, tview.NewTableCell(fmt.Sprintf("%d", len(n.Children))))
+	tv.SetCell(row, 0, tview.NewTableCell("Children").SetTextColor(t.Heading))
+	tv.SetCell(row, 1, tview.NewTableCell(fmt.Sprintf("%d", n.Len())).SetTextColor(t.Value))
 	row++
 
 	// Compute path
@@ -99,13 +110,15 @@ func UpdateStatus(tv *tview.Table, n *xapidb.Node) {
 		cur = cur.Parent
 	}
 
-	tv.SetCell(row, 0, tview.NewTableCell("Path").SetTextColor(tcell.ColorYellow))
-	tv.SetCell(row, 1, tview.NewTableCell(path))
+	tv.SetCell(row, 0, tview.NewTableCell("Path").SetTextColor(t.Heading))
+	tv.SetCell(row, 1, tview.NewTableCell(path).SetTextColor(t.Value))
 }
 
 func FollowOpaqueRef(app *tview.Application, tree *tview.TreeView, DB *xapidb.DB, ref string) string {
 	// Find node using the DB ref index
-	target, ok := DB.RefIndex[ref]
+	// The row may be in a table not loaded yet, the tables that fail to
+	// load report it when they are opened
+	target, ok := DB.Lookup(ref)
 	if !ok {
 		return fmt.Sprintf("Failed to find %s in RefIndex", ref)
 	}
@@ -133,7 +146,9 @@ func FollowOpaqueRef(app *tview.Application, tree *tview.TreeView, DB *xapidb.DB
 	}
 
 	// Load rows of the table if not loaded yet
-	LoadChildren(tableTreeNode, table)
+	if len(tableTreeNode.GetChildren()) == 0 {
+		LoadChildren(tableTreeNode, table)
+	}
 	tableTreeNode.SetExpanded(true)
 
 	// Now find the row node inside the table
@@ -149,3 +164,93 @@ func FollowOpaqueRef(app *tview.Application, tree *tview.TreeView, DB *xapidb.DB
 
 	return "failed to find the row node inside the table"
 }
+
+// HighlightDuration is how long modified rows stay highlighted in the tree.
+const HighlightDuration = 2 * time.Second
+
+// ApplyChanges updates the tree and the status after the DB has been
+// modified. It must be called from the UI goroutine (app.QueueUpdateDraw).
+// Changed rows are highlighted during highlightFor, or until ClearHighlights
+// is called if it is 0.
+func ApplyChanges(
+	app *tview.Application,
+	tree *tview.TreeView,
+	status *tview.Table,
+	changes []xapidb.Change,
+	highlightFor time.Duration,
+) {
+	root := tree.GetRoot()
+	current := tree.GetCurrentNode()
+
+	for _, c := range changes {
+		tableTreeNode := FindTreeNode(root, c.Table)
+		if tableTreeNode == nil {
+			// A new table, it is added at the end of the database
+			if c.Table.Parent == root.GetReference() {
+				tableTreeNode = MakeTreeNode(c.Table)
+				root.AddChild(tableTreeNode)
+			}
+			continue
+		}
+		tableTreeNode.SetText(NodeLabel(c.Table))
+
+		rowTreeNode := FindTreeNode(tableTreeNode, c.Row)
+
+		switch c.Op {
+		case xapidb.RowAdded:
+			// Rows are only added if the table has already been loaded,
+			// otherwise they will be loaded with the others.
+			if len(tableTreeNode.GetChildren()) > 0 {
+				rowTreeNode = MakeTreeNode(c.Row)
+				tableTreeNode.AddChild(rowTreeNode)
+				highlight(app, rowTreeNode, c.Row, highlightFor)
+			}
+
+		case xapidb.RowModified:
+			if rowTreeNode != nil {
+				rowTreeNode.SetText(NodeLabel(c.Row))
+				highlight(app, rowTreeNode, c.Row, highlightFor)
+			}
+
+		case xapidb.RowDeleted:
+			if rowTreeNode != nil {
+				tableTreeNode.RemoveChild(rowTreeNode)
+				if current == rowTreeNode {
+					tree.SetCurrentNode(tableTreeNode)
+					current = tableTreeNode
+				}
+			}
+		}
+
+		// Keep the status in sync with the selected node
+		if current != nil && (current == rowTreeNode || current == tableTreeNode) {
+			if n, ok := current.GetReference().(*xapidb.Node); ok {
+				UpdateStatus(status, n)
+			}
+		}
+	}
+}
+
+func highlight(app *tview.Application, tn *tview.TreeNode, n *xapidb.Node, d time.Duration) {
+	tn.SetColor(theme.Current.Highlight)
+
+	if d == 0 {
+		return
+	}
+
+	time.AfterFunc(d, func() {
+		app.QueueUpdateDraw(func() {
+			tn.SetColor(NodeColor(n))
+		})
+	})
+}
+
+// ClearHighlights restores the color of all nodes of the tree.
+func ClearHighlights(tree *tview.TreeView) {
+	tree.GetRoot().Walk(func(tn, parent *tview.TreeNode) bool {
+		if n, ok := tn.GetReference().(*xapidb.Node); ok {
+			tn.SetColor(NodeColor(n))
+		}
+		return true
+	})
+}
@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/rivo/tview"
+
+	"example.com/readxapidb/internal/theme"
+	"example.com/readxapidb/internal/validate"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// SetValidationView fills the table with the findings of the report, one per
+// line. The reference of each line is its finding.
+func SetValidationView(vv *tview.Table, report *validate.Report) {
+	vv.Clear()
+	vv.SetTitle(fmt.Sprintf("Validation: schema %s against %s (%d errors, %d warnings)",
+		orUnknown(report.DBVersion), report.SchemaVersion, report.Errors(), len(report.Findings)-report.Errors()))
+
+	for col, h := range []string{"Severity", "Kind", "Table", "Object", "Field", "Message", "Value"} {
+		vv.SetCell(0, col, tview.NewTableCell(h).SetTextColor(theme.Current.Heading).SetSelectable(false))
+	}
+
+	for i, f := range report.Findings {
+		row := i + 1
+
+		color := theme.Current.Warning
+		if f.Severity == validate.Error {
+			color = theme.Current.Error
+		}
+
+		object := f.Ref
+		if f.Label != "" {
+			object = f.Label
+		}
+
+		vv.SetCell(row, 0, tview.NewTableCell(f.Severity.String()).SetTextColor(color).SetReference(f))
+		vv.SetCell(row, 1, tview.NewTableCell(f.Kind.String()))
+		vv.SetCell(row, 2, tview.NewTableCell(f.Table))
+		vv.SetCell(row, 3, tview.NewTableCell(object))
+		vv.SetCell(row, 4, tview.NewTableCell(f.Field))
+		vv.SetCell(row, 5, tview.NewTableCell(f.Message).SetMaxWidth(60))
+		vv.SetCell(row, 6, tview.NewTableCell(xapidb.UnescapeValue(f.Value)).SetMaxWidth(40))
+	}
+
+	vv.Select(1, 0).ScrollToBeginning()
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+// SelectedValidationCallback is called when a finding is selected. It goes
+// back to the tree and selects the object, or the table for findings about a
+// table.
+func SelectedValidationCallback(
+	app *tview.Application,
+	tree *tview.TreeView,
+	status *tview.Table,
+	debugView *tview.TextView,
+	pages *tview.Pages,
+	db *xapidb.DB,
+	validationView *tview.Table,
+	history *History,
+) func(row, column int) {
+	return func(row, column int) {
+		ref := validationView.GetCell(row, 0).GetReference()
+		if ref == nil {
+			return
+		}
+		f := ref.(validate.Finding)
+
+		pages.SwitchToPage("normal")
+		app.SetFocus(tree)
+
+		debugView.Clear()
+		Logf(debugView, "[yellow]%s[white] %s %s %s: %s\n", f.Severity, f.Table, f.Ref, f.Field, tview.Escape(f.Message))
+
+		if f.Ref == "" {
+			return
+		}
+		if result := JumpTo(app, tree, status, db, history, f.Ref); result != "done" {
+			Logf(debugView, "[red]%s", result)
+		}
+	}
+}
+
+// SetValidationPage creates the "validation" page, filled later with
+// SetValidationView.
+func SetValidationPage(
+	app *tview.Application,
+	tree *tview.TreeView,
+	status *tview.Table,
+	debugView *tview.TextView,
+	pages *tview.Pages,
+	help *tview.TextView,
+	db *xapidb.DB,
+	history *History,
+) {
+	validationView := tview.NewTable()
+	validationView.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0).
+		SetBorder(true)
+	validationView.SetSelectedFunc(SelectedValidationCallback(app, tree, status, debugView, pages, db, validationView, history))
+
+	validationLayout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(validationView, 0, 1, true).
+		AddItem(help, 1, 0, false)
+
+	pages.AddPage("validation", validationLayout, true, false)
+}
+
+// ShowValidation switches to the "validation" page showing the report.
+func ShowValidation(pages *tview.Pages, debugView *tview.TextView, report *validate.Report) {
+	layout, ok := pages.GetPage("validation").(*tview.Flex)
+	if !ok {
+		return
+	}
+	SetValidationView(layout.GetItem(0).(*tview.Table), report)
+	pages.SwitchToPage("validation")
+
+	debugView.Clear()
+	Logf(debugView, "[blue]Validation: %d errors, %d warnings", report.Errors(), len(report.Findings)-report.Errors())
+}
@@ -0,0 +1,262 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"example.com/readxapidb/internal/theme"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// ValueViewer shows a value decoded: sets as lists, maps as key/value tables
+// and nested values as a tree. The raw value is one key away.
+type ValueViewer struct {
+	app       *tview.Application
+	pages     *tview.Pages
+	debugView *tview.TextView
+	keys      Keymap
+
+	layout *tview.Flex
+	inner  *tview.Pages
+	tree   *tview.TreeView
+	table  *tview.Table
+	raw    *tview.TextView
+	help   *tview.TextView
+
+	value   string
+	decoded string
+	// Focused before opening the viewer
+	from tview.Primitive
+}
+
+// NewValueViewer creates the viewer, it is added to pages as "value" and
+// shown with Open.
+func NewValueViewer(
+	app *tview.Application,
+	pages *tview.Pages,
+	debugView *tview.TextView,
+	keys Keymap,
+) *ValueViewer {
+	v := &ValueViewer{
+		app:       app,
+		pages:     pages,
+		debugView: debugView,
+		keys:      keys,
+	}
+
+	v.tree = tview.NewTreeView()
+	v.tree.SetBorder(true)
+	v.tree.SetSelectedFunc(func(tn *tview.TreeNode) {
+		tn.SetExpanded(!tn.IsExpanded())
+	})
+
+	v.table = tview.NewTable()
+	v.table.SetSelectable(true, false).
+		SetFixed(1, 0).
+		SetBorder(true)
+
+	v.raw = tview.NewTextView().SetWrap(true)
+	v.raw.SetBorder(true)
+
+	v.help = tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter)
+
+	v.inner = tview.NewPages().
+		AddPage("tree", v.tree, true, true).
+		AddPage("table", v.table, true, false).
+		AddPage("raw", v.raw, true, false)
+
+	v.layout = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(v.inner, 0, 1, true).
+		AddItem(v.help, 1, 0, false)
+	v.layout.SetInputCapture(v.inputCapture)
+
+	pages.AddPage("value", v.layout, true, false)
+	return v
+}
+
+// Open shows the value of a field as stored in the database (escaped).
+func (v *ValueViewer) Open(field, value string) {
+	v.value = xapidb.UnescapeValue(value)
+
+	SetHelp(v.help, fmt.Sprintf(
+		"[yellow]'%s'[white]=decoded/raw | [yellow]'%s'[white]=copy | [yellow]'Enter'[white]=expand/collapse | [yellow]'%s'[white]=close",
+		v.keys.Keys(ActionValue), v.keys.Keys(ActionCopy), v.keys.Keys(ActionBack)))
+
+	v.raw.SetTitle(field + " (raw)")
+	v.raw.SetText(v.value)
+
+	e, err := parseNested(v.value)
+	switch {
+	case err != nil:
+		// Not a set or a map
+		v.decoded = "raw"
+	case e.IsMap() && len(e.List) > 0 && flat(e):
+		v.decoded = "table"
+		v.setTable(field, e)
+	default:
+		v.decoded = "tree"
+		v.setTree(field, e)
+	}
+
+	v.from = v.app.GetFocus()
+	v.pages.SwitchToPage("value")
+	v.show(v.decoded)
+}
+
+func (v *ValueViewer) show(page string) {
+	v.inner.SwitchToPage(page)
+	front, item := v.inner.GetFrontPage()
+	if front == page {
+		v.app.SetFocus(item)
+	}
+}
+
+// parseNested parses a set or a map. Atoms that are sets or maps themselves
+// are parsed too.
+func parseNested(s string) (xapidb.SExpr, error) {
+	if !strings.HasPrefix(s, "(") {
+		return xapidb.SExpr{}, fmt.Errorf("not a set or a map")
+	}
+
+	e, err := xapidb.ParseSExpr(s)
+	if err != nil {
+		return xapidb.SExpr{}, err
+	}
+
+	var expand func(e xapidb.SExpr) xapidb.SExpr
+	expand = func(e xapidb.SExpr) xapidb.SExpr {
+		if !e.IsList {
+			if nested, err := xapidb.ParseSExpr(e.Atom); err == nil && nested.IsList && len(nested.List) > 0 {
+				return expand(nested)
+			}
+			return e
+		}
+		for i, item := range e.List {
+			e.List[i] = expand(item)
+		}
+		return e
+	}
+
+	return expand(e), nil
+}
+
+// flat returns true if the values of the map are all atoms.
+func flat(e xapidb.SExpr) bool {
+	for _, pair := range e.List {
+		if pair.List[1].IsList {
+			return false
+		}
+	}
+	return true
+}
+
+func (v *ValueViewer) setTable(field string, e xapidb.SExpr) {
+	t := theme.Current
+
+	v.table.Clear()
+	v.table.SetTitle(fmt.Sprintf("%s (map, %d keys)", field, len(e.List)))
+	v.table.SetSelectedStyle(t.SelectedStyle())
+	v.table.SetCell(0, 0, tview.NewTableCell("Key").SetTextColor(t.Heading).SetSelectable(false))
+	v.table.SetCell(0, 1, tview.NewTableCell("Value").SetTextColor(t.Heading).SetSelectable(false))
+
+	for i, pair := range e.List {
+		key, value := pair.List[0].Atom, pair.List[1].Atom
+		v.table.SetCell(i+1, 0, tview.NewTableCell(tview.Escape(key)).SetTextColor(t.Key))
+		v.table.SetCell(i+1, 1, tview.NewTableCell(tview.Escape(value)).SetTextColor(t.Value).SetReference(value))
+	}
+	v.table.Select(1, 0)
+}
+
+func (v *ValueViewer) setTree(field string, e xapidb.SExpr) {
+	root := v.treeNode(field, e)
+	root.SetExpanded(true)
+	v.tree.SetTitle(field)
+	v.tree.SetRoot(root).SetCurrentNode(root)
+}
+
+// treeNode returns the node of e, its reference is the text to copy.
+func (v *ValueViewer) treeNode(label string, e xapidb.SExpr) *tview.TreeNode {
+	t := theme.Current
+
+	if !e.IsList {
+		text := e.Atom
+		if label != "" {
+			text = label + " = " + e.Atom
+		}
+		return tview.NewTreeNode(tview.Escape(text)).
+			SetColor(t.Value).
+			SetSelectedTextStyle(t.SelectedStyle()).
+			SetReference(e.Atom)
+	}
+
+	kind := "set"
+	if e.IsMap() && len(e.List) > 0 {
+		kind = "map"
+	}
+
+	tn := tview.NewTreeNode(tview.Escape(fmt.Sprintf("%s (%s, %d)", label, kind, len(e.List)))).
+		SetColor(t.Key).
+		SetSelectedTextStyle(t.SelectedStyle()).
+		SetReference(e.String())
+
+	for _, item := range e.List {
+		if kind == "map" {
+			tn.AddChild(v.treeNode(item.List[0].Atom, item.List[1]))
+		} else {
+			tn.AddChild(v.treeNode("", item))
+		}
+	}
+
+	// Nested values are collapsed
+	tn.SetExpanded(label == "" || kind == "set")
+	return tn
+}
+
+// selected returns the text to copy: the selected element or the whole raw
+// value.
+func (v *ValueViewer) selected() string {
+	switch front, _ := v.inner.GetFrontPage(); front {
+	case "tree":
+		if tn := v.tree.GetCurrentNode(); tn != nil && tn != v.tree.GetRoot() {
+			return tn.GetReference().(string)
+		}
+	case "table":
+		row, _ := v.table.GetSelection()
+		if value, ok := v.table.GetCell(row, 1).GetReference().(string); ok {
+			return value
+		}
+	}
+	return v.value
+}
+
+func (v *ValueViewer) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	action, ok := v.keys.Action(event)
+	if !ok {
+		return event
+	}
+
+	switch action {
+	case ActionBack:
+		v.pages.SwitchToPage("normal")
+		v.app.SetFocus(v.from)
+		return nil
+
+	case ActionValue:
+		if front, _ := v.inner.GetFrontPage(); front == "raw" {
+			v.show(v.decoded)
+		} else {
+			v.show("raw")
+		}
+		return nil
+
+	case ActionCopy:
+		copyValue(v.debugView, v.selected())
+		return nil
+	}
+
+	return event
+}
@@ -0,0 +1,58 @@
+package ui
+
+import "testing"
+
+func TestParseNested(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  string
+		err   bool
+	}{
+		{value: "()", want: "()"},
+		{value: "('a' 'b')", want: "('a' 'b')"},
+		{value: "(('k1' 'v1') ('k2' 'v2'))", want: "(('k1' 'v1') ('k2' 'v2'))"},
+		// Maps stored as strings in a map
+		{value: `(('k' '((\'x\' \'y\'))'))`, want: "(('k' (('x' 'y'))))"},
+		{value: `('((\'a\' \'((\\\'b\\\' \\\'c\\\'))\'))')`, want: "((('a' (('b' 'c')))))"},
+		// Atoms that only look like lists are kept
+		{value: "(('k' '()'))", want: "(('k' '()'))"},
+		{value: "(('k' '(unbalanced'))", want: `(('k' '(unbalanced'))`},
+		{value: "debian", err: true},
+		{value: "", err: true},
+		{value: "(('k' 'v')", err: true},
+		{value: "('a') ('b')", err: true},
+	} {
+		e, err := parseNested(tt.value)
+		if tt.err {
+			if err == nil {
+				t.Errorf("parseNested(%q) = %s, want an error", tt.value, e)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseNested(%q): %v", tt.value, err)
+		} else if got := e.String(); got != tt.want {
+			t.Errorf("parseNested(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestFlat(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  bool
+	}{
+		{"()", true},
+		{"(('k1' 'v1') ('k2' 'v2'))", true},
+		{"(('k1' 'v1') ('k2' ('a' 'b')))", false},
+		{`(('k1' 'v1') ('k2' '((\'x\' \'y\'))'))`, false},
+	} {
+		e, err := parseNested(tt.value)
+		if err != nil {
+			t.Fatalf("parseNested(%q): %v", tt.value, err)
+		}
+		if got := flat(e); got != tt.want {
+			t.Errorf("flat(%q) = %t, want %t", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package validate
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"example.com/readxapidb/internal/schema"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	}
+	return "unknown"
+}
+
+type Kind int
+
+const (
+	SchemaVersion Kind = iota // the database and the schema versions differ
+	UnknownTable
+	UnknownField
+	MissingField
+	InvalidValue
+	WrongClass // a reference to an object of another class
+	ParseError // a part of the database could not be read
+)
+
+func (k Kind) String() string {
+	switch k {
+	case SchemaVersion:
+		return "schema version"
+	case UnknownTable:
+		return "unknown table"
+	case UnknownField:
+		return "unknown field"
+	case MissingField:
+		return "missing field"
+	case InvalidValue:
+		return "invalid value"
+	case WrongClass:
+		return "wrong class"
+	case ParseError:
+		return "parse error"
+	}
+	return "unknown"
+}
+
+type Finding struct {
+	Severity Severity
+	Kind     Kind
+	Table    string
+	Ref      string // the row, empty for findings about a table
+	Label    string // name__label of the row if any, to help reading reports
+	Field    string
+	Value    string // raw, as stored in the DB
+	Message  string
+}
+
+type Report struct {
+	// Versions as major.minor, DBVersion is empty if the database has no
+	// manifest (live objects)
+	DBVersion     string
+	SchemaVersion string
+	Findings      []Finding
+}
+
+// Errors returns the number of findings that are errors.
+func (r *Report) Errors() int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == Error {
+			n++
+		}
+	}
+	return n
+}
+
+// Validate checks the tables, rows and values of db against the schema. The
+// database is read locked during the validation.
+//
+// Only the version of s is known: a database of another version is checked
+// against it anyway, with a finding saying so. Tables and fields unknown to
+// the schema are errors, unless the database is newer than the schema in
+// which case they are reported as warnings as they may have been added
+// since. Unknown fields are reported once per table.
+//
+// The parts of a database skipped by xapidb.RecoverXapiDB and the tables
+// that cannot be loaded are errors: what they held cannot be validated.
+func Validate(db *xapidb.DB, s *schema.Schema) *Report {
+	r := &Report{SchemaVersion: fmt.Sprintf("%d.%d", s.Major, s.Minor)}
+
+	if err := db.LoadAll(); err != nil {
+		r.add(Finding{Severity: Error, Kind: ParseError, Message: err.Error()})
+	}
+
+	manifest := db.Manifest()
+
+	db.RLock()
+	defer db.RUnlock()
+
+	for _, perr := range db.ParseErrors {
+		// The error gives the table and row, which are not in the database
+		r.add(Finding{Severity: Error, Kind: ParseError, Message: "skipped from " + perr.Error()})
+	}
+
+	unknown := Error
+	if major, minor, ok := version(manifest); ok {
+		r.DBVersion = fmt.Sprintf("%d.%d", major, minor)
+
+		// There is a single schema, the embedded one or the -schema file
+		only := fmt.Sprintf("only the schema %s is known (see -schema)", r.SchemaVersion)
+		switch {
+		case major != s.Major:
+			r.add(Finding{Severity: Error, Kind: SchemaVersion,
+				Message: fmt.Sprintf("the database schema %s is not described by the schema %s, %s", r.DBVersion, r.SchemaVersion, only)})
+		case minor > s.Minor:
+			unknown = Warning
+			r.add(Finding{Severity: Warning, Kind: SchemaVersion,
+				Message: fmt.Sprintf("the database schema %s is newer than the schema %s, %s: unknown tables and fields are warnings", r.DBVersion, r.SchemaVersion, only)})
+		case minor < s.Minor:
+			r.add(Finding{Severity: Warning, Kind: SchemaVersion,
+				Message: fmt.Sprintf("the database schema %s is older than the schema %s, %s", r.DBVersion, r.SchemaVersion, only)})
+		}
+	}
+
+	for _, t := range db.Root.Children {
+		if t.Name != "table" {
+			continue
+		}
+		name := t.Attr["name"]
+
+		class := s.Class(name)
+		if class == nil {
+			r.add(Finding{Severity: unknown, Kind: UnknownTable, Table: name,
+				Message: fmt.Sprintf("no class %s in the schema (%d rows)", name, len(t.Children))})
+			continue
+		}
+
+		r.validateTable(db, s, class, t, unknown)
+	}
+
+	return r
+}
+
+func (r *Report) validateTable(db *xapidb.DB, s *schema.Schema, class *schema.Class, t *xapidb.Node, unknown Severity) {
+	name := t.Attr["name"]
+
+	// Unknown fields are reported once with the first row having them
+	unknownFields := map[string]*Finding{}
+	unknownRows := map[string]int{}
+	unknownOrder := []string{}
+
+	for _, row := range t.Children {
+		ref := row.Attr["ref"]
+		label := xapidb.UnescapeValue(row.Attr["name__label"])
+		finding := func(severity Severity, kind Kind, field, value, message string) Finding {
+			return Finding{Severity: severity, Kind: kind, Table: name, Ref: ref, Label: label,
+				Field: field, Value: value, Message: message}
+		}
+
+		if ref == "" {
+			r.add(finding(Error, MissingField, "ref", "", "the row has no reference"))
+		}
+
+		for _, fname := range sortedFields(class.Fields) {
+			if _, ok := row.Attr[fname]; !ok && !class.Fields[fname].Optional {
+				r.add(finding(Error, MissingField, fname, "", "mandatory field missing"))
+			}
+		}
+
+		for _, fname := range sortedFields(row.Attr) {
+			value := row.Attr[fname]
+
+			f, ok := s.Field(name, fname)
+			if !ok {
+				if _, seen := unknownFields[fname]; !seen {
+					u := finding(unknown, UnknownField, fname, value, "")
+					unknownFields[fname] = &u
+					unknownOrder = append(unknownOrder, fname)
+				}
+				unknownRows[fname]++
+				continue
+			}
+
+			if _, err := xapidb.Decode(f.Type, value); err != nil {
+				r.add(finding(Error, InvalidValue, fname, value, fmt.Sprintf("%s (%s)", err, f.Type)))
+			}
+		}
+
+		// References must point to objects of the class of the field,
+		// dangling references are common (objects not persisted) and
+		// ignored.
+		for _, ref := range xapidb.Refs(s, row) {
+			if ref.Class == "" {
+				continue
+			}
+			target, ok := db.RefIndex[ref.Ref]
+			if !ok || target.Parent == nil {
+				continue
+			}
+			if targetClass := target.Parent.Attr["name"]; !strings.EqualFold(targetClass, ref.Class) {
+				r.add(finding(Error, WrongClass, ref.Field, ref.Ref,
+					fmt.Sprintf("references a %s instead of a %s", targetClass, ref.Class)))
+			}
+		}
+	}
+
+	for _, fname := range unknownOrder {
+		u := unknownFields[fname]
+		u.Message = fmt.Sprintf("not in the schema (%d rows)", unknownRows[fname])
+		r.add(*u)
+	}
+}
+
+func (r *Report) add(f Finding) {
+	r.Findings = append(r.Findings, f)
+}
+
+// version returns the schema version of the manifest.
+func version(manifest map[string]string) (major, minor int, ok bool) {
+	major, errMajor := strconv.Atoi(manifest["schema_major_vsn"])
+	minor, errMinor := strconv.Atoi(manifest["schema_minor_vsn"])
+	return major, minor, errMajor == nil && errMinor == nil
+}
+
+func sortedFields[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Print writes the report in a human readable form.
+func (r *Report) Print(w io.Writer) {
+	db := r.DBVersion
+	if db == "" {
+		db = "unknown"
+	}
+	fmt.Fprintf(w, "Database schema %s, validated against the schema %s\n\n", db, r.SchemaVersion)
+
+	if len(r.Findings) == 0 {
+		fmt.Fprintln(w, "No problem found")
+		return
+	}
+
+	for _, f := range r.Findings {
+		where := f.Table
+		if f.Ref != "" {
+			where += " " + f.Ref
+			if f.Label != "" {
+				where += " (" + f.Label + ")"
+			}
+		}
+		if f.Field != "" {
+			where += ": " + f.Field
+		}
+		if where != "" {
+			where += ": "
+		}
+
+		fmt.Fprintf(w, "%-7s %s%s\n", f.Severity, where, f.Message)
+		if f.Value != "" && f.Kind != UnknownField {
+			fmt.Fprintf(w, "        value: %s\n", xapidb.UnescapeValue(f.Value))
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d error(s), %d warning(s)\n", r.Errors(), len(r.Findings)-r.Errors())
+}
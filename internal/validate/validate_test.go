@@ -0,0 +1,174 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"example.com/readxapidb/internal/schema"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// vmRow is a VM as written by xapi in state.db, the fields of namespaces
+// (memory, VCPUs, actions, PV, HVM) are joined by a double underscore.
+const vmRow = `<row ref="OpaqueRef:3826b59d-7e1c-4c8e-9a4b-2f6f0c7a1d11" __ctime="7950" __mtime="9912"
+ HVM__boot_params="(('firmware'%.'uefi')%.('order'%.'cdn'))" HVM__boot_policy="BIOS%.order" HVM__shadow_multiplier="1."
+ NVRAM="()" PCI_bus="" PV__args="" PV__bootloader="" PV__bootloader_args="" PV__kernel="" PV__legacy_args="" PV__ramdisk=""
+ VBDs="('OpaqueRef:5b0e5a8c-0c52-4d0e-8f5c-1d8b3c6f2a01')" VCPUs__at_startup="2" VCPUs__max="2" VCPUs__params="()"
+ VGPUs="()" VIFs="()" VTPMs="()" VUSBs="()" _ref="OpaqueRef:3826b59d-7e1c-4c8e-9a4b-2f6f0c7a1d11"
+ actions__after_crash="restart" actions__after_reboot="restart" actions__after_shutdown="destroy" actions__after_softreboot="soft_reboot"
+ affinity="OpaqueRef:NULL" allowed_operations="('changing_dynamic_range'%.'migrate_send'%.'pool_migrate'%.'changing_VCPUs_live'%.'suspend'%.'hard_reboot'%.'hard_shutdown'%.'clean_reboot'%.'clean_shutdown'%.'pause'%.'checkpoint'%.'snapshot'%.'export'%.'copy')"
+ appliance="OpaqueRef:NULL" attached_PCIs="()" bios_strings="(('bios-vendor'%.'Xen'))" blobs="()" blocked_operations="()"
+ children="()" consoles="()" crash_dumps="()" current_operations="(('OpaqueRef:9c1f4e2a-3b5d-4c6e-8f70-a1b2c3d4e5f6'%.'clean_shutdown'))"
+ domain_type="hvm" domarch="" domid="12" generation_id="" guest_metrics="OpaqueRef:NULL" ha_always_run="false" ha_restart_priority=""
+ hardware_platform_version="0" has_vendor_device="false" is_a_snapshot="false" is_a_template="false" is_control_domain="false"
+ is_default_template="false" is_snapshot_from_vmpp="false" is_vmss_snapshot="false" last_boot_CPU_flags="()" last_booted_record=""
+ memory__dynamic_max="4294967296" memory__dynamic_min="4294967296" memory__overhead="37748736" memory__static_max="4294967296"
+ memory__static_min="1073741824" memory__target="4294967296" metrics="OpaqueRef:NULL" name__description="" name__label="debian"
+ order="0" other_config="(('base_template_name'%.'Debian%.Bookworm%.12'))" parent="OpaqueRef:NULL" platform="(('acpi'%.'1'))"
+ power_state="Running" protection_policy="OpaqueRef:NULL" recommendations="" reference_label="debian-12" requires_reboot="false"
+ resident_on="OpaqueRef:05b33782-2fde-72f1-7985-8e41da383881" scheduled_to_be_resident_on="OpaqueRef:NULL" shutdown_delay="0"
+ snapshot_info="()" snapshot_metadata="" snapshot_of="OpaqueRef:NULL" snapshot_schedule="OpaqueRef:NULL" snapshot_time="19700101T00:00:00Z"
+ snapshots="()" start_delay="0" suspend_SR="OpaqueRef:NULL" suspend_VDI="OpaqueRef:NULL" tags="()" transportable_snapshot_id=""
+ user_version="1" uuid="2b7f8e4c-1d3a-4f5b-9c6d-7e8f9a0b1c2d" version="0" xenstore_data="()"/>`
+
+func parse(t *testing.T, rows string) *xapidb.DB {
+	t.Helper()
+	db, err := xapidb.ParseXapiDB([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<database>
+  <manifest>
+    <pair key="schema_major_vsn" value="5"/>
+    <pair key="schema_minor_vsn" value="790"/>
+  </manifest>
+  <table name="VM">` + rows + `</table>
+</database>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestValidateVM(t *testing.T) {
+	r := Validate(parse(t, vmRow), schema.Default())
+
+	for _, f := range r.Findings {
+		t.Errorf("%s %s %s: %s", f.Severity, f.Kind, f.Field, f.Message)
+	}
+}
+
+func TestValidateOperations(t *testing.T) {
+	row := strings.Replace(vmRow, `'checkpoint'`, `'fly'`, 1)
+	row = strings.Replace(row, `'clean_shutdown'))"`, `'explode'))"`, 1)
+	r := Validate(parse(t, row), schema.Default())
+
+	invalid := map[string]bool{}
+	for _, f := range r.Findings {
+		if f.Kind == InvalidValue {
+			invalid[f.Field] = true
+		}
+	}
+	for _, field := range []string{"allowed_operations", "current_operations"} {
+		if !invalid[field] {
+			t.Errorf("invalid operation in %s not reported", field)
+		}
+	}
+}
+
+func TestValidateSingleUnderscore(t *testing.T) {
+	// The API names are not the ones of the database
+	row := strings.Replace(vmRow, "memory__static_max=", "memory_static_max=", 1)
+	r := Validate(parse(t, row), schema.Default())
+
+	var missing, unknown bool
+	for _, f := range r.Findings {
+		missing = missing || f.Kind == MissingField && f.Field == "memory__static_max"
+		unknown = unknown || f.Kind == UnknownField && f.Field == "memory_static_max"
+	}
+	if !missing || !unknown {
+		t.Errorf("memory_static_max: missing %t, unknown %t", missing, unknown)
+	}
+}
+
+func TestValidateVersion(t *testing.T) {
+	for _, tt := range []struct {
+		major, minor string
+		severity     Severity
+		message      string
+	}{
+		{"5", "790", 0, ""},
+		{"5", "791", Warning, "the database schema 5.791 is newer than the schema 5.790, only the schema 5.790 is known (see -schema): unknown tables and fields are warnings"},
+		{"5", "700", Warning, "the database schema 5.700 is older than the schema 5.790, only the schema 5.790 is known (see -schema)"},
+		{"6", "0", Error, "the database schema 6.0 is not described by the schema 5.790, only the schema 5.790 is known (see -schema)"},
+	} {
+		db, err := xapidb.ParseXapiDB([]byte(`<database>
+  <manifest>
+    <pair key="schema_major_vsn" value="` + tt.major + `"/>
+    <pair key="schema_minor_vsn" value="` + tt.minor + `"/>
+  </manifest>
+  <table name="VM">` + vmRow + `</table>
+</database>`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := Validate(db, schema.Default())
+
+		var found []Finding
+		for _, f := range r.Findings {
+			if f.Kind == SchemaVersion {
+				found = append(found, f)
+			}
+		}
+		switch {
+		case tt.message == "" && len(found) != 0:
+			t.Errorf("%s.%s: got %+v", tt.major, tt.minor, found)
+		case tt.message != "" && (len(found) != 1 || found[0].Severity != tt.severity || found[0].Message != tt.message):
+			t.Errorf("%s.%s: got %+v, want %s %q", tt.major, tt.minor, found, tt.severity, tt.message)
+		}
+	}
+}
+
+func TestValidateRecovered(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "truncated",
+			data: `<database>
+  <table name="VM">` + vmRow + `
+    <row ref="OpaqueRef:cut" name__label="cut`,
+			want: []string{"skipped from line 22, column 46, table VM, row OpaqueRef:cut: unexpected EOF"},
+		},
+		{
+			name: "garbage",
+			data: `<database>
+  <table name="VM"><row ref="OpaqueRef:bad" name__label="<<<"/>` + vmRow + `</table>
+</database>`,
+			want: []string{"skipped from line 2, column 59, table VM, row OpaqueRef:bad: unescaped < inside quoted string"},
+		},
+	} {
+		db, err := xapidb.RecoverXapiDB([]byte(tt.data))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		r := Validate(db, schema.Default())
+
+		var got []string
+		for _, f := range r.Findings {
+			if f.Kind != ParseError || f.Severity != Error {
+				t.Errorf("%s: %s %s %s: %s", tt.name, f.Severity, f.Kind, f.Field, f.Message)
+				continue
+			}
+			got = append(got, f.Message)
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+
+		var out strings.Builder
+		r.Print(&out)
+		if r.Errors() == 0 || strings.Contains(out.String(), "No problem found") {
+			t.Errorf("%s: the skipped rows are not reported:\n%s", tt.name, out.String())
+		}
+	}
+}
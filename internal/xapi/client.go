@@ -0,0 +1,183 @@
+package xapi
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client talks to XAPI using JSON-RPC (available on /jsonrpc since XenServer 7.x
+// and on all XCP-ng releases).
+type Client struct {
+	url     string
+	http    *http.Client
+	session string
+
+	mu     sync.Mutex
+	nextID int
+}
+
+type request struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+	ID      int    `json:"id"`
+}
+
+type response struct {
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+	ID     int             `json:"id"`
+}
+
+// Error is the error returned by XAPI. Message is the error code (for example
+// SESSION_AUTHENTICATION_FAILED) and Data holds its parameters.
+type Error struct {
+	Code    int      `json:"code"`
+	Message string   `json:"message"`
+	Data    []string `json:"data"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Data) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Message, strings.Join(e.Data, ", "))
+}
+
+// URL returns the JSON-RPC endpoint of the host. The host can be given with a
+// scheme (http://localhost:8080) to reach a stand-in server, otherwise https is
+// used.
+func URL(host string) string {
+	if !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://") {
+		host = "https://" + host
+	}
+	return strings.TrimSuffix(host, "/") + "/jsonrpc"
+}
+
+func NewClient(host string) *Client {
+	return &Client{
+		url: URL(host),
+		http: &http.Client{
+			Timeout: 60 * time.Second,
+			Transport: &http.Transport{
+				// XCP-ng hosts use self-signed certificates, like for the SSH
+				// host key we don't check them.
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		},
+	}
+}
+
+// Login creates a client and opens a session on the host.
+func Login(host, username, password string) (*Client, error) {
+	c := NewClient(host)
+
+	var session string
+	if err := c.Call("session.login_with_password", &session, username, password, "1.0", "readxapidb"); err != nil {
+		return nil, err
+	}
+	c.session = session
+
+	return c, nil
+}
+
+func (c *Client) Logout() error {
+	if c.session == "" {
+		return nil
+	}
+	err := c.Call("session.logout", nil, c.session)
+	c.session = ""
+	return err
+}
+
+// Call runs the method with the given params and decodes the result into
+// result if it is not nil. Numbers are kept as json.Number.
+func (c *Client) Call(method string, result any, params ...any) error {
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	c.mu.Unlock()
+
+	if params == nil {
+		params = []any{}
+	}
+
+	body, err := json.Marshal(request{JSONRPC: "2.0", Method: method, Params: params, ID: id})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected HTTP status %s", method, resp.Status)
+	}
+
+	var r response
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&r); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+
+	if r.Error != nil {
+		return r.Error
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	dec = json.NewDecoder(bytes.NewReader(r.Result))
+	dec.UseNumber()
+	return dec.Decode(result)
+}
+
+// CallSession is like Call but the session reference is passed as first
+// parameter as required by all methods except login ones.
+func (c *Client) CallSession(method string, result any, params ...any) error {
+	return c.Call(method, result, append([]any{c.session}, params...)...)
+}
+
+// Classes returns the name of all classes that can be listed with
+// get_all_records.
+func (c *Client) Classes() ([]string, error) {
+	var methods []string
+	if err := c.Call("system.listMethods", &methods); err != nil {
+		return nil, err
+	}
+
+	classes := []string{}
+	for _, m := range methods {
+		if class, ok := strings.CutSuffix(m, ".get_all_records"); ok {
+			classes = append(classes, class)
+		}
+	}
+
+	return classes, nil
+}
+
+// Record is a XAPI object as returned by get_record.
+type Record = map[string]any
+
+func (c *Client) AllRecords(class string) (map[string]Record, error) {
+	var records map[string]Record
+	err := c.CallSession(class+".get_all_records", &records)
+	return records, err
+}
+
+func (c *Client) Record(class, ref string) (Record, error) {
+	var record Record
+	err := c.CallSession(class+".get_record", &record, ref)
+	return record, err
+}
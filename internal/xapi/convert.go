@@ -0,0 +1,121 @@
+package xapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"example.com/readxapidb/internal/schema"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// In the database, fields that belong to a namespace of the datamodel are
+// stored with a double underscore (name.label -> name__label) while the API
+// flattens them with a single one (name_label). There is no way to guess it
+// from the name alone (PV_drivers_version is not in the PV namespace), the
+// schema has the names of the database.
+var namespaced = struct {
+	sync.Mutex
+	schema *schema.Schema
+	// API names of the namespaced fields of each class to their name in
+	// the database
+	fields map[string]map[string]string
+}{}
+
+// namespacedFields returns the namespaced fields of the class in the
+// current schema.
+func namespacedFields(class string) map[string]string {
+	namespaced.Lock()
+	defer namespaced.Unlock()
+
+	if namespaced.schema != schema.Current {
+		namespaced.schema = schema.Current
+		namespaced.fields = map[string]map[string]string{}
+	}
+	if fields, ok := namespaced.fields[class]; ok {
+		return fields
+	}
+
+	fields := map[string]string{}
+	if c := schema.Current.Class(class); c != nil {
+		for name := range c.Fields {
+			if strings.Contains(name, "__") {
+				fields[APIFieldName(name)] = name
+			}
+		}
+	}
+	namespaced.fields[class] = fields
+	return fields
+}
+
+// DBFieldName returns the name used in the database for an API field of the
+// class.
+func DBFieldName(class, field string) string {
+	if name, ok := namespacedFields(class)[field]; ok {
+		return name
+	}
+	return field
+}
+
+// APIFieldName is the reverse of DBFieldName.
+func APIFieldName(field string) string {
+	return strings.Replace(field, "__", "_", 1)
+}
+
+// RowAttrs converts a record into the attributes of a database row so it
+// can be browsed like a row read from a state.db file.
+func RowAttrs(class, ref string, rec Record) map[string]string {
+	attrs := make(map[string]string, len(rec)+2)
+
+	for k, v := range rec {
+		attrs[DBFieldName(class, k)] = xapidb.EscapeValue(toSExpr(v, false))
+	}
+	attrs["ref"] = ref
+	attrs["_ref"] = ref
+
+	return attrs
+}
+
+// toSExpr converts a JSON value into its database representation. Strings
+// are only quoted when they are inside a set or a map.
+func toSExpr(v any, quoted bool) string {
+	var s string
+
+	switch v := v.(type) {
+	case nil:
+		s = ""
+	case string:
+		s = v
+	case bool:
+		s = fmt.Sprintf("%t", v)
+	case json.Number:
+		s = v.String()
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, toSExpr(item, true))
+		}
+		return "(" + strings.Join(items, " ") + ")"
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		pairs := make([]string, 0, len(v))
+		for _, k := range keys {
+			pairs = append(pairs, "("+xapidb.QuoteSExpr(k)+" "+toSExpr(v[k], true)+")")
+		}
+		return "(" + strings.Join(pairs, " ") + ")"
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	if quoted {
+		return xapidb.QuoteSExpr(s)
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package xapi
+
+import (
+	"encoding/json"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// Event is a record of event.from. Class is in lower case (vm, host, ...).
+type Event struct {
+	ID        json.Number `json:"id"`
+	Class     string      `json:"class"`
+	Operation string      `json:"operation"` // add, mod or del
+	Ref       string      `json:"ref"`
+	Snapshot  Record      `json:"snapshot"`
+}
+
+type EventBatch struct {
+	Events []Event `json:"events"`
+	Token  string  `json:"token"`
+}
+
+// EventTimeout is the number of seconds event.from waits for events before
+// returning an empty batch.
+const EventTimeout = 30.0
+
+// EventFrom returns the events that happened since token. An empty token
+// returns the current state of all objects.
+func (c *Client) EventFrom(classes []string, token string, timeout float64) (*EventBatch, error) {
+	var batch EventBatch
+	err := c.CallSession("event.from", &batch, classes, token, timeout)
+	return &batch, err
+}
+
+// EventToken returns a token that can be passed to Watch to get events that
+// happen after it is called. Get it before loading the objects to not miss
+// events that happen during the load.
+func EventToken(c *Client) (string, error) {
+	// We are not interested by the current state so only ask for a class
+	// that has a single object.
+	batch, err := c.EventFrom([]string{"pool"}, "", 0)
+	if err != nil {
+		return "", err
+	}
+	return batch.Token, nil
+}
+
+// Watch waits for events of all classes that happen after token and calls
+// handle for each batch. It only returns on error.
+func Watch(c *Client, token string, handle func(events []Event)) error {
+	for {
+		batch, err := c.EventFrom([]string{"*"}, token, EventTimeout)
+		if err != nil {
+			return err
+		}
+
+		if len(batch.Events) > 0 {
+			handle(batch.Events)
+		}
+		token = batch.Token
+	}
+}
+
+// ApplyEvents updates the DB with the events and returns the changes.
+func ApplyEvents(db *xapidb.DB, events []Event) []xapidb.Change {
+	changes := []xapidb.Change{}
+
+	for _, e := range events {
+		switch e.Operation {
+		case "add", "mod":
+			if e.Snapshot == nil {
+				continue
+			}
+			// Use the name of the table if it exists as event classes are
+			// in lower case.
+			tableName := e.Class
+			if t := db.Table(e.Class); t != nil {
+				tableName = t.Attr["name"]
+			}
+			changes = append(changes, db.SetRow(tableName, e.Ref, RowAttrs(tableName, e.Ref, e.Snapshot)))
+
+		case "del":
+			if c, ok := db.DeleteRow(e.Ref); ok {
+				changes = append(changes, c)
+			}
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,118 @@
+package xapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+func TestEvents(t *testing.T) {
+	c, server, _ := serve(t, nil)
+
+	token, err := EventToken(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := Load(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := server.SetField(vmRef, "name__label", "debian 13"); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetField(hostRef, "API_version__minor", "22"); err != nil {
+		t.Fatal(err)
+	}
+
+	batch, err := c.EventFrom([]string{"*"}, token, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.Events) != 2 {
+		t.Fatalf("got %d events, want 2", len(batch.Events))
+	}
+	if e := batch.Events[0]; e.Class != "vm" || e.Operation != "mod" || e.Ref != vmRef {
+		t.Errorf("got event %s %s %s", e.Class, e.Operation, e.Ref)
+	}
+
+	changes := ApplyEvents(db, batch.Events)
+	if len(changes) != 2 {
+		t.Fatalf("got %d changes, want 2", len(changes))
+	}
+	for _, c := range changes {
+		if c.Op != xapidb.RowModified {
+			t.Errorf("%s: got %s, want a modification", c.Row.Attr["ref"], c.Op)
+		}
+	}
+
+	if got := db.RefIndex[vmRef].Attr["name__label"]; got != "debian%.13" {
+		t.Errorf("name__label: got %q", got)
+	}
+	host := db.RefIndex[hostRef].Attr
+	if host["API_version__minor"] != "22" || host["API_version__major"] != "2" {
+		t.Errorf("API version: got %s.%s", host["API_version__major"], host["API_version__minor"])
+	}
+	if _, ok := host["API_version_minor"]; ok {
+		t.Error("API_version_minor stored with the API name")
+	}
+
+	// Without new events, event.from returns once the timeout expires
+	batch, err = c.EventFrom([]string{"*"}, batch.Token, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.Events) != 0 {
+		t.Errorf("got %d events, want none", len(batch.Events))
+	}
+}
+
+func TestApplyEvents(t *testing.T) {
+	db, err := xapidb.ParseXapiDB([]byte(testDB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const newVM = "OpaqueRef:new-vm"
+
+	changes := ApplyEvents(db, []Event{
+		{Class: "vm", Operation: "add", Ref: newVM, Snapshot: Record{"name_label": "new", "tags": []any{"a b"}}},
+		{Class: "network", Operation: "add", Ref: "OpaqueRef:net", Snapshot: Record{"MTU": json.Number("1500")}},
+		{Class: "vm", Operation: "mod", Ref: vmRef},
+		{Class: "host", Operation: "del", Ref: hostRef},
+		{Class: "host", Operation: "del", Ref: "OpaqueRef:unknown"},
+	})
+
+	for i, want := range []struct {
+		op    xapidb.ChangeOp
+		table string
+		ref   string
+	}{
+		{xapidb.RowAdded, "VM", newVM},
+		{xapidb.RowAdded, "network", "OpaqueRef:net"},
+		{xapidb.RowDeleted, "host", hostRef},
+	} {
+		if i >= len(changes) {
+			t.Fatalf("got %d changes, want 3", len(changes))
+		}
+		c := changes[i]
+		if c.Op != want.op || c.Table.Attr["name"] != want.table || c.Row.Attr["ref"] != want.ref {
+			t.Errorf("change %d: got %s %s %s", i, c.Op, c.Table.Attr["name"], c.Row.Attr["ref"])
+		}
+	}
+	if len(changes) != 3 {
+		t.Errorf("got %d changes, want 3", len(changes))
+	}
+
+	row := db.RefIndex[newVM]
+	if row == nil || row.Attr["name__label"] != "new" || row.Attr["tags"] != "('a%.b')" {
+		t.Errorf("new VM: got %v", row)
+	}
+	if _, ok := db.RefIndex[hostRef]; ok {
+		t.Error("host not deleted")
+	}
+	// A modification without snapshot is ignored
+	if db.RefIndex[vmRef].Attr["name__label"] != "debian%.12" {
+		t.Error("VM modified")
+	}
+}
@@ -0,0 +1,103 @@
+package xapi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// skippedErrors are the errors of get_all_records for classes that cannot
+// be listed, other errors (SESSION_INVALID, ...) stop the loading.
+var skippedErrors = map[string]bool{
+	"MESSAGE_METHOD_UNKNOWN": true,
+	"MESSAGE_REMOVED":        true,
+	"PERMISSION_DENIED":      true,
+	"RBAC_PERMISSION_DENIED": true,
+}
+
+// Load builds a database from the live objects of the pool. Each class is a
+// table and each record returned by get_all_records is a row, so the result
+// can be browsed exactly like a database read from a file.
+func Load(c *Client) (*xapidb.DB, error) {
+	classes, err := c.Classes()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(classes)
+
+	root := &xapidb.Node{
+		Name:     "database",
+		Attr:     map[string]string{},
+		Children: []*xapidb.Node{},
+	}
+	refIndex := make(map[string]*xapidb.Node)
+
+	for _, class := range classes {
+		records, err := c.AllRecords(class)
+		if err != nil {
+			// Some classes cannot be listed (permissions, deprecated
+			// classes, ...), that should not prevent browsing the others.
+			var xerr *Error
+			if errors.As(err, &xerr) && skippedErrors[xerr.Message] {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get %s records: %w", class, err)
+		}
+
+		table := &xapidb.Node{
+			Name:     "table",
+			Attr:     map[string]string{"name": class},
+			Children: []*xapidb.Node{},
+			Parent:   root,
+		}
+		root.Children = append(root.Children, table)
+
+		refs := make([]string, 0, len(records))
+		for ref := range records {
+			refs = append(refs, ref)
+		}
+		sort.Strings(refs)
+
+		for _, ref := range refs {
+			row := &xapidb.Node{
+				Name:     "row",
+				Attr:     RowAttrs(class, ref, records[ref]),
+				Children: []*xapidb.Node{},
+				Parent:   table,
+			}
+			table.Children = append(table.Children, row)
+			refIndex[ref] = row
+		}
+	}
+
+	return &xapidb.DB{Root: root, RefIndex: refIndex}, nil
+}
+
+// RefreshRow reloads the attributes of a row from the live pool.
+func RefreshRow(c *Client, db *xapidb.DB, row *xapidb.Node) error {
+	attrs, err := FetchRow(c, row)
+	if err != nil {
+		return err
+	}
+	db.SetRow(row.Parent.Attr["name"], row.Attr["ref"], attrs)
+	return nil
+}
+
+// FetchRow gets the attributes of a row from the live pool without changing
+// the database, so that they can be fetched outside of the UI goroutine.
+func FetchRow(c *Client, row *xapidb.Node) (map[string]string, error) {
+	if row.Name != "row" || row.Parent == nil {
+		return nil, fmt.Errorf("%s is not a row", row.Name)
+	}
+
+	class := row.Parent.Attr["name"]
+	ref := row.Attr["ref"]
+
+	rec, err := c.Record(class, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s record %s: %w", class, ref, err)
+	}
+	return RowAttrs(class, ref, rec), nil
+}
@@ -0,0 +1,200 @@
+package xapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+const (
+	vmRef   = "OpaqueRef:3826b59d-7e1c-4c8e-9a4b-2f6f0c7a1d11"
+	hostRef = "OpaqueRef:05b33782-2fde-72f1-7985-8e41da383881"
+)
+
+// testDB has fields of all types, with the namespaced fields stored with a
+// double underscore like in state.db.
+const testDB = `<?xml version="1.0" encoding="UTF-8"?>
+<database>
+  <manifest>
+    <pair key="schema_major_vsn" value="5"/>
+    <pair key="schema_minor_vsn" value="790"/>
+  </manifest>
+  <table name="VM">
+    <row ref="` + vmRef + `" __ctime="7950" __mtime="9912" _ref="` + vmRef + `"
+      HVM__shadow_multiplier="1.5" VCPUs__max="2" actions__after_crash="restart"
+      allowed_operations="('changing_dynamic_range'%.'suspend'%.'clean_shutdown')"
+      is_a_template="false" memory__static_max="4294967296" name__label="debian%.12"
+      other_config="(('base_template_name'%.'Debian%.Bookworm%.12')%.('mac_seed'%.'6c1a'))"
+      resident_on="` + hostRef + `" snapshot_time="20250331T15:00:19Z" tags="()"
+      unknown_field="kept%.as%.is" uuid="2b7f8e4c-1d3a-4f5b-9c6d-7e8f9a0b1c2d"/>
+  </table>
+  <table name="host">
+    <row ref="` + hostRef + `" __ctime="12" __mtime="40" _ref="` + hostRef + `"
+      API_version__major="2" API_version__minor="21" memory__overhead="619634688"
+      name__label="xcp-ng-1" resident_VMs="('` + vmRef + `')" uuid="6b2d3b4e-6d47-4e5e-9fb1-9d0c5a3b8c11"/>
+  </table>
+  <table name="pool">
+    <row ref="OpaqueRef:5e1f1b22-08ad-4cb1-a4f6-2f1c4a8a8b8e" __ctime="1" __mtime="1"
+      _ref="OpaqueRef:5e1f1b22-08ad-4cb1-a4f6-2f1c4a8a8b8e" master="` + hostRef + `" name__label=""/>
+  </table>
+</database>`
+
+// serve starts a stand-in server for testDB and returns a client logged in
+// on it. handler wraps the server if not nil.
+func serve(t *testing.T, handler func(*Server) http.Handler) (*Client, *Server, *xapidb.DB) {
+	t.Helper()
+
+	db, err := xapidb.ParseXapiDB([]byte(testDB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(db)
+
+	var h http.Handler = server
+	if handler != nil {
+		h = handler(server)
+	}
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+
+	c, err := Login(ts.URL, "root", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, server, db
+}
+
+func TestServerTypes(t *testing.T) {
+	c, _, _ := serve(t, nil)
+
+	rec, err := c.Record("VM", vmRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for field, want := range map[string]any{
+		"memory_static_max":     json.Number("4294967296"),
+		"HVM_shadow_multiplier": json.Number("1.5"),
+		"is_a_template":         false,
+		"name_label":            "debian 12",
+		"snapshot_time":         "20250331T15:00:19Z",
+		"unknown_field":         "kept as is",
+	} {
+		if rec[field] != want {
+			t.Errorf("%s: got %#v, want %#v", field, rec[field], want)
+		}
+	}
+
+	if ops, ok := rec["allowed_operations"].([]any); !ok || len(ops) != 3 || ops[2] != "clean_shutdown" {
+		t.Errorf("allowed_operations: got %#v", rec["allowed_operations"])
+	}
+	if tags, ok := rec["tags"].([]any); !ok || len(tags) != 0 {
+		t.Errorf("tags: got %#v", rec["tags"])
+	}
+	if m, ok := rec["other_config"].(map[string]any); !ok || m["base_template_name"] != "Debian Bookworm 12" {
+		t.Errorf("other_config: got %#v", rec["other_config"])
+	}
+}
+
+func TestLoad(t *testing.T) {
+	c, _, file := serve(t, nil)
+
+	db, err := Load(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The rows must be the ones of the file, without the generation counts
+	// that are not in the records
+	for ref, want := range file.RefIndex {
+		row, ok := db.RefIndex[ref]
+		if !ok {
+			t.Errorf("%s: missing row", ref)
+			continue
+		}
+		if got, want := row.Parent.Attr["name"], want.Parent.Attr["name"]; got != want {
+			t.Errorf("%s: in table %s, want %s", ref, got, want)
+		}
+		for field, value := range want.Attr {
+			if field == "__ctime" || field == "__mtime" {
+				continue
+			}
+			if row.Attr[field] != value {
+				t.Errorf("%s %s: got %q, want %q", ref, field, row.Attr[field], value)
+			}
+		}
+		for field := range row.Attr {
+			if _, ok := want.Attr[field]; !ok {
+				t.Errorf("%s: unexpected field %s", ref, field)
+			}
+		}
+	}
+}
+
+// failing returns the error code for the get_all_records calls of class.
+func failing(class, code string) func(*Server) http.Handler {
+	return func(s *Server) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(body), `"`+class+`.get_all_records"`) {
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"jsonrpc": "2.0",
+					"id":      1,
+					"error":   &Error{Message: code},
+				})
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			s.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestLoadSkippedClass(t *testing.T) {
+	c, _, _ := serve(t, failing("host", "PERMISSION_DENIED"))
+
+	db, err := Load(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := db.RefIndex[hostRef]; ok {
+		t.Error("host table loaded")
+	}
+	if _, ok := db.RefIndex[vmRef]; !ok {
+		t.Error("VM table not loaded")
+	}
+}
+
+func TestLoadError(t *testing.T) {
+	c, _, _ := serve(t, failing("host", "SESSION_INVALID"))
+
+	if _, err := Load(c); err == nil || !strings.Contains(err.Error(), "SESSION_INVALID") {
+		t.Errorf("got error %v, want SESSION_INVALID", err)
+	}
+}
+
+func TestRefreshRow(t *testing.T) {
+	c, server, _ := serve(t, nil)
+
+	db, err := Load(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := server.SetField(vmRef, "memory__static_max", "8589934592"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RefreshRow(c, db, db.RefIndex[vmRef]); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := db.RefIndex[vmRef].Attr["memory__static_max"]; got != "8589934592" {
+		t.Errorf("memory__static_max: got %q", got)
+	}
+}
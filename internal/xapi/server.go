@@ -0,0 +1,319 @@
+package xapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/readxapidb/internal/schema"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// Server is a stand-in for XAPI that serves the content of a database over
+// JSON-RPC. It only implements what the viewer needs (login, listing classes,
+// getting records and events) and accepts any credentials. It is useful to
+// try the live mode without a pool.
+type Server struct {
+	db *xapidb.DB
+
+	mu     sync.Mutex
+	events []serverEvent
+	notify chan struct{} // closed when an event is published
+}
+
+type serverEvent struct {
+	id       int
+	class    string
+	op       string
+	ref      string
+	snapshot map[string]any
+}
+
+func NewServer(db *xapidb.DB) *Server {
+	return &Server{db: db, notify: make(chan struct{})}
+}
+
+// SetField modifies a field of a row and publishes the corresponding event.
+func (s *Server) SetField(ref, field, value string) error {
+	s.db.RLock()
+	row, ok := s.db.RefIndex[ref]
+	if !ok {
+		s.db.RUnlock()
+		return fmt.Errorf("no row %s", ref)
+	}
+
+	table := row.Parent.Attr["name"]
+	attrs := make(map[string]string, len(row.Attr))
+	for k, v := range row.Attr {
+		attrs[k] = v
+	}
+	s.db.RUnlock()
+
+	attrs[field] = xapidb.EscapeValue(value)
+	c := s.db.SetRow(table, ref, attrs)
+	s.publish(c, ref, apiRecord(table, attrs))
+
+	return nil
+}
+
+func (s *Server) publish(c xapidb.Change, ref string, snapshot map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, serverEvent{
+		id:       len(s.events) + 1,
+		class:    strings.ToLower(c.Table.Attr["name"]),
+		op:       c.Op.String(),
+		ref:      ref,
+		snapshot: snapshot,
+	})
+
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// eventsFrom returns the events after token, waiting for at most timeout if
+// there is none yet.
+func (s *Server) eventsFrom(classes []string, token int, timeout time.Duration) ([]map[string]any, int) {
+	deadline := time.After(timeout)
+
+	for {
+		s.mu.Lock()
+		events := []map[string]any{}
+		for _, e := range s.events[min(token, len(s.events)):] {
+			if matchClass(classes, e.class) {
+				events = append(events, map[string]any{
+					"id":        e.id,
+					"class":     e.class,
+					"operation": e.op,
+					"ref":       e.ref,
+					"snapshot":  e.snapshot,
+				})
+			}
+		}
+		last := len(s.events)
+		notify := s.notify
+		s.mu.Unlock()
+
+		if len(events) > 0 {
+			return events, last
+		}
+		token = last
+
+		select {
+		case <-notify:
+		case <-deadline:
+			return events, last
+		}
+	}
+}
+
+func matchClass(classes []string, class string) bool {
+	for _, c := range classes {
+		if c == "*" || strings.EqualFold(c, class) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/jsonrpc" || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req struct {
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+		ID     int               `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+
+	result, err := s.call(req.Method, req.Params)
+	if err != nil {
+		resp["error"] = err
+	} else {
+		resp["result"] = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) call(method string, rawParams []json.RawMessage) (any, *Error) {
+	// Most of the parameters we support are strings
+	params := make([]string, len(rawParams))
+	for i, p := range rawParams {
+		_ = json.Unmarshal(p, &params[i])
+	}
+
+	// event.from waits for modifications so it must not hold the DB lock
+	if method == "event.from" {
+		return s.eventFrom(rawParams)
+	}
+
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	switch method {
+	case "session.login_with_password":
+		return "OpaqueRef:stand-in-session", nil
+	case "session.logout":
+		return "", nil
+	case "system.listMethods":
+		methods := []string{}
+		for _, t := range s.db.Root.Children {
+			if t.Name != "table" {
+				continue
+			}
+			methods = append(methods, t.Attr["name"]+".get_all_records", t.Attr["name"]+".get_record")
+		}
+		return methods, nil
+	}
+
+	class, call, ok := strings.Cut(method, ".")
+	if !ok {
+		return nil, &Error{Message: "MESSAGE_METHOD_UNKNOWN", Data: []string{method}}
+	}
+
+	var table *xapidb.Node
+	for _, t := range s.db.Root.Children {
+		if t.Name == "table" && t.Attr["name"] == class {
+			table = t
+			break
+		}
+	}
+	if table == nil {
+		return nil, &Error{Message: "MESSAGE_METHOD_UNKNOWN", Data: []string{method}}
+	}
+
+	switch call {
+	case "get_all_records":
+		records := map[string]map[string]any{}
+		for _, row := range table.Children {
+			records[row.Attr["ref"]] = apiRecord(class, row.Attr)
+		}
+		return records, nil
+
+	case "get_record":
+		if len(params) < 2 {
+			return nil, &Error{Message: "MESSAGE_PARAMETER_COUNT_MISMATCH", Data: []string{method, "2", fmt.Sprint(len(params))}}
+		}
+		row, ok := s.db.RefIndex[params[1]]
+		if !ok || row.Parent != table {
+			return nil, &Error{Message: "HANDLE_INVALID", Data: []string{class, params[1]}}
+		}
+		return apiRecord(class, row.Attr), nil
+	}
+
+	return nil, &Error{Message: "MESSAGE_METHOD_UNKNOWN", Data: []string{method}}
+}
+
+// apiRecord converts the attributes of a row back into a record, with the
+// values typed like XAPI does: numbers, booleans, arrays for sets and
+// objects for maps. Fields not in the schema are returned as strings.
+func apiRecord(class string, attrs map[string]string) map[string]any {
+	rec := map[string]any{}
+	for k, v := range attrs {
+		switch k {
+		case "ref", "_ref", "__ctime", "__mtime":
+			continue
+		}
+		rec[APIFieldName(k)] = apiValue(class, k, v)
+	}
+	return rec
+}
+
+// apiValue returns the JSON value of a field, invalid values are returned as
+// strings.
+func apiValue(class, field, raw string) any {
+	f, ok := schema.Current.Field(class, field)
+	if !ok || f.Type.Kind == schema.KindDateTime {
+		return xapidb.UnescapeValue(raw)
+	}
+	v, err := xapidb.Decode(f.Type, raw)
+	if err != nil {
+		return xapidb.UnescapeValue(raw)
+	}
+	return jsonValue(v)
+}
+
+// jsonValue converts a value returned by xapidb.Decode into a JSON one.
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC().Format("20060102T15:04:05Z")
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = jsonValue(item)
+		}
+		return items
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[k] = jsonValue(item)
+		}
+		return m
+	}
+	return v
+}
+
+func (s *Server) eventFrom(rawParams []json.RawMessage) (any, *Error) {
+	var classes []string
+	var token string
+	var timeout float64
+
+	if len(rawParams) != 4 ||
+		json.Unmarshal(rawParams[1], &classes) != nil ||
+		json.Unmarshal(rawParams[2], &token) != nil ||
+		json.Unmarshal(rawParams[3], &timeout) != nil {
+		return nil, &Error{Message: "MESSAGE_PARAMETER_COUNT_MISMATCH", Data: []string{"event.from", "4", fmt.Sprint(len(rawParams))}}
+	}
+
+	s.mu.Lock()
+	last := len(s.events)
+	s.mu.Unlock()
+
+	// Without token the current state of all objects is returned
+	if token == "" {
+		s.db.RLock()
+		defer s.db.RUnlock()
+
+		events := []map[string]any{}
+		for _, t := range s.db.Root.Children {
+			if t.Name != "table" || !matchClass(classes, t.Attr["name"]) {
+				continue
+			}
+			for _, row := range t.Children {
+				events = append(events, map[string]any{
+					"id":        last,
+					"class":     strings.ToLower(t.Attr["name"]),
+					"operation": "add",
+					"ref":       row.Attr["ref"],
+					"snapshot":  apiRecord(t.Attr["name"], row.Attr),
+				})
+			}
+		}
+		return map[string]any{"events": events, "token": strconv.Itoa(last)}, nil
+	}
+
+	from, err := strconv.Atoi(token)
+	if err != nil {
+		return nil, &Error{Message: "EVENT_FROM_TOKEN_PARSE_FAILURE", Data: []string{token}}
+	}
+
+	events, next := s.eventsFrom(classes, from, time.Duration(timeout*float64(time.Second)))
+
+	return map[string]any{"events": events, "token": strconv.Itoa(next)}, nil
+}
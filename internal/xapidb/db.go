@@ -0,0 +1,266 @@
+package xapidb
+
+import (
+	"maps"
+	"strconv"
+	"strings"
+)
+
+type ChangeOp int
+
+const (
+	RowAdded ChangeOp = iota
+	RowModified
+	RowDeleted
+)
+
+func (op ChangeOp) String() string {
+	switch op {
+	case RowAdded:
+		return "add"
+	case RowModified:
+		return "mod"
+	case RowDeleted:
+		return "del"
+	}
+	return "unknown"
+}
+
+// Change describes a modification of the DB so the views can be updated
+// without rebuilding everything.
+type Change struct {
+	Op    ChangeOp
+	Table *Node
+	Row   *Node
+}
+
+// Table returns the table with the given name. Names are compared without
+// case as XAPI events use lower case class names (vm instead of VM).
+func (db *DB) Table(name string) *Node {
+	db.RLock()
+	defer db.RUnlock()
+
+	return db.table(name)
+}
+
+func (db *DB) table(name string) *Node {
+	for _, t := range db.Root.Children {
+		if t.Name == "table" && strings.EqualFold(t.Attr["name"], name) {
+			return t
+		}
+	}
+	return nil
+}
+
+// RefByUUID returns the reference of the row with the given uuid.
+func (db *DB) RefByUUID(uuid string) (string, bool) {
+	if db.index != nil {
+		return db.index.RefByUUID(uuid)
+	}
+
+	// The rows of tables that cannot be loaded are not found
+	db.LoadAll()
+
+	db.RLock()
+	defer db.RUnlock()
+
+	if uuid == "" {
+		return "", false
+	}
+	for ref, row := range db.RefIndex {
+		if row.Attr["uuid"] == uuid {
+			return ref, true
+		}
+	}
+	return "", false
+}
+
+// SetRow replaces the attributes of the row referenced by ref in the table
+// or adds a new row if it doesn't exist. The table is created if needed.
+func (db *DB) SetRow(tableName, ref string, attrs map[string]string) Change {
+	db.Lock()
+	defer db.Unlock()
+
+	if row, ok := db.RefIndex[ref]; ok {
+		// Replace the map instead of updating it so readers holding the
+		// previous one are not affected.
+		row.Attr = attrs
+		return Change{Op: RowModified, Table: row.Parent, Row: row}
+	}
+
+	table := db.table(tableName)
+	if table == nil {
+		table = &Node{
+			Name:     "table",
+			Attr:     map[string]string{"name": tableName},
+			Children: []*Node{},
+			Parent:   db.Root,
+		}
+		db.Root.Children = append(db.Root.Children, table)
+	}
+
+	row := &Node{
+		Name:     "row",
+		Attr:     attrs,
+		Children: []*Node{},
+		Parent:   table,
+	}
+	table.Children = append(table.Children, row)
+	db.RefIndex[ref] = row
+
+	return Change{Op: RowAdded, Table: table, Row: row}
+}
+
+// DeleteRow removes the row referenced by ref. ok is false if there is no
+// such row.
+func (db *DB) DeleteRow(ref string) (c Change, ok bool) {
+	db.Lock()
+	defer db.Unlock()
+
+	row, ok := db.RefIndex[ref]
+	if !ok {
+		return Change{}, false
+	}
+	delete(db.RefIndex, ref)
+
+	table := row.Parent
+	children := make([]*Node, 0, len(table.Children))
+	for _, c := range table.Children {
+		if c != row {
+			children = append(children, c)
+		}
+	}
+	table.Children = children
+
+	return Change{Op: RowDeleted, Table: table, Row: row}, true
+}
+
+// Replace makes db use the content of other. It allows to switch what is
+// displayed while keeping the same *DB in all callbacks. The file of the
+// previous content is closed, the tables of other not loaded yet are loaded
+// in db.
+func (db *DB) Replace(other *DB) {
+	db.Lock()
+	defer db.Unlock()
+
+	db.close()
+
+	db.Root = other.Root
+	db.RefIndex = other.RefIndex
+	db.ParseErrors = other.ParseErrors
+	db.index = other.index
+	db.src = other.src
+
+	// The lazy tables lock and update the displayed database
+	if other.Root != nil {
+		for _, t := range other.Root.Children {
+			if t.lazy != nil {
+				t.lazy.db = db
+			}
+		}
+	}
+}
+
+// Manifest returns the pairs of the manifest (schema_major_vsn,
+// schema_minor_vsn, generation_count). It is empty for live databases.
+func (db *DB) Manifest() map[string]string {
+	db.RLock()
+	defer db.RUnlock()
+
+	return manifest(db.Root)
+}
+
+func manifest(root *Node) map[string]string {
+	pairs := map[string]string{}
+	for _, n := range root.Children {
+		if n.Name != "manifest" {
+			continue
+		}
+		for _, pair := range n.Children {
+			pairs[pair.Attr["key"]] = pair.Attr["value"]
+		}
+	}
+
+	return pairs
+}
+
+// GenerationCount returns the generation count of the manifest or -1 if it
+// is unknown.
+func (db *DB) GenerationCount() int64 {
+	db.RLock()
+	defer db.RUnlock()
+
+	return GenerationOf(db.Root)
+}
+
+// GenerationOf returns the generation count of the database containing n,
+// or -1 if it is unknown.
+func GenerationOf(n *Node) int64 {
+	for n.Parent != nil {
+		n = n.Parent
+	}
+	gen, err := strconv.ParseInt(manifest(n)["generation_count"], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return gen
+}
+
+// Update makes db match other by adding, modifying and deleting rows, and
+// returns the changes. Unlike Replace the nodes of db that still exist are
+// kept so views built on them stay valid.
+func (db *DB) Update(other *DB) []Change {
+	changes := []Change{}
+
+	other.RLock()
+	defer other.RUnlock()
+
+	seen := map[string]bool{}
+	for _, t := range other.Root.Children {
+		switch t.Name {
+		case "manifest":
+			db.Lock()
+			for _, n := range db.Root.Children {
+				if n.Name == "manifest" {
+					n.Children = t.Children
+					for _, pair := range n.Children {
+						pair.Parent = n
+					}
+				}
+			}
+			db.Unlock()
+
+		case "table":
+			for _, row := range t.Children {
+				ref := row.Attr["ref"]
+				seen[ref] = true
+
+				db.RLock()
+				old, ok := db.RefIndex[ref]
+				same := ok && maps.Equal(old.Attr, row.Attr)
+				db.RUnlock()
+
+				if !same {
+					changes = append(changes, db.SetRow(t.Attr["name"], ref, row.Attr))
+				}
+			}
+		}
+	}
+
+	db.RLock()
+	deleted := []string{}
+	for ref := range db.RefIndex {
+		if !seen[ref] {
+			deleted = append(deleted, ref)
+		}
+	}
+	db.RUnlock()
+
+	for _, ref := range deleted {
+		if c, ok := db.DeleteRow(ref); ok {
+			changes = append(changes, c)
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,144 @@
+package xapidb
+
+import "testing"
+
+const rowsDB = `<?xml version="1.0" encoding="UTF-8"?>
+<database>
+  <table name="VM">
+    <row ref="OpaqueRef:vm1" name__label="vm1"/>
+    <row ref="OpaqueRef:vm2" name__label="vm2"/>
+  </table>
+</database>`
+
+func parseRows(t *testing.T) *DB {
+	t.Helper()
+	db, err := ParseXapiDB([]byte(rowsDB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestSetRow(t *testing.T) {
+	for _, tt := range []struct {
+		table, ref string
+		op         ChangeOp
+		tableName  string
+		rows       int
+	}{
+		{"VM", "OpaqueRef:vm1", RowModified, "VM", 2},
+		{"VM", "OpaqueRef:vm3", RowAdded, "VM", 3},
+		// Event classes are in lower case
+		{"vm", "OpaqueRef:vm3", RowAdded, "VM", 3},
+		{"network", "OpaqueRef:net1", RowAdded, "network", 1},
+	} {
+		db := parseRows(t)
+		old := db.RefIndex[tt.ref]
+
+		attrs := map[string]string{"ref": tt.ref, "name__label": "new"}
+		c := db.SetRow(tt.table, tt.ref, attrs)
+
+		if c.Op != tt.op || c.Table.Attr["name"] != tt.tableName || len(c.Table.Children) != tt.rows {
+			t.Errorf("%s %s: got %s in %s with %d rows", tt.table, tt.ref, c.Op, c.Table.Attr["name"], len(c.Table.Children))
+		}
+		if db.RefIndex[tt.ref] != c.Row || c.Row.Parent != c.Table || c.Row.Attr["name__label"] != "new" {
+			t.Errorf("%s %s: row not indexed or not updated", tt.table, tt.ref)
+		}
+		if old != nil && old != c.Row {
+			t.Errorf("%s %s: modified row replaced", tt.table, tt.ref)
+		}
+	}
+}
+
+func TestSetRowKeepsAttr(t *testing.T) {
+	db := parseRows(t)
+
+	// Readers holding the previous attributes must not see the change
+	attrs := db.RefIndex["OpaqueRef:vm1"].Attr
+	db.SetRow("VM", "OpaqueRef:vm1", map[string]string{"name__label": "new"})
+	if attrs["name__label"] != "vm1" {
+		t.Errorf("previous attributes modified: %v", attrs)
+	}
+}
+
+func TestDeleteRow(t *testing.T) {
+	db := parseRows(t)
+
+	c, ok := db.DeleteRow("OpaqueRef:vm1")
+	if !ok || c.Op != RowDeleted || c.Row.Attr["ref"] != "OpaqueRef:vm1" {
+		t.Fatalf("got %v %v", c, ok)
+	}
+	if _, ok := db.RefIndex["OpaqueRef:vm1"]; ok {
+		t.Error("vm1 still indexed")
+	}
+	if rows := db.Table("VM").Children; len(rows) != 1 || rows[0].Attr["ref"] != "OpaqueRef:vm2" {
+		t.Errorf("VM table: got %d rows", len(rows))
+	}
+
+	if _, ok := db.DeleteRow("OpaqueRef:vm1"); ok {
+		t.Error("vm1 deleted twice")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	db, err := ParseXapiDB([]byte(`<database>
+  <manifest><pair key="generation_count" value="10"/></manifest>
+  <table name="VM">
+    <row ref="OpaqueRef:vm1" name__label="vm1"/>
+    <row ref="OpaqueRef:vm2" name__label="vm2"/>
+    <row ref="OpaqueRef:vm3" name__label="vm3"/>
+  </table>
+</database>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ParseXapiDB([]byte(`<database>
+  <manifest><pair key="generation_count" value="12"/></manifest>
+  <table name="VM">
+    <row ref="OpaqueRef:vm1" name__label="vm1"/>
+    <row ref="OpaqueRef:vm2" name__label="renamed"/>
+  </table>
+  <table name="host">
+    <row ref="OpaqueRef:host1" name__label="host1"/>
+  </table>
+</database>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vm2 := db.RefIndex["OpaqueRef:vm2"]
+	changes := db.Update(other)
+
+	got := map[string]ChangeOp{}
+	for _, c := range changes {
+		got[c.Row.Attr["ref"]] = c.Op
+	}
+	want := map[string]ChangeOp{
+		"OpaqueRef:vm2":   RowModified,
+		"OpaqueRef:vm3":   RowDeleted,
+		"OpaqueRef:host1": RowAdded,
+	}
+	if len(got) != len(want) || len(changes) != len(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for ref, op := range want {
+		if got[ref] != op {
+			t.Errorf("%s: got %s, want %s", ref, got[ref], op)
+		}
+	}
+
+	// The nodes of the rows still there are kept
+	if db.RefIndex["OpaqueRef:vm2"] != vm2 || vm2.Attr["name__label"] != "renamed" {
+		t.Error("vm2 replaced or not updated")
+	}
+	if _, ok := db.RefIndex["OpaqueRef:vm3"]; ok {
+		t.Error("vm3 not deleted")
+	}
+	if gen := GenerationOf(vm2); gen != 12 {
+		t.Errorf("got generation %d, want 12", gen)
+	}
+
+	if changes := db.Update(other); len(changes) != 0 {
+		t.Errorf("second update: got %d changes", len(changes))
+	}
+}
@@ -0,0 +1,483 @@
+package xapidb
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Index locates the tables and the rows of a database file so it can be
+// opened without reading it (OpenIndexed) and references can be looked up
+// without parsing all the tables. It is only valid for the file whose
+// SHA-256 is Hash.
+type Index struct {
+	Hash [sha256.Size]byte
+	Size int64
+	// Root is the name of the root element (database)
+	Root string
+	// Head are the elements of the root other than the tables (manifest),
+	// Tables the content of the tables (their rows)
+	Head   []Section
+	Tables []Section
+	Rows   []IndexedRow
+
+	refs  map[string]int
+	uuids map[string]int
+}
+
+// Section is a part of the file.
+type Section struct {
+	Name   string
+	Offset int64
+	Length int64
+	// Rows is the number of rows of a table
+	Rows int
+}
+
+type IndexedRow struct {
+	Ref   string
+	UUID  string
+	Table int // index in Tables
+	// Offset and Length of the row element in the file
+	Offset int64
+	Length int64
+	// Referrers are the rows having a field referencing this one (indexes
+	// in Rows)
+	Referrers []int
+}
+
+// ErrStaleIndex is returned when an index doesn't match the file.
+var ErrStaleIndex = errors.New("the index doesn't match the file")
+
+// opaqueRefs finds the references in any value (fields, sets and maps).
+var opaqueRefs = regexp.MustCompile(`OpaqueRef:[0-9A-Fa-f-]+`)
+
+// HashFile returns the SHA-256 of a database file, to check an index.
+func HashFile(r io.Reader) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// BuildIndex reads a database file to index its tables and rows.
+func BuildIndex(r io.ReaderAt, size int64) (*Index, error) {
+	hash, err := HashFile(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, err
+	}
+	ix := &Index{Hash: hash, Size: size}
+
+	decoder := xml.NewDecoder(bufio.NewReaderSize(io.NewSectionReader(r, 0, size), 64*1024))
+
+	// Names of the open elements, the current table or head section and
+	// the references held by each row to compute the referrers
+	var stack []string
+	var section *Section
+	var targets [][]string
+
+	for {
+		start := decoder.InputOffset()
+		tok, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line, column := decoder.InputPos()
+			perr := &ParseError{Line: line, Column: column, Offset: decoder.InputOffset(), Err: err}
+			if section != nil && len(stack) > 1 && stack[1] == "table" {
+				perr.Table = section.Name
+			}
+			return nil, perr
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+
+			switch len(stack) {
+			case 1:
+				ix.Root = t.Name.Local
+			case 2:
+				if t.Name.Local == "table" {
+					ix.Tables = append(ix.Tables, Section{Name: attr(t, "name"), Offset: decoder.InputOffset()})
+					section = &ix.Tables[len(ix.Tables)-1]
+				} else {
+					ix.Head = append(ix.Head, Section{Name: t.Name.Local, Offset: start})
+					section = &ix.Head[len(ix.Head)-1]
+				}
+			case 3:
+				if stack[1] == "table" && t.Name.Local == "row" {
+					section.Rows++
+					row := IndexedRow{Ref: attr(t, "ref"), UUID: attr(t, "uuid"), Table: len(ix.Tables) - 1, Offset: start}
+					ix.Rows = append(ix.Rows, row)
+
+					refs := []string{}
+					for _, a := range t.Attr {
+						if a.Name.Local != "ref" && a.Name.Local != "_ref" {
+							refs = append(refs, opaqueRefs.FindAllString(a.Value, -1)...)
+						}
+					}
+					targets = append(targets, refs)
+				}
+			}
+
+		case xml.EndElement:
+			if len(stack) == 0 || stack[len(stack)-1] != t.Name.Local {
+				line, column := decoder.InputPos()
+				return nil, &ParseError{Line: line, Column: column, Offset: start, Err: fmt.Errorf("unexpected </%s>", t.Name.Local)}
+			}
+
+			switch len(stack) {
+			case 2:
+				if t.Name.Local == "table" {
+					section.Length = start - section.Offset
+				} else {
+					section.Length = decoder.InputOffset() - section.Offset
+				}
+				section = nil
+			case 3:
+				if stack[1] == "table" && t.Name.Local == "row" {
+					row := &ix.Rows[len(ix.Rows)-1]
+					row.Length = decoder.InputOffset() - row.Offset
+				}
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	if ix.Root == "" {
+		return nil, errors.New("no database found")
+	}
+	if len(stack) > 0 {
+		line, column := decoder.InputPos()
+		return nil, &ParseError{Line: line, Column: column, Offset: size, Err: ErrTruncated}
+	}
+
+	ix.init()
+	for i, refs := range targets {
+		for _, ref := range refs {
+			if target, ok := ix.refs[ref]; ok && target != i {
+				ix.Rows[target].Referrers = append(ix.Rows[target].Referrers, i)
+			}
+		}
+	}
+
+	return ix, nil
+}
+
+func attr(t xml.StartElement, name string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// init builds the maps of the rows by ref and by uuid.
+func (ix *Index) init() {
+	ix.refs = make(map[string]int, len(ix.Rows))
+	ix.uuids = make(map[string]int, len(ix.Rows))
+	for i, row := range ix.Rows {
+		if row.Ref != "" {
+			ix.refs[row.Ref] = i
+		}
+		if row.UUID != "" {
+			ix.uuids[row.UUID] = i
+		}
+	}
+}
+
+// Row returns the row with the given reference.
+func (ix *Index) Row(ref string) (IndexedRow, bool) {
+	i, ok := ix.refs[ref]
+	if !ok {
+		return IndexedRow{}, false
+	}
+	return ix.Rows[i], true
+}
+
+// RefByUUID returns the reference of the row with the given uuid.
+func (ix *Index) RefByUUID(uuid string) (string, bool) {
+	i, ok := ix.uuids[uuid]
+	if !ok {
+		return "", false
+	}
+	return ix.Rows[i].Ref, true
+}
+
+// Referrers returns the references of the rows referencing ref.
+func (ix *Index) Referrers(ref string) []string {
+	i, ok := ix.refs[ref]
+	if !ok {
+		return nil
+	}
+	refs := make([]string, 0, len(ix.Rows[i].Referrers))
+	for _, r := range ix.Rows[i].Referrers {
+		refs = append(refs, ix.Rows[r].Ref)
+	}
+	return refs
+}
+
+// The index is written as a magic, then integers as uvarints and strings as
+// their length followed by their bytes:
+//
+//	magic hash size root
+//	len(Head) (name offset length)...
+//	len(Tables) (name offset length rows)...
+//	len(Rows) (ref uuid table offset length len(referrers) referrers...)...
+//
+// The refs and uuids of the rows are written as a kind (idString, idUUID or
+// idRef) followed by the string or the 16 bytes of the UUID.
+var indexMagic = []byte("XAPIDBIX1\n")
+
+// WriteTo writes the index in its binary form.
+func (ix *Index) WriteTo(w io.Writer) (int64, error) {
+	buf := append([]byte{}, indexMagic...)
+	buf = append(buf, ix.Hash[:]...)
+	buf = binary.AppendUvarint(buf, uint64(ix.Size))
+	buf = appendString(buf, ix.Root)
+
+	buf = binary.AppendUvarint(buf, uint64(len(ix.Head)))
+	for _, s := range ix.Head {
+		buf = appendString(buf, s.Name)
+		buf = binary.AppendUvarint(buf, uint64(s.Offset))
+		buf = binary.AppendUvarint(buf, uint64(s.Length))
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(ix.Tables)))
+	for _, s := range ix.Tables {
+		buf = appendString(buf, s.Name)
+		buf = binary.AppendUvarint(buf, uint64(s.Offset))
+		buf = binary.AppendUvarint(buf, uint64(s.Length))
+		buf = binary.AppendUvarint(buf, uint64(s.Rows))
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(ix.Rows)))
+	for _, row := range ix.Rows {
+		buf = appendID(buf, row.Ref)
+		buf = appendID(buf, row.UUID)
+		buf = binary.AppendUvarint(buf, uint64(row.Table))
+		buf = binary.AppendUvarint(buf, uint64(row.Offset))
+		buf = binary.AppendUvarint(buf, uint64(row.Length))
+		buf = binary.AppendUvarint(buf, uint64(len(row.Referrers)))
+		for _, r := range row.Referrers {
+			buf = binary.AppendUvarint(buf, uint64(r))
+		}
+	}
+
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+const (
+	idString = iota
+	idUUID
+	idRef
+)
+
+func appendID(buf []byte, s string) []byte {
+	if u, ok := parseUUID(s); ok {
+		return append(append(buf, idUUID), u[:]...)
+	}
+	if u, ok := parseUUID(strings.TrimPrefix(s, "OpaqueRef:")); ok && strings.HasPrefix(s, "OpaqueRef:") {
+		return append(append(buf, idRef), u[:]...)
+	}
+	return appendString(append(buf, idString), s)
+}
+
+// parseUUID returns the bytes of a UUID in the canonical lower case form,
+// the only one written as bytes so it is read back unchanged.
+func parseUUID(s string) (u [16]byte, ok bool) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, false
+	}
+	h := s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	if strings.ToLower(h) != h {
+		return u, false
+	}
+	if _, err := hex.Decode(u[:], []byte(h)); err != nil {
+		return u, false
+	}
+	return u, true
+}
+
+func formatUUID(u []byte) string {
+	h := hex.EncodeToString(u)
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// ReadIndex reads an index written by WriteTo.
+func ReadIndex(r io.Reader) (*Index, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, indexMagic) || len(data) < len(indexMagic)+sha256.Size {
+		return nil, errors.New("not an index")
+	}
+
+	d := &indexDecoder{data: data[len(indexMagic)+sha256.Size:]}
+	ix := &Index{}
+	copy(ix.Hash[:], data[len(indexMagic):])
+	ix.Size = int64(d.uint())
+	ix.Root = d.string()
+
+	ix.Head = make([]Section, d.count())
+	for i := range ix.Head {
+		ix.Head[i] = Section{Name: d.string(), Offset: int64(d.uint()), Length: int64(d.uint())}
+	}
+
+	ix.Tables = make([]Section, d.count())
+	for i := range ix.Tables {
+		ix.Tables[i] = Section{Name: d.string(), Offset: int64(d.uint()), Length: int64(d.uint()), Rows: d.count()}
+	}
+
+	ix.Rows = make([]IndexedRow, d.count())
+	for i := range ix.Rows {
+		row := IndexedRow{Ref: d.id(), UUID: d.id(), Table: int(d.uint()), Offset: int64(d.uint()), Length: int64(d.uint())}
+		row.Referrers = make([]int, d.count())
+		for j := range row.Referrers {
+			// Checked once all the rows are read
+			row.Referrers[j] = int(d.uint())
+		}
+		ix.Rows[i] = row
+	}
+
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid index: %w", d.err)
+	}
+	for _, row := range ix.Rows {
+		if row.Table < 0 || row.Table >= len(ix.Tables) {
+			return nil, errors.New("invalid index: unknown table")
+		}
+		for _, r := range row.Referrers {
+			if r < 0 || r >= len(ix.Rows) {
+				return nil, errors.New("invalid index: unknown referrer")
+			}
+		}
+	}
+
+	ix.init()
+	return ix, nil
+}
+
+// indexDecoder reads the values of an index, the first error is kept and
+// the values read after it are zero.
+type indexDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *indexDecoder) uint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+// count reads a number of elements, it can't be more than the bytes left.
+func (d *indexDecoder) count() int {
+	v := d.uint()
+	if v > uint64(len(d.data)) && d.err == nil {
+		d.err = errors.New("count out of range")
+		return 0
+	}
+	return int(v)
+}
+
+func (d *indexDecoder) id() string {
+	if d.err != nil || len(d.data) == 0 {
+		d.err = cmp.Or(d.err, io.ErrUnexpectedEOF)
+		return ""
+	}
+	kind := d.data[0]
+	d.data = d.data[1:]
+
+	switch kind {
+	case idString:
+		return d.string()
+	case idUUID, idRef:
+		if len(d.data) < 16 {
+			d.err = io.ErrUnexpectedEOF
+			return ""
+		}
+		u := formatUUID(d.data[:16])
+		d.data = d.data[16:]
+		if kind == idRef {
+			return "OpaqueRef:" + u
+		}
+		return u
+	}
+	d.err = fmt.Errorf("unknown id kind %d", kind)
+	return ""
+}
+
+func (d *indexDecoder) string() string {
+	n := d.count()
+	if d.err != nil {
+		return ""
+	}
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	return s
+}
+
+// OpenIndexed opens the database file read from r using its index: only the
+// head (manifest) is parsed, the tables are loaded when they are used like
+// with OpenXapiDB. The index must have been checked against the file, see
+// HashFile. Close closes r.
+func OpenIndexed(r io.ReaderAt, ix *Index) (*DB, error) {
+	db := &DB{RefIndex: map[string]*Node{}, index: ix, src: r}
+	root := &Node{Name: ix.Root, Attr: map[string]string{}, Children: []*Node{}}
+	db.Root = root
+
+	for _, s := range ix.Head {
+		data := make([]byte, s.Length)
+		if _, err := r.ReadAt(data, s.Offset); err != nil {
+			return nil, fmt.Errorf("failed to read the %s: %w", s.Name, err)
+		}
+		p := newParser(db.RefIndex)
+		p.root = root
+		p.stack = []*Node{root}
+		if _, err := p.decode(xml.NewDecoder(bytes.NewReader(data)), false); err != nil {
+			return nil, fmt.Errorf("failed to parse the %s: %w", s.Name, err)
+		}
+	}
+
+	for _, s := range ix.Tables {
+		root.Children = append(root.Children, &Node{
+			Name:     "table",
+			Attr:     map[string]string{"name": s.Name},
+			Children: []*Node{},
+			Parent:   root,
+			lazy:     &lazyRows{db: db, src: r, offset: s.Offset, length: s.Length, count: s.Rows},
+		})
+	}
+
+	return db, nil
+}
@@ -0,0 +1,270 @@
+package xapidb
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// lazyRows is the part of the file holding the rows of a table.
+type lazyRows struct {
+	db     *DB
+	src    io.ReaderAt
+	offset int64
+	length int64
+	count  int
+}
+
+// OpenXapiDB reads the tables of the database from r without their rows.
+// The rows of a table are parsed when it is loaded (Node.Load) so only the
+// tables browsed are kept in memory. The RefIndex only holds the rows of the
+// loaded tables, LoadAll is needed before looking for any reference.
+//
+// r must stay readable while the database is used, Close closes it.
+func OpenXapiDB(r io.ReaderAt, size int64) (*DB, error) {
+	db := &DB{RefIndex: map[string]*Node{}, src: r}
+	p := newParser(db.RefIndex)
+	decoder := xml.NewDecoder(io.NewSectionReader(r, 0, size))
+
+	// table is the table whose rows are skipped, depth counts the elements
+	// opened in it
+	var table *Node
+	depth := 0
+
+	for {
+		start := decoder.InputOffset()
+		tok, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line, column := decoder.InputPos()
+			return nil, p.errorAt(line, column, decoder.InputOffset(), nil, err)
+		}
+
+		if table != nil {
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if depth == 0 && t.Name.Local == "row" {
+					table.lazy.count++
+				}
+				depth++
+			case xml.EndElement:
+				if depth > 0 {
+					depth--
+					continue
+				}
+				table.lazy.length = start - table.lazy.offset
+				table = nil
+				p.stack = p.stack[:len(p.stack)-1]
+			}
+			continue
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := p.newNode(t)
+			if len(p.stack) > 0 {
+				parent := p.stack[len(p.stack)-1]
+				n.Parent = parent
+				parent.Children = append(parent.Children, n)
+			} else if p.root == nil {
+				p.root = n
+			}
+			p.stack = append(p.stack, n)
+
+			if n.Name == "table" {
+				table = n
+				n.lazy = &lazyRows{db: db, src: r, offset: decoder.InputOffset()}
+			}
+
+		case xml.EndElement:
+			if len(p.stack) == 0 {
+				line, column := decoder.InputPos()
+				return nil, p.errorAt(line, column, start, nil, fmt.Errorf("unexpected </%s>", t.Name.Local))
+			}
+			p.stack = p.stack[:len(p.stack)-1]
+		}
+	}
+
+	if p.root == nil {
+		return nil, fmt.Errorf("no database found")
+	}
+	if len(p.stack) > 0 {
+		line, column := decoder.InputPos()
+		return nil, p.errorAt(line, column, decoder.InputOffset(), nil, ErrTruncated)
+	}
+
+	db.Root = p.root
+	return db, nil
+}
+
+// Close closes the file the tables of the database are read from if it is
+// an io.Closer, the tables not loaded yet cannot be loaded anymore. It does
+// nothing for the databases parsed at once.
+func (db *DB) Close() error {
+	db.Lock()
+	defer db.Unlock()
+
+	return db.close()
+}
+
+func (db *DB) close() error {
+	if db.Root != nil {
+		for _, t := range db.Root.Children {
+			if t.lazy != nil {
+				t.lazy.src = nil
+			}
+		}
+	}
+
+	c, ok := db.src.(io.Closer)
+	db.src = nil
+	if !ok {
+		return nil
+	}
+	return c.Close()
+}
+
+// Loaded returns false for a table of a database opened with OpenXapiDB
+// whose rows have not been parsed yet.
+func (n *Node) Loaded() bool {
+	return n.lazy == nil
+}
+
+// Len returns the number of children of the node, including the rows not
+// loaded yet.
+func (n *Node) Len() int {
+	if n.lazy != nil {
+		return n.lazy.count
+	}
+	return len(n.Children)
+}
+
+// Load parses the rows of a table not loaded yet, it does nothing for the
+// other nodes.
+func (n *Node) Load() error {
+	if n.lazy == nil {
+		return nil
+	}
+	db := n.lazy.db
+	db.Lock()
+	defer db.Unlock()
+
+	return n.load()
+}
+
+// LoadAll parses the rows of all the tables not loaded yet. It returns the
+// first error, the other tables are loaded anyway.
+func (db *DB) LoadAll() error {
+	db.Lock()
+	defer db.Unlock()
+
+	var first error
+	for _, t := range db.Root.Children {
+		if err := t.load(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// load parses the rows of the table, the database must be locked. The rows
+// are not loaded again after an error, the ones parsed before it are kept.
+func (n *Node) load() error {
+	lazy := n.lazy
+	if lazy == nil {
+		return nil
+	}
+	n.lazy = nil
+
+	if lazy.src == nil {
+		return fmt.Errorf("failed to read the rows of %s: %w", n.Attr["name"], os.ErrClosed)
+	}
+	data := make([]byte, lazy.length)
+	if _, err := lazy.src.ReadAt(data, lazy.offset); err != nil {
+		return fmt.Errorf("failed to read the rows of %s: %w", n.Attr["name"], err)
+	}
+
+	p := newParser(lazy.db.RefIndex)
+	p.root = lazy.db.Root
+	p.stack = []*Node{n}
+	if _, err := p.decode(xml.NewDecoder(bytes.NewReader(data)), false); err != nil {
+		return fmt.Errorf("failed to parse the rows of %s: %w", n.Attr["name"], err)
+	}
+	return nil
+}
+
+// Lookup returns the row with the given reference, loading its table if
+// needed. With an index only this table is loaded, otherwise all of them.
+func (db *DB) Lookup(ref string) (*Node, bool) {
+	db.RLock()
+	n, ok := db.RefIndex[ref]
+	index := db.index
+	db.RUnlock()
+	if ok {
+		return n, true
+	}
+
+	if index == nil {
+		// The rows of tables that cannot be loaded are not found
+		db.LoadAll()
+	} else if row, ok := index.Row(ref); ok {
+		db.Lock()
+		if t := db.indexedTable(row.Table); t != nil {
+			t.load()
+		}
+		db.Unlock()
+	}
+
+	db.RLock()
+	defer db.RUnlock()
+	n, ok = db.RefIndex[ref]
+	return n, ok
+}
+
+// indexedTable returns the table at position i in the index.
+func (db *DB) indexedTable(i int) *Node {
+	for _, t := range db.Root.Children {
+		if t.Name != "table" {
+			continue
+		}
+		if i == 0 {
+			return t
+		}
+		i--
+	}
+	return nil
+}
+
+// Referrers returns the references of the other rows having a field
+// referencing ref, from the index if there is one.
+func (db *DB) Referrers(ref string) []string {
+	if db.index != nil {
+		return db.index.Referrers(ref)
+	}
+
+	db.LoadAll()
+
+	db.RLock()
+	defer db.RUnlock()
+
+	refs := []string{}
+	for _, t := range db.Root.Children {
+		for _, row := range t.Children {
+			if row.Attr["ref"] == ref {
+				continue
+			}
+			for k, v := range row.Attr {
+				if k != "ref" && k != "_ref" && strings.Contains(v, ref) {
+					refs = append(refs, row.Attr["ref"])
+					break
+				}
+			}
+		}
+	}
+	return refs
+}
@@ -0,0 +1,105 @@
+package xapidb
+
+import (
+	"bytes"
+	"testing"
+)
+
+const lazyDB = `<?xml version="1.0" encoding="UTF-8"?>
+<database>
+  <manifest>
+    <pair key="schema_major_vsn" value="5"/>
+    <pair key="schema_minor_vsn" value="790"/>
+  </manifest>
+  <table name="VM">
+    <row ref="OpaqueRef:vm1" name__label="vm1"/>
+    <row ref="OpaqueRef:vm2" name__label="vm2"/>
+  </table>
+  <table name="host">
+    <row ref="OpaqueRef:host1" name__label="host1"/>
+  </table>
+</database>`
+
+// file is a database file that counts how many times it is closed.
+type file struct {
+	*bytes.Reader
+	closed int
+}
+
+func (f *file) Close() error {
+	f.closed++
+	return nil
+}
+
+func openLazy(t *testing.T) (*DB, *file) {
+	t.Helper()
+	f := &file{Reader: bytes.NewReader([]byte(lazyDB))}
+	db, err := OpenXapiDB(f, f.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, f
+}
+
+func TestLazyLoad(t *testing.T) {
+	db, f := openLazy(t)
+
+	vm := db.Table("VM")
+	if vm.Loaded() || vm.Len() != 2 || len(db.RefIndex) != 0 {
+		t.Fatalf("VM table: loaded %t, %d rows, %d refs", vm.Loaded(), vm.Len(), len(db.RefIndex))
+	}
+
+	if n, ok := db.Lookup("OpaqueRef:host1"); !ok || n.Attr["name__label"] != "host1" {
+		t.Error("host1 not found")
+	}
+	if !vm.Loaded() || len(vm.Children) != 2 {
+		t.Errorf("VM table: loaded %t, %d rows", vm.Loaded(), len(vm.Children))
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil || f.closed != 1 {
+		t.Errorf("file closed %d times", f.closed)
+	}
+}
+
+func TestLazyClosed(t *testing.T) {
+	db, _ := openLazy(t)
+
+	db.Close()
+	if err := db.Table("VM").Load(); err == nil {
+		t.Error("table loaded from a closed file")
+	}
+}
+
+func TestLazyReplace(t *testing.T) {
+	db, old := openLazy(t)
+	other, f := openLazy(t)
+
+	db.Replace(other)
+	if old.closed != 1 {
+		t.Errorf("previous file closed %d times", old.closed)
+	}
+	if f.closed != 0 {
+		t.Error("new file closed")
+	}
+
+	// The tables not loaded yet lock and fill the displayed database
+	for _, table := range db.Root.Children {
+		if table.lazy != nil && table.lazy.db != db {
+			t.Errorf("table %s loads in the replaced database", table.Attr["name"])
+		}
+	}
+	if err := db.Table("VM").Load(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := db.RefIndex["OpaqueRef:vm1"]; !ok {
+		t.Error("vm1 not in the database")
+	}
+
+	db.Close()
+	if f.closed != 1 {
+		t.Errorf("new file closed %d times", f.closed)
+	}
+}
@@ -1,11 +1,16 @@
 package xapidb
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 )
 
 // Example of DB:
@@ -96,9 +101,29 @@ import (
 //            Attr: { "ref": "...", ... }
 //            Children: []
 
+// DB can be modified while it is displayed (live events). Mutations done
+// through its methods hold the lock, other goroutines must hold the read
+// lock while browsing it. The UI applies mutations from its own goroutine
+// (app.QueueUpdateDraw) so it can read the nodes without locking.
 type DB struct {
+	sync.RWMutex
+
 	Root     *Node
 	RefIndex map[string]*Node // maps "OpaqueRef:xxx" to a *Node
+
+	// ParseErrors are the errors skipped by RecoverXapiDB, the database
+	// is partial if there are some.
+	ParseErrors []*ParseError
+
+	// index of the file of a database opened with OpenIndexed
+	index *Index
+	// src is the file the lazy tables are read from, see Close
+	src io.ReaderAt
+}
+
+// Partial returns true if parts of the database could not be parsed.
+func (db *DB) Partial() bool {
+	return len(db.ParseErrors) > 0
 }
 
 type Node struct {
@@ -106,6 +131,9 @@ type Node struct {
 	Attr     map[string]string
 	Children []*Node
 	Parent   *Node // Will be usefull to deal with "cd .."
+
+	// lazy are the rows of a table not parsed yet, see OpenXapiDB
+	lazy *lazyRows
 }
 
 func PrintTree(db *DB) {
@@ -135,60 +163,318 @@ func PrintTree(db *DB) {
 	print(db.Root, "")
 }
 
+// ErrTruncated is the error of a database that ends before all its elements
+// are closed.
+var ErrTruncated = errors.New("unexpected end of file")
+
+// ParseError is an error in the XML of a database. It gives the position of
+// the error and the table and row being parsed to find it in the file.
+type ParseError struct {
+	Line   int
+	Column int
+	Offset int64
+	// Table being parsed, empty before the first table
+	Table string
+	// Ref of the row being parsed if it could be read, otherwise After is
+	// the ref of the last row parsed before the error.
+	Ref   string
+	After string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	where := fmt.Sprintf("line %d, column %d", e.Line, e.Column)
+	if e.Table != "" {
+		where += ", table " + e.Table
+	}
+	if e.Ref != "" {
+		where += ", row " + e.Ref
+	} else if e.After != "" {
+		where += ", after row " + e.After
+	}
+
+	msg := e.Err.Error()
+	var syntax *xml.SyntaxError
+	if errors.As(e.Err, &syntax) {
+		// The line of the syntax error is relative to where the decoding
+		// started, it is already given
+		msg = syntax.Msg
+	}
+	return where + ": " + msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseXapiDB parses a database, it fails with a *ParseError on the first
+// error of the XML.
 func ParseXapiDB(data []byte) (*DB, error) {
-	decoder := xml.NewDecoder(bytes.NewReader(data))
+	return parse(data, false)
+}
+
+// ReadXapiDB parses a database read from r without keeping the file in
+// memory. Errors are *ParseError, without the ref of the row when the error
+// is in the row.
+func ReadXapiDB(r io.Reader) (*DB, error) {
+	p := newParser(make(map[string]*Node))
+	decoder := xml.NewDecoder(bufio.NewReaderSize(r, 64*1024))
+
+	if _, err := p.decode(decoder, false); err != nil {
+		line, column := decoder.InputPos()
+		return nil, p.errorAt(line, column, decoder.InputOffset(), nil, err)
+	}
+	if p.root == nil {
+		return nil, errors.New("no database found")
+	}
+	if len(p.stack) > 0 {
+		line, column := decoder.InputPos()
+		return nil, p.errorAt(line, column, decoder.InputOffset(), nil, ErrTruncated)
+	}
+
+	return &DB{Root: p.root, RefIndex: p.refIndex}, nil
+}
+
+// RecoverXapiDB parses a database keeping what can be parsed of a truncated
+// or corrupted file. Parsing starts again at the next row or table after an
+// error so complete rows following a bad one are kept, the rows of a table
+// whose start could not be parsed are skipped with it. The errors are in the
+// ParseErrors of the DB, it fails only if no database element was found.
+func RecoverXapiDB(data []byte) (*DB, error) {
+	return parse(data, true)
+}
+
+// parser builds the tree from the tokens of one or more decoders, a new
+// decoder is used to go on after an error.
+type parser struct {
+	stack    []*Node
+	root     *Node
+	refIndex map[string]*Node
+	strings  interner
+	// lastRef is the ref of the last row started
+	lastRef string
+}
+
+func newParser(refIndex map[string]*Node) *parser {
+	return &parser{refIndex: refIndex, strings: interner{}}
+}
+
+func (p *parser) newNode(t xml.StartElement) *Node {
+	n := &Node{
+		Name:     p.strings.intern(t.Name.Local),
+		Attr:     make(map[string]string, len(t.Attr)),
+		Children: []*Node{},
+	}
+	for _, a := range t.Attr {
+		value := a.Value
+		if len(value) <= maxInterned {
+			value = p.strings.intern(value)
+		}
+		n.Attr[p.strings.intern(a.Name.Local)] = value
+	}
+	return n
+}
+
+// maxInterned is the length of the longest values interned. The values
+// repeated in many rows are short (booleans, enums, numbers, empty sets and
+// maps, OpaqueRef:NULL), the longer ones are mostly references and uuids
+// unique to a row that would only grow the interner.
+const maxInterned = 16
+
+// interner returns a single copy of equal strings. The names of the
+// attributes and many values are the same in all the rows of a table,
+// keeping one copy of them saves a string per field of each row.
+type interner map[string]string
+
+func (in interner) intern(s string) string {
+	if c, ok := in[s]; ok {
+		return c
+	}
+	in[s] = s
+	return s
+}
+
+var refAttr = regexp.MustCompile(`\sref="([^"]*)"`)
+
+// boundaries are the places where parsing can start again after an error.
+// Out of a table, rows cannot be attached and parsing starts again at the
+// next table.
+var (
+	boundaries      = [][]byte{[]byte("<row "), []byte("<table "), []byte("</table>"), []byte("</database>")}
+	tableBoundaries = [][]byte{[]byte("<table "), []byte("</database>")}
+)
+
+func parse(data []byte, recover bool) (*DB, error) {
+	p := newParser(make(map[string]*Node))
+	var errs []*ParseError
+
+	base := 0
+	for base < len(data) {
+		decoder := xml.NewDecoder(bytes.NewReader(data[base:]))
 
-	var stack []*Node
-	var root *Node
-	refIndex := make(map[string]*Node)
+		start, err := p.decode(decoder, recover)
+		if err == nil {
+			break
+		}
+
+		perr := p.parseError(data, base+int(start), base+int(decoder.InputOffset()), err)
+		if !recover {
+			return nil, perr
+		}
+		errs = append(errs, perr)
+
+		bs := boundaries
+		if !p.inTable() {
+			bs = tableBoundaries
+		}
+		next := nextBoundary(data, base+int(start)+1, bs)
+		if next < 0 {
+			break
+		}
+		base = next
+	}
+
+	if p.root == nil {
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+		return nil, errors.New("no database found")
+	}
 
+	// A file cut after a complete element has no syntax error but elements
+	// not closed
+	if len(p.stack) > 0 && len(errs) == 0 {
+		perr := p.parseError(data, len(data), len(data), ErrTruncated)
+		if !recover {
+			return nil, perr
+		}
+		errs = append(errs, perr)
+	}
+
+	return &DB{Root: p.root, RefIndex: p.refIndex, ParseErrors: errs}, nil
+}
+
+// decode adds the elements read by decoder to the tree. On error it returns
+// the offset of the start of the token that failed.
+func (p *parser) decode(decoder *xml.Decoder, recover bool) (int64, error) {
 	for {
-		// Get the next XML token in the input stream
-		tok, err := decoder.Token()
+		start := decoder.InputOffset()
+
+		// The matching of start and end elements is checked by the parser
+		// as a new decoder doesn't know the elements opened before it
+		tok, err := decoder.RawToken()
+		if err == io.EOF {
+			return 0, nil
+		}
 		if err != nil {
-			// We reach the end of bytes
-			if err == io.EOF {
-				break
-			}
-			// Otherwise it is an error
-			return nil, err
+			return start, err
 		}
 
 		switch t := tok.(type) {
 		case xml.StartElement:
-			n := &Node{
-				Name:     t.Name.Local,
-				Attr:     map[string]string{},
-				Children: []*Node{},
-			}
-
-			//fmt.Printf("Created a new node %s\n", n.Name)
+			n := p.newNode(t)
 
-			// Set attributes
-			for _, a := range t.Attr {
-				//fmt.Printf("  adding attr %s -> %s\n", a.Name.Local, a.Value)
-				n.Attr[a.Name.Local] = a.Value
+			// An element opened again after an error (a row or a table)
+			// closes the one left open
+			if recover {
+				p.close(n.Name)
 			}
 
 			// Attach to parent if not root
-			if len(stack) > 0 {
-				parent := stack[len(stack)-1]
+			if len(p.stack) > 0 {
+				parent := p.stack[len(p.stack)-1]
 				n.Parent = parent
 				parent.Children = append(parent.Children, n)
+			} else if p.root == nil {
+				p.root = n
 			} else {
-				root = n
+				return start, fmt.Errorf("unexpected <%s> after the database", n.Name)
 			}
 
 			// Keep cross opaque reference for the node if available
 			if ref, ok := n.Attr["ref"]; ok {
-				refIndex[ref] = n
+				p.refIndex[ref] = n
+			}
+			if n.Name == "row" {
+				p.lastRef = n.Attr["ref"]
 			}
-			stack = append(stack, n)
+			p.stack = append(p.stack, n)
 
 		case xml.EndElement:
-			stack = stack[:len(stack)-1]
+			if len(p.stack) > 0 && p.stack[len(p.stack)-1].Name == t.Name.Local {
+				p.stack = p.stack[:len(p.stack)-1]
+			} else if !recover || !p.close(t.Name.Local) {
+				return start, fmt.Errorf("unexpected </%s>", t.Name.Local)
+			}
+		}
+	}
+}
+
+// inTable returns true if a table is open.
+func (p *parser) inTable() bool {
+	return slices.ContainsFunc(p.stack, func(n *Node) bool { return n.Name == "table" })
+}
+
+// close pops the last element with the given name and the ones opened after
+// it, it returns false if no element has this name.
+func (p *parser) close(name string) bool {
+	for i := len(p.stack) - 1; i >= 0; i-- {
+		if p.stack[i].Name == name {
+			p.stack = p.stack[:i]
+			return true
 		}
 	}
+	return false
+}
 
-	return &DB{Root: root, RefIndex: refIndex}, nil
+// parseError returns the error found at offset while parsing the token
+// starting at start.
+func (p *parser) parseError(data []byte, start, offset int, err error) *ParseError {
+	offset = min(offset, len(data))
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	column := offset - bytes.LastIndexByte(data[:offset], '\n')
+
+	return p.errorAt(line, column, int64(offset), data[min(start, offset):offset], err)
+}
+
+// errorAt returns the error found at a position, token is the part of the
+// token read before the error if known.
+func (p *parser) errorAt(line, column int, offset int64, token []byte, err error) *ParseError {
+	perr := &ParseError{Line: line, Column: column, Offset: offset, Err: err}
+
+	for i := len(p.stack) - 1; i >= 0; i-- {
+		if p.stack[i].Name == "table" {
+			perr.Table = p.stack[i].Attr["name"]
+			break
+		}
+	}
+
+	if bytes.HasPrefix(token, []byte("<row")) {
+		if m := refAttr.FindSubmatch(token); m != nil {
+			perr.Ref = string(m[1])
+		}
+	}
+	if perr.Ref == "" {
+		perr.After = p.lastRef
+	}
+
+	return perr
+}
+
+// nextBoundary returns the offset of the first of the boundaries bs after
+// from, -1 if there is none.
+func nextBoundary(data []byte, from int, bs [][]byte) int {
+	if from >= len(data) {
+		return -1
+	}
+	next := -1
+	for _, b := range bs {
+		if i := bytes.Index(data[from:], b); i >= 0 && (next < 0 || i < next) {
+			next = i
+		}
+	}
+	if next < 0 {
+		return -1
+	}
+	return from + next
 }
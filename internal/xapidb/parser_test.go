@@ -0,0 +1,305 @@
+package xapidb_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"example.com/readxapidb/internal/synth"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// benchDB is a synthetic database of about 10 MiB, most of it in the
+// message and task tables like the big databases of real pools.
+var benchDB = sync.OnceValue(func() []byte {
+	o := synth.DefaultOptions()
+	o.VMs, o.Messages, o.Tasks = 200, 20000, 5000
+
+	db, _ := synth.Generate(o)
+	var buf bytes.Buffer
+	if err := xapidb.WriteXapiDB(&buf, db); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+})
+
+// benchmarkParse runs parse on benchDB and reports the heap kept by the
+// parsed database, what a parser saves that the allocations don't show.
+func benchmarkParse(b *testing.B, parse func(data []byte) (*xapidb.DB, error)) {
+	data := benchDB()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	var db *xapidb.DB
+	for b.Loop() {
+		var err error
+		if db, err = parse(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	db = nil
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	db, _ = parse(data)
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(float64(after.HeapAlloc-min(before.HeapAlloc, after.HeapAlloc))/(1<<20), "heap-MiB")
+	runtime.KeepAlive(db)
+}
+
+// BenchmarkParseBaseline is the parser before interning and streaming: a
+// map per node with a copy of every string.
+func BenchmarkParseBaseline(b *testing.B) {
+	benchmarkParse(b, parseBaseline)
+}
+
+func BenchmarkParseBytes(b *testing.B) {
+	benchmarkParse(b, xapidb.ParseXapiDB)
+}
+
+func BenchmarkParseStream(b *testing.B) {
+	benchmarkParse(b, func(data []byte) (*xapidb.DB, error) {
+		return xapidb.ReadXapiDB(bytes.NewReader(data))
+	})
+}
+
+func BenchmarkParseLazy(b *testing.B) {
+	benchmarkParse(b, func(data []byte) (*xapidb.DB, error) {
+		return xapidb.OpenXapiDB(bytes.NewReader(data), int64(len(data)))
+	})
+}
+
+// BenchmarkParseLazyVM opens the database lazily and loads the VM table,
+// like expanding it in the tree.
+func BenchmarkParseLazyVM(b *testing.B) {
+	benchmarkParse(b, func(data []byte) (*xapidb.DB, error) {
+		db, err := xapidb.OpenXapiDB(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		return db, db.Table("VM").Load()
+	})
+}
+
+func TestParsers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the database is big")
+	}
+	data := benchDB()
+
+	want, err := parseBaseline(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, parse := range map[string]func() (*xapidb.DB, error){
+		"bytes":  func() (*xapidb.DB, error) { return xapidb.ParseXapiDB(data) },
+		"stream": func() (*xapidb.DB, error) { return xapidb.ReadXapiDB(bytes.NewReader(data)) },
+		"lazy": func() (*xapidb.DB, error) {
+			db, err := xapidb.OpenXapiDB(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				return nil, err
+			}
+			return db, db.LoadAll()
+		},
+	} {
+		db, err := parse()
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		if len(db.RefIndex) != len(want.RefIndex) {
+			t.Errorf("%s: got %d rows, want %d", name, len(db.RefIndex), len(want.RefIndex))
+		}
+		for ref, row := range want.RefIndex {
+			got, ok := db.RefIndex[ref]
+			if !ok {
+				t.Errorf("%s: no row %s", name, ref)
+				continue
+			}
+			if len(got.Attr) != len(row.Attr) {
+				t.Errorf("%s: %s has %d fields, want %d", name, ref, len(got.Attr), len(row.Attr))
+			}
+			for k, v := range row.Attr {
+				if got.Attr[k] != v {
+					t.Errorf("%s: %s %s is %q, want %q", name, ref, k, got.Attr[k], v)
+				}
+			}
+		}
+	}
+}
+
+// parseBaseline is the parser before interning and streaming, to compare.
+func parseBaseline(data []byte) (*xapidb.DB, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var stack []*xapidb.Node
+	var root *xapidb.Node
+	refIndex := make(map[string]*xapidb.Node)
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xapidb.Node{Name: t.Name.Local, Attr: map[string]string{}, Children: []*xapidb.Node{}}
+			for _, a := range t.Attr {
+				n.Attr[a.Name.Local] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				n.Parent = parent
+				parent.Children = append(parent.Children, n)
+			} else {
+				root = n
+			}
+			if ref, ok := n.Attr["ref"]; ok {
+				refIndex[ref] = n
+			}
+			stack = append(stack, n)
+
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	return &xapidb.DB{Root: root, RefIndex: refIndex}, nil
+}
+
+const recoverDB = `<?xml version="1.0" encoding="UTF-8"?>
+<database>
+  <table name="SR">
+    <row ref="OpaqueRef:sr1" name__label="local"/>
+  </table>
+  <table name="VM">
+    <row ref="OpaqueRef:vm1" name__label="vm1"/>
+    <row ref="OpaqueRef:vm2" name__label="vm2"/>
+    <row ref="OpaqueRef:vm3" name__label="vm3"/>
+  </table>
+  <table name="host">
+    <row ref="OpaqueRef:h1" name__label="h1"/>
+  </table>
+</database>`
+
+// cut returns recoverDB up to the end of s.
+func cut(s string) string {
+	return recoverDB[:strings.Index(recoverDB, s)+len(s)]
+}
+
+func TestRecoverXapiDB(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data string
+		// rows are the rows kept as ref@table
+		rows   []string
+		errors []string
+	}{
+		{
+			name: "valid",
+			data: recoverDB,
+			rows: []string{"h1@host", "sr1@SR", "vm1@VM", "vm2@VM", "vm3@VM"},
+		},
+		{
+			name:   "truncated in a row",
+			data:   cut(`<row ref="OpaqueRef:vm3" name__`),
+			rows:   []string{"sr1@SR", "vm1@VM", "vm2@VM"},
+			errors: []string{"line 9, column 36, table VM, row OpaqueRef:vm3: unexpected EOF"},
+		},
+		{
+			name:   "truncated after a row",
+			data:   cut(`<row ref="OpaqueRef:vm3" name__label="vm3"/>`),
+			rows:   []string{"sr1@SR", "vm1@VM", "vm2@VM", "vm3@VM"},
+			errors: []string{"line 9, column 49, table VM, after row OpaqueRef:vm3: unexpected end of file"},
+		},
+		{
+			name: "garbage in a row",
+			data: strings.Replace(recoverDB, `name__label="vm2"/>`, `name__label="vm2 <<<garbage`, 1),
+			// The next row starts a new row, vm2 is lost
+			rows:   []string{"h1@host", "sr1@SR", "vm1@VM", "vm3@VM"},
+			errors: []string{"line 8, column 48, table VM, row OpaqueRef:vm2: unescaped < inside quoted string"},
+		},
+		{
+			name:   "garbage between rows",
+			data:   strings.Replace(recoverDB, `    <row ref="OpaqueRef:vm2"`, "\x00\x00</x>\n    <row ref=\"OpaqueRef:vm2\"", 1),
+			rows:   []string{"h1@host", "sr1@SR", "vm1@VM", "vm2@VM", "vm3@VM"},
+			errors: []string{"line 8, column 3, table VM, after row OpaqueRef:vm1: illegal character code U+0000"},
+		},
+		{
+			name:   "garbage between tables",
+			data:   strings.Replace(recoverDB, `  <table name="VM">`, "junk</x>\n  <table name=\"VM\">", 1),
+			rows:   []string{"h1@host", "sr1@SR", "vm1@VM", "vm2@VM", "vm3@VM"},
+			errors: []string{"line 6, column 9, after row OpaqueRef:sr1: unexpected </x>"},
+		},
+		{
+			name: "garbage in a table",
+			data: strings.Replace(recoverDB, `<table name="VM">`, `<table name="VM" <<<>`, 1),
+			// Its rows have no table, the next table is parsed
+			rows:   []string{"h1@host", "sr1@SR"},
+			errors: []string{"line 6, column 20, after row OpaqueRef:sr1: expected attribute name in element"},
+		},
+		{
+			name:   "garbage at the end",
+			data:   strings.Replace(recoverDB, `</database>`, `</database><<<`, 1),
+			rows:   []string{"h1@host", "sr1@SR", "vm1@VM", "vm2@VM", "vm3@VM"},
+			errors: []string{"line 14, column 13, after row OpaqueRef:h1: expected element name after <"},
+		},
+	} {
+		db, err := xapidb.RecoverXapiDB([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		rows := []string{}
+		for ref, row := range db.RefIndex {
+			rows = append(rows, strings.TrimPrefix(ref, "OpaqueRef:")+"@"+row.Parent.Attr["name"])
+		}
+		slices.Sort(rows)
+		if !slices.Equal(rows, tt.rows) {
+			t.Errorf("%s: got rows %q, want %q", tt.name, rows, tt.rows)
+		}
+
+		errs := []string{}
+		for _, perr := range db.ParseErrors {
+			errs = append(errs, perr.Error())
+		}
+		if !slices.Equal(errs, tt.errors) {
+			t.Errorf("%s: got errors %q, want %q", tt.name, errs, tt.errors)
+		}
+		if db.Partial() != (len(tt.errors) > 0) {
+			t.Errorf("%s: partial is %t", tt.name, db.Partial())
+		}
+
+		// Without recovery the first error is returned
+		_, err = xapidb.ParseXapiDB([]byte(tt.data))
+		var perr *xapidb.ParseError
+		if len(tt.errors) == 0 && err != nil {
+			t.Errorf("%s: ParseXapiDB: %v", tt.name, err)
+		} else if len(tt.errors) > 0 && (!errors.As(err, &perr) || perr.Error() != tt.errors[0]) {
+			t.Errorf("%s: ParseXapiDB: got %v, want %s", tt.name, err, tt.errors[0])
+		}
+	}
+}
+
+func TestRecoverXapiDBNoDatabase(t *testing.T) {
+	for _, data := range []string{"", "garbage", "<database"} {
+		if db, err := xapidb.RecoverXapiDB([]byte(data)); err == nil {
+			t.Errorf("%q: got %d tables", data, len(db.Root.Children))
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package xapidb
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/readxapidb/internal/schema"
+)
+
+// NullRef is the value of references that don't point to an object.
+const NullRef = "OpaqueRef:NULL"
+
+// Layouts of the dates stored by XAPI (20250331T15:00:19Z).
+var dateTimeLayouts = []string{
+	"20060102T15:04:05Z07:00",
+	"20060102T15:04:05Z",
+	"20060102T15:04:05",
+}
+
+// ParseDateTime parses a date stored by XAPI.
+func ParseDateTime(s string) (time.Time, error) {
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// Decode returns the value of a field as stored in the database (escaped)
+// as a Go value of its type:
+//
+//	string, enum, ref -> string
+//	int               -> int64
+//	float             -> float64
+//	bool              -> bool
+//	datetime          -> time.Time
+//	set               -> []any
+//	map               -> map[string]any (keys are kept as strings)
+func Decode(t schema.Type, raw string) (any, error) {
+	s := UnescapeValue(raw)
+
+	switch t.Kind {
+	case schema.KindSet, schema.KindMap:
+		e, err := ParseSExpr(s)
+		if err != nil {
+			return nil, err
+		}
+		return decodeSExpr(t, e)
+	}
+
+	return decodeAtom(t, s)
+}
+
+func decodeAtom(t schema.Type, s string) (any, error) {
+	switch t.Kind {
+	case schema.KindString:
+		return s, nil
+
+	case schema.KindInt:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int %q", s)
+		}
+		return n, nil
+
+	case schema.KindFloat:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %q", s)
+		}
+		return f, nil
+
+	case schema.KindBool:
+		switch s {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid bool %q", s)
+
+	case schema.KindDateTime:
+		return ParseDateTime(s)
+
+	case schema.KindEnum:
+		if len(t.Values) > 0 && !slices.ContainsFunc(t.Values, func(v string) bool {
+			return strings.EqualFold(v, s)
+		}) {
+			return nil, fmt.Errorf("invalid %s %q", t.Name, s)
+		}
+		return s, nil
+
+	case schema.KindRef:
+		if s != "" && !strings.HasPrefix(s, "OpaqueRef:") {
+			return nil, fmt.Errorf("invalid reference %q", s)
+		}
+		return s, nil
+	}
+
+	return nil, fmt.Errorf("%s is not an atom", t)
+}
+
+func decodeSExpr(t schema.Type, e SExpr) (any, error) {
+	switch t.Kind {
+	case schema.KindSet, schema.KindMap:
+		if !e.IsList {
+			// Nested sets and maps are stored as strings
+			nested, err := ParseSExpr(e.Atom)
+			if err != nil {
+				return nil, err
+			}
+			e = nested
+		}
+
+		if t.Kind == schema.KindSet {
+			set := make([]any, 0, len(e.List))
+			for _, item := range e.List {
+				v, err := decodeSExpr(*t.Elem, item)
+				if err != nil {
+					return nil, err
+				}
+				set = append(set, v)
+			}
+			return set, nil
+		}
+
+		if !e.IsMap() {
+			return nil, fmt.Errorf("%s is not a map", e)
+		}
+		m := make(map[string]any, len(e.List))
+		for _, pair := range e.List {
+			key := pair.List[0].Atom
+			if _, err := decodeAtom(*t.Key, key); err != nil {
+				return nil, err
+			}
+			v, err := decodeSExpr(*t.Elem, pair.List[1])
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		}
+		return m, nil
+	}
+
+	if e.IsList {
+		return nil, fmt.Errorf("%s is not a %s", e, t)
+	}
+	return decodeAtom(t, e.Atom)
+}
+
+// Reference is a reference held by a field of a row.
+type Reference struct {
+	Field string
+	Ref   string
+	// Class of the referenced object, empty if the field is not in the
+	// schema.
+	Class string
+}
+
+// className returns the class of the row, the name of its table.
+func className(row *Node) string {
+	if row.Parent == nil {
+		return ""
+	}
+	return row.Parent.Attr["name"]
+}
+
+// FieldRef returns the reference held by a field of type ref. Fields that
+// are not in the schema are references if their value looks like one. Null
+// references are ignored.
+func FieldRef(s *schema.Schema, row *Node, field string) (Reference, bool) {
+	v := row.Attr[field]
+	if v == "" || v == NullRef {
+		return Reference{}, false
+	}
+
+	f, known := s.Field(className(row), field)
+	if !known {
+		if strings.HasPrefix(v, "OpaqueRef:") {
+			return Reference{Field: field, Ref: v}, true
+		}
+		return Reference{}, false
+	}
+
+	if f.Type.Kind != schema.KindRef {
+		return Reference{}, false
+	}
+	return Reference{Field: field, Ref: v, Class: f.Type.Name}, true
+}
+
+// Refs returns the references held by the fields of the row, including the
+// ones in sets and maps, sorted by field. The reference of the row itself
+// is not included.
+func Refs(s *schema.Schema, row *Node) []Reference {
+	fields := make([]string, 0, len(row.Attr))
+	for k := range row.Attr {
+		if k != "ref" && k != "_ref" {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+
+	refs := []Reference{}
+	for _, field := range fields {
+		f, known := s.Field(className(row), field)
+		if !known || f.Type.Kind == schema.KindRef {
+			if r, ok := FieldRef(s, row, field); ok {
+				refs = append(refs, r)
+			}
+			continue
+		}
+		if !f.Type.HasRefs() {
+			continue
+		}
+
+		v, err := Decode(f.Type, row.Attr[field])
+		if err != nil {
+			continue
+		}
+		collectRefs(f.Type, v, func(ref, class string) {
+			if ref != "" && ref != NullRef {
+				refs = append(refs, Reference{Field: field, Ref: ref, Class: class})
+			}
+		})
+	}
+
+	return refs
+}
+
+// collectRefs calls add for each reference of a decoded value.
+func collectRefs(t schema.Type, v any, add func(ref, class string)) {
+	switch t.Kind {
+	case schema.KindRef:
+		add(v.(string), t.Name)
+
+	case schema.KindSet:
+		for _, item := range v.([]any) {
+			collectRefs(*t.Elem, item, add)
+		}
+
+	case schema.KindMap:
+		m := v.(map[string]any)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if t.Key.Kind == schema.KindRef {
+				add(k, t.Key.Name)
+			}
+			collectRefs(*t.Elem, m[k], add)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package xapidb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Values stored in the XAPI database have their whitespaces escaped so
+// that they survive the XML serialization (see xml_spaces.ml in xapi):
+//
+//	'%'  -> "%%"
+//	' '  -> "%."
+//	'\t' -> "%t"
+//	'\n' -> "%n"
+//	'\r' -> "%r"
+//
+// Sets and maps are stored as s-expressions, for example:
+//
+//	set: ('a' 'b')
+//	map: (('key1' 'value1') ('key2' 'value2'))
+//
+// and then escaped like any other string value.
+
+func EscapeValue(s string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		switch r {
+		case '%':
+			b.WriteString("%%")
+		case ' ':
+			b.WriteString("%.")
+		case '\t':
+			b.WriteString("%t")
+		case '\n':
+			b.WriteString("%n")
+		case '\r':
+			b.WriteString("%r")
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+func UnescapeValue(s string) string {
+	// Fast path, most of the values are not escaped
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case '%':
+			b.WriteByte('%')
+		case '.':
+			b.WriteByte(' ')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			// Unknown escape, keep it as is
+			b.WriteByte('%')
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
+
+// QuoteSExpr returns s as an s-expression string atom: surrounded by single
+// quotes with quotes and backslashes escaped.
+func QuoteSExpr(s string) string {
+	var b strings.Builder
+
+	b.WriteByte('\'')
+	for _, r := range s {
+		if r == '\'' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('\'')
+
+	return b.String()
+}
+
+// SExpr is a parsed set or map value. An atom has no list, a list can be
+// empty. Maps are lists of two atoms lists.
+type SExpr struct {
+	Atom   string
+	List   []SExpr
+	IsList bool
+}
+
+// ParseSExpr parses an unescaped value like "(('k' 'v') ('k2' 'v2'))".
+func ParseSExpr(s string) (SExpr, error) {
+	p := sexprParser{s: s}
+
+	e, err := p.parse()
+	if err != nil {
+		return SExpr{}, err
+	}
+
+	p.skipSpaces()
+	if p.pos != len(p.s) {
+		return SExpr{}, fmt.Errorf("unexpected %q at offset %d", p.s[p.pos:], p.pos)
+	}
+
+	return e, nil
+}
+
+// IsMap returns true if all elements of the list are pairs of atoms. Note
+// that an empty list is both an empty set and an empty map.
+func (e SExpr) IsMap() bool {
+	if !e.IsList {
+		return false
+	}
+	for _, item := range e.List {
+		if !item.IsList || len(item.List) != 2 || item.List[0].IsList {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the s-expression in the format used by the database
+// (before escaping).
+func (e SExpr) String() string {
+	if !e.IsList {
+		return QuoteSExpr(e.Atom)
+	}
+
+	items := make([]string, 0, len(e.List))
+	for _, item := range e.List {
+		items = append(items, item.String())
+	}
+	return "(" + strings.Join(items, " ") + ")"
+}
+
+type sexprParser struct {
+	s   string
+	pos int
+}
+
+func (p *sexprParser) skipSpaces() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *sexprParser) parse() (SExpr, error) {
+	p.skipSpaces()
+	if p.pos == len(p.s) {
+		return SExpr{}, fmt.Errorf("unexpected end of value")
+	}
+
+	switch p.s[p.pos] {
+	case '(':
+		p.pos++
+		e := SExpr{IsList: true, List: []SExpr{}}
+		for {
+			p.skipSpaces()
+			if p.pos == len(p.s) {
+				return SExpr{}, fmt.Errorf("missing closing parenthesis")
+			}
+			if p.s[p.pos] == ')' {
+				p.pos++
+				return e, nil
+			}
+			item, err := p.parse()
+			if err != nil {
+				return SExpr{}, err
+			}
+			e.List = append(e.List, item)
+		}
+
+	case '\'':
+		p.pos++
+		var b strings.Builder
+		for p.pos < len(p.s) {
+			c := p.s[p.pos]
+			switch {
+			case c == '\\' && p.pos+1 < len(p.s):
+				b.WriteByte(p.s[p.pos+1])
+				p.pos += 2
+			case c == '\'':
+				p.pos++
+				return SExpr{Atom: b.String()}, nil
+			default:
+				b.WriteByte(c)
+				p.pos++
+			}
+		}
+		return SExpr{}, fmt.Errorf("missing closing quote")
+	}
+
+	return SExpr{}, fmt.Errorf("unexpected %q at offset %d", p.s[p.pos], p.pos)
+}
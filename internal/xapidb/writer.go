@@ -0,0 +1,88 @@
+package xapidb
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+)
+
+// rowFirst are the attributes written first in rows, the others are sorted
+// like xapi does.
+var rowFirst = []string{"ref", "__ctime", "__mtime"}
+
+// WriteXapiDB writes db as a XAPI database file that ParseXapiDB reads
+// back. The tables of a lazy database are loaded first.
+func WriteXapiDB(w io.Writer, db *DB) error {
+	if err := db.LoadAll(); err != nil {
+		return err
+	}
+
+	db.RLock()
+	defer db.RUnlock()
+
+	bw := bufio.NewWriterSize(w, 64*1024)
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	writeNode(bw, db.Root, "")
+	return bw.Flush()
+}
+
+func writeNode(w *bufio.Writer, n *Node, indent string) {
+	w.WriteString(indent)
+	w.WriteByte('<')
+	w.WriteString(n.Name)
+
+	names := make([]string, 0, len(n.Attr))
+	for name := range n.Attr {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		pi, pj := attrPriority(names[i]), attrPriority(names[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		w.WriteByte(' ')
+		w.WriteString(name)
+		w.WriteString(`="`)
+		attrEscaper.WriteString(w, n.Attr[name])
+		w.WriteByte('"')
+	}
+
+	if len(n.Children) == 0 {
+		w.WriteString("/>\n")
+		return
+	}
+
+	w.WriteString(">\n")
+	for _, child := range n.Children {
+		writeNode(w, child, indent+"  ")
+	}
+	w.WriteString(indent)
+	w.WriteString("</")
+	w.WriteString(n.Name)
+	w.WriteString(">\n")
+}
+
+func attrPriority(name string) int {
+	for i, first := range rowFirst {
+		if name == first {
+			return i
+		}
+	}
+	return len(rowFirst)
+}
+
+// attrEscaper escapes what cannot be in a double quoted attribute. The
+// whitespaces of values are already escaped (see EscapeValue).
+var attrEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"\n", "&#xA;",
+	"\r", "&#xD;",
+	"\t", "&#x9;",
+)
@@ -1,77 +1,250 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"os"
+	"os/signal"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
 	"example.com/readxapidb/internal/args"
+	"example.com/readxapidb/internal/cache"
+	"example.com/readxapidb/internal/config"
+	"example.com/readxapidb/internal/diff"
 	"example.com/readxapidb/internal/fetch"
+	"example.com/readxapidb/internal/schema"
 	"example.com/readxapidb/internal/theme"
 	"example.com/readxapidb/internal/ui"
+	"example.com/readxapidb/internal/validate"
+	"example.com/readxapidb/internal/xapi"
 	"example.com/readxapidb/internal/xapidb"
 )
 
 func main() {
 	args := args.GetArgs()
 
-	data, err := fetch.DB(args)
-	if err != nil {
-		if args.Hostname == "" {
-			fmt.Printf("failed to read %s: %s\n", args.FileName, err)
-		} else {
-			fmt.Printf("failed to fetch %s from %s: %s\n", args.FileName, args.Hostname, err)
+	switch args.Command {
+	case "":
+	case "snapshots":
+		if !runSnapshots(&args) {
+			return
 		}
+	default:
+		fmt.Printf("unknown command %s\n", args.Command)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Read %d bytes from %s\n", len(data), args.FileName)
+	var db *xapidb.DB
+	var refresh ui.RefreshFunc
+	var client *xapi.Client
+	var eventToken string
+	var hosts []ui.HostDB
+	var reload ui.ReloadFunc
+
+	if args.Live {
+		var err error
+		client, err = xapi.Login(args.Hostname, args.Username, args.Password)
+		if err != nil {
+			fmt.Printf("failed to login to %s: %s\n", args.Hostname, err)
+			os.Exit(1)
+		}
+		defer client.Logout()
+
+		// Get the token before loading objects to not miss any event
+		eventToken, err = xapi.EventToken(client)
+		if err != nil {
+			fmt.Printf("failed to subscribe to events of %s: %s\n", args.Hostname, err)
+			os.Exit(1)
+		}
+
+		db, err = xapi.Load(client)
+		if err != nil {
+			fmt.Printf("failed to load objects from %s: %s\n", args.Hostname, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Loaded %d objects from %s\n", len(db.RefIndex), args.Hostname)
+
+		refresh = func(n *xapidb.Node) (map[string]string, error) {
+			return xapi.FetchRow(client, n)
+		}
+		reload = func(progress func(msg string)) (*xapidb.DB, error) {
+			progress(fmt.Sprintf("Loading objects from %s", args.Hostname))
+			return xapi.Load(client)
+		}
+	} else if args.Pool || len(args.Hosts) > 0 {
+		hosts = loadHosts(args)
+
+		// Display the first host that has been successfully fetched
+		db = &xapidb.DB{}
+		for _, h := range hosts {
+			if h.Err == nil {
+				db.Replace(h.DB)
+				break
+			}
+		}
+		if db.Root == nil {
+			fmt.Println("failed to fetch the database from all hosts")
+			os.Exit(1)
+		}
+	} else if (args.Hostname == "" || args.Offline) && !args.Recover {
+		path := args.FileName
+		if args.Offline {
+			// Reopen the last database fetched from the host
+			s, err := (&cache.Cache{Dir: args.CacheDir}).Latest(args.Hostname)
+			if err != nil {
+				fmt.Printf("failed to open the last snapshot of %s: %s\n", args.Hostname, err)
+				os.Exit(1)
+			}
+			path = s.Path
+		}
+
+		var err error
+		db, err = openLocal(args, path)
+		if err != nil {
+			fmt.Printf("failed to parse %s: %s\n", path, err)
+			fmt.Println("Use -recover to keep what can be parsed")
+			os.Exit(1)
+		}
+		fmt.Printf("Read %d tables from %s\n", len(db.Root.Children), path)
+
+		reload = func(progress func(msg string)) (*xapidb.DB, error) {
+			progress(fmt.Sprintf("Reading %s", path))
+			return openLocal(args, path)
+		}
+	} else {
+		// Ctrl-C cancels the download, the UI is not started yet
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		progress, endProgress := fetch.TerminalProgress(os.Stdout, args.FileName)
+		data, err := fetch.DBContext(ctx, args, progress)
+		stop()
+		if err != nil {
+			endProgress()
+			if args.Offline {
+				fmt.Printf("failed to open the last snapshot of %s: %s\n", args.Hostname, err)
+			} else if args.Hostname == "" {
+				fmt.Printf("failed to read %s: %s\n", args.FileName, err)
+			} else {
+				fmt.Printf("failed to fetch %s from %s: %s\n", args.FileName, args.Hostname, err)
+			}
+			os.Exit(1)
+		}
+
+		if args.Offline {
+			fmt.Printf("Read %d bytes from the last snapshot of %s\n", len(data), args.Hostname)
+		} else {
+			fmt.Printf("Read %d bytes from %s\n", len(data), args.FileName)
+		}
+
+		db, err = parseDB(args, data)
+		if err != nil {
+			fmt.Printf("failed to parse %s: %s\n", args.FileName, err)
+			if !args.Recover {
+				fmt.Println("Use -recover to keep what can be parsed")
+			}
+			os.Exit(1)
+		}
+		for _, perr := range db.ParseErrors {
+			fmt.Printf("Skipped a part of %s: %s\n", args.FileName, perr)
+		}
+
+		if args.Hostname != "" && !args.Offline {
+			saveSnapshot(args, args.Hostname, data, db)
+		}
+
+		reload = func(progress func(msg string)) (*xapidb.DB, error) {
+			progress(fmt.Sprintf("Fetching %s", args.FileName))
+			data, err := fetch.DB(args)
+			if err != nil {
+				return nil, err
+			}
+
+			progress(fmt.Sprintf("Parsing %d bytes", len(data)))
+			return parseDB(args, data)
+		}
+	}
+
+	if args.Schema != "" {
+		s, err := schema.Load(args.Schema)
+		if err != nil {
+			fmt.Printf("Error: failed to load the schema: %s\n", err)
+			os.Exit(1)
+		}
+		schema.Set(s)
+	}
+
+	var validation *validate.Report
+	if args.Validate {
+		validation = validate.Validate(db, schema.Current)
+		validation.Print(os.Stdout)
 
-	db, err := xapidb.ParseXapiDB(data)
-	if err != nil && err != io.EOF {
-		fmt.Printf("failed to parse %s: %s\n", args.FileName, err)
+		if args.ReportOnly && !args.Compare {
+			if validation.Errors() > 0 {
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	var report *diff.Report
+	if args.Compare {
+		report = compareLive(args, db)
+		report.Print(os.Stdout)
+
+		if args.ReportOnly {
+			if len(report.Findings) > 0 || (validation != nil && validation.Errors() > 0) {
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	// The theme must be set before creating the primitives
+	if err := theme.LoadDir(config.ThemesDir()); err != nil {
+		fmt.Printf("Failed to load themes: %s\n", err)
+		os.Exit(1)
+	}
+	t, err := theme.Resolve(args.Theme)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		os.Exit(1)
+	}
+	theme.Set(t)
+
+	keys := ui.DefaultKeymap()
+	if err := keys.Override(args.Keys); err != nil {
+		fmt.Printf("Error: invalid key bindings: %s\n", err)
 		os.Exit(1)
 	}
 
-	rootNode := db.Root
+	app := tview.NewApplication()
 
 	// Instead of printing the tree we will try to use the demo of navigable
 	// tree view of current dir: https://github.com/rivo/tview/wiki/TreeView
-	rootTree := ui.MakeTreeNode(rootNode)
-	ui.LoadChildren(rootTree, rootNode)
-	rootTree.SetExpanded(true)
-
 	tree := tview.NewTreeView()
-
-	// Set current node to first child if it exists
-	if len(rootTree.GetChildren()) > 0 {
-		tree.SetCurrentNode(rootTree.GetChildren()[0])
-	} else {
-		tree.SetCurrentNode(rootTree)
-	}
+	ui.ResetTree(tree, db)
 
 	const (
 		searchHeight = 3
 		debugHeight  = 5
 		helpHeight   = 1
+
+		fieldInfoHeight = 6
 	)
 
 	// Set border and title are done separatly otherwise the type of tree is
 	// modified to tview.Box instead of TreeView !!!
-	tree.SetRoot(rootTree).
-		SetBorder(true).
+	tree.SetBorder(true).
 		SetTitle("XAPI DB")
 
 	// We add a status view to print all row attributes for example
 	status := tview.NewTable()
 	status.SetBorders(false).
 		SetSelectable(true, false).
-		SetSelectedStyle(tcell.Style{}.
-			Background(tcell.NewHexColor(0x504945)).
-			Foreground(tcell.NewHexColor(0xfabd2f))).
+		SetSelectedStyle(theme.Current.SelectedStyle()).
 		SetBorder(true).
 		SetTitle("Attributes")
 
@@ -90,14 +263,22 @@ func main() {
 	// Add help footer
 	help := tview.NewTextView()
 	help.SetTextAlign(tview.AlignCenter).SetDynamicColors(true)
-	help.SetText("[yellow]'q'[white]=quit | [yellow]'/'[white]=search | [yellow]'Space/Enter'[white]=expand/collapse")
+	ui.SetHelp(help, keys.HelpLine(ui.ActionQuit, ui.ActionSearch, ui.ActionTheme, ui.ActionHelp)+
+		" | [yellow]'Space/Enter'[white]=expand/collapse")
 	help.SetBackgroundColor(tcell.ColorDefault)
 
+	// The type and documentation of the selected attribute are shown below
+	// the status
+	attributes := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(status, 0, 1, false).
+		AddItem(ui.NewFieldInfo(tree, status), fieldInfoHeight, 0, false)
+
 	// Create main Layout with tree and status
 	mainLayout := tview.NewFlex().
 		SetDirection(tview.FlexColumn).
 		AddItem(tree, 0, 1, true).
-		AddItem(status, 0, 1, false)
+		AddItem(attributes, 0, 1, false)
 
 	// We create 2 pages so we will be able to switch between
 	// normal view and search view. Switch view is just normal view with
@@ -117,26 +298,198 @@ func main() {
 
 	pages := tview.NewPages().
 		AddPage("normal", normalLayout, true, true).
-		AddPage("search", searchLayout, true, false)
+		AddPage("search", searchLayout, true, false).
+		AddPage("help", ui.MakeHelpView(keys), true, false)
 
-	tview.Styles = theme.GruvboxDark
+	// Rows visited by following references, searching, ...
+	history := ui.NewHistory()
+	pages.AddPage("history", ui.MakeHistoryList(app, tree, status, debugView, pages, db, history), true, false)
 
-	app := tview.NewApplication()
+	// The columns chosen in the grid are remembered between runs
+	state, err := config.LoadState(config.StatePath())
+	if err != nil {
+		fmt.Printf("Failed to read the state: %s\n", err)
+		state = &config.State{Columns: map[string][]string{}}
+	}
+	viewer := ui.NewValueViewer(app, pages, debugView, keys)
+	grid := ui.NewGrid(app, tree, status, debugView, pages, db, history, keys, state.Columns, func() error {
+		return state.Save(config.StatePath())
+	})
+
+	ui.SetValidationPage(app, tree, status, debugView, pages, help, db, history)
+
+	if report != nil {
+		ui.SetDiffPage(app, tree, status, debugView, pages, help, db, report, history)
+		if len(report.Findings) > 0 {
+			pages.SwitchToPage("diff")
+		}
+	}
+	if validation != nil && len(validation.Findings) > 0 {
+		ui.ShowValidation(pages, debugView, validation)
+	}
+	ui.LogParseErrors(debugView, db)
+
+	if hosts != nil {
+		hostList := ui.MakeHostList(app, tree, status, debugView, pages, help, db, hosts, history)
+		pages.AddPage("hosts", hostList, true, false)
+	}
 
 	// Track which pane has focus
 	var currentFocus tview.Primitive = tree
 
 	// Set initial focus
-	tree.SetBorderColor(tcell.ColorGreen)
-	status.SetBorderColor(tcell.ColorWhite)
+	tree.SetBorderColor(theme.Current.FocusBorder)
+	status.SetBorderColor(theme.Current.Border)
 
 	// Set callbacks
 	tree.SetSelectedFunc(ui.SelectedTreeCallback(status))
-	status.SetSelectedFunc(ui.SelectedStatusCallback(status, debugView, app, tree, db))
-	searchInput.SetDoneFunc(ui.DoneSearchCallback(app, tree, status, searchInput, debugView, db, pages))
-	app.SetInputCapture(ui.InputCaptureCallback(app, tree, status, searchInput, pages, &currentFocus))
+	searchInput.SetDoneFunc(ui.DoneSearchCallback(app, tree, status, searchInput, debugView, db, pages, history))
+	app.SetInputCapture(ui.InputCaptureCallback(app, tree, status, searchInput, debugView, pages, &currentFocus, refresh, db, reload, keys, history, grid, viewer))
+
+	// In live mode the model is kept up to date using events. They are
+	// applied from the UI goroutine so nodes can be read without locking.
+	if client != nil {
+		go func() {
+			err := xapi.Watch(client, eventToken, func(events []xapi.Event) {
+				app.QueueUpdateDraw(func() {
+					changes := xapi.ApplyEvents(db, events)
+					ui.ApplyChanges(app, tree, status, changes, ui.HighlightDuration)
+				})
+			})
+			app.QueueUpdateDraw(func() {
+				debugView.Clear()
+				ui.Logf(debugView, "[red]Stopped receiving events: %s", err)
+			})
+		}()
+	}
+
+	if args.Watch && client == nil && hosts == nil {
+		go watch(args, app, tree, status, debugView, db)
+	}
 
 	if err := app.SetRoot(pages, true).Run(); err != nil {
 		panic(err)
 	}
 }
+
+// openLocal opens a local database file with its index if it has one
+// (snapshots), otherwise it parses it while reading it, or only indexes its
+// tables with -lazy.
+func openLocal(args args.Args, path string) (*xapidb.DB, error) {
+	// A watched file changes, it must be read again each time
+	if !args.Watch {
+		db, err := cache.OpenIndexed(path)
+		if err == nil {
+			return db, nil
+		}
+		if errors.Is(err, xapidb.ErrStaleIndex) {
+			// Index it again for the next time
+			defer cache.WriteIndex(path)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Lazy {
+		// The file is read when tables are loaded, it stays open until
+		// the database is replaced by a reload
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		db, err := xapidb.OpenXapiDB(f, info.Size())
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return db, nil
+	}
+
+	defer f.Close()
+	return xapidb.ReadXapiDB(f)
+}
+
+// parseDB parses a database file, keeping what can be parsed with -recover.
+func parseDB(args args.Args, data []byte) (*xapidb.DB, error) {
+	if args.Recover {
+		return xapidb.RecoverXapiDB(data)
+	}
+	return xapidb.ParseXapiDB(data)
+}
+
+// compareLive compares db with the live objects of the pool.
+func compareLive(args args.Args, db *xapidb.DB) *diff.Report {
+	client, err := xapi.Login(args.LiveHostname, args.Username, args.Password)
+	if err != nil {
+		fmt.Printf("failed to login to %s: %s\n", args.LiveHostname, err)
+		os.Exit(1)
+	}
+	defer client.Logout()
+
+	live, err := xapi.Load(client)
+	if err != nil {
+		fmt.Printf("failed to load objects from %s: %s\n", args.LiveHostname, err)
+		os.Exit(1)
+	}
+
+	report := diff.Compare(db, live, diff.DefaultOptions())
+	report.AName = args.FileName
+	report.BName = args.LiveHostname + " (live)"
+
+	return report
+}
+
+// loadHosts fetches and parses the database of all hosts given on the
+// command line or of all hosts of the pool.
+func loadHosts(args args.Args) []ui.HostDB {
+	hostnames := args.Hosts
+
+	if args.Pool {
+		// The master database gives us the address of the other hosts
+		data, err := fetch.FileSSH(context.Background(), fetch.SSHOptionsFrom(args, args.Hostname), nil)
+		if err != nil {
+			fmt.Printf("failed to fetch %s from %s: %s\n", args.FileName, args.Hostname, err)
+			os.Exit(1)
+		}
+
+		master, err := xapidb.ParseXapiDB(data)
+		if err != nil {
+			fmt.Printf("failed to parse %s from %s: %s\n", args.FileName, args.Hostname, err)
+			os.Exit(1)
+		}
+
+		hosts := []ui.HostDB{{Host: args.Hostname, DB: master}}
+		fmt.Printf("%s: read %d bytes\n", args.Hostname, len(data))
+		saveSnapshot(args, args.Hostname, data, master)
+
+		return append(hosts, fetchHosts(args, fetch.PoolMembers(master))...)
+	}
+
+	return fetchHosts(args, hostnames)
+}
+
+func fetchHosts(args args.Args, hostnames []string) []ui.HostDB {
+	hosts := []ui.HostDB{}
+
+	for _, r := range fetch.Hosts(fetch.SSHOptionsFrom(args, ""), hostnames, args.Parallel) {
+		h := ui.HostDB{Host: r.Host, Err: r.Err}
+		if r.Err == nil {
+			h.DB, h.Err = xapidb.ParseXapiDB(r.Data)
+		}
+
+		if h.Err != nil {
+			fmt.Printf("%s: failed: %s\n", r.Host, h.Err)
+		} else {
+			fmt.Printf("%s: read %d bytes\n", r.Host, len(r.Data))
+			saveSnapshot(args, r.Host, r.Data, h.DB)
+		}
+
+		hosts = append(hosts, h)
+	}
+
+	return hosts
+}
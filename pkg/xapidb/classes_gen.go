@@ -0,0 +1,3634 @@
+// Code generated by xapitypes from the XAPI schema 5.790. DO NOT EDIT.
+
+package xapidb
+
+import "time"
+
+// Objects are the objects of the core classes, see DB.Objects.
+type Objects struct {
+	Bonds          []*Bond
+	GPUGroups      []*GPUGroup
+	Hosts          []*Host
+	HostCPUs       []*HostCPU
+	HostMetrics    []*HostMetrics
+	Messages       []*Message
+	Networks       []*Network
+	PBDs           []*PBD
+	PCIs           []*PCI
+	PGPUs          []*PGPU
+	PIFs           []*PIF
+	Pools          []*Pool
+	SMs            []*SM
+	SRs            []*SR
+	Tasks          []*Task
+	Tunnels        []*Tunnel
+	VBDs           []*VBD
+	VDIs           []*VDI
+	VGPUs          []*VGPU
+	VGPUTypes      []*VGPUType
+	VIFs           []*VIF
+	VLANs          []*VLAN
+	VMs            []*VM
+	VMGuestMetrics []*VMGuestMetrics
+	VMMetrics      []*VMMetrics
+
+	objects map[string]any
+}
+
+// load creates the objects of all the classes before decoding their
+// fields, so references resolve to any of them.
+func (o *Objects) load(d *decoder) {
+	bonds, bondsRows := create[Bond](d, "Bond")
+	gpugroups, gpugroupsRows := create[GPUGroup](d, "GPU_group")
+	hosts, hostsRows := create[Host](d, "host")
+	hostcpus, hostcpusRows := create[HostCPU](d, "host_cpu")
+	hostmetrics, hostmetricsRows := create[HostMetrics](d, "host_metrics")
+	messages, messagesRows := create[Message](d, "message")
+	networks, networksRows := create[Network](d, "network")
+	pbds, pbdsRows := create[PBD](d, "PBD")
+	pcis, pcisRows := create[PCI](d, "PCI")
+	pgpus, pgpusRows := create[PGPU](d, "PGPU")
+	pifs, pifsRows := create[PIF](d, "PIF")
+	pools, poolsRows := create[Pool](d, "pool")
+	sms, smsRows := create[SM](d, "SM")
+	srs, srsRows := create[SR](d, "SR")
+	tasks, tasksRows := create[Task](d, "task")
+	tunnels, tunnelsRows := create[Tunnel](d, "tunnel")
+	vbds, vbdsRows := create[VBD](d, "VBD")
+	vdis, vdisRows := create[VDI](d, "VDI")
+	vgpus, vgpusRows := create[VGPU](d, "VGPU")
+	vgputypes, vgputypesRows := create[VGPUType](d, "VGPU_type")
+	vifs, vifsRows := create[VIF](d, "VIF")
+	vlans, vlansRows := create[VLAN](d, "VLAN")
+	vms, vmsRows := create[VM](d, "VM")
+	vmguestmetrics, vmguestmetricsRows := create[VMGuestMetrics](d, "VM_guest_metrics")
+	vmmetrics, vmmetricsRows := create[VMMetrics](d, "VM_metrics")
+
+	o.Bonds = bonds
+	for i, row := range bondsRows {
+		bonds[i].decode(d, row)
+	}
+
+	o.GPUGroups = gpugroups
+	for i, row := range gpugroupsRows {
+		gpugroups[i].decode(d, row)
+	}
+
+	o.Hosts = hosts
+	for i, row := range hostsRows {
+		hosts[i].decode(d, row)
+	}
+
+	o.HostCPUs = hostcpus
+	for i, row := range hostcpusRows {
+		hostcpus[i].decode(d, row)
+	}
+
+	o.HostMetrics = hostmetrics
+	for i, row := range hostmetricsRows {
+		hostmetrics[i].decode(d, row)
+	}
+
+	o.Messages = messages
+	for i, row := range messagesRows {
+		messages[i].decode(d, row)
+	}
+
+	o.Networks = networks
+	for i, row := range networksRows {
+		networks[i].decode(d, row)
+	}
+
+	o.PBDs = pbds
+	for i, row := range pbdsRows {
+		pbds[i].decode(d, row)
+	}
+
+	o.PCIs = pcis
+	for i, row := range pcisRows {
+		pcis[i].decode(d, row)
+	}
+
+	o.PGPUs = pgpus
+	for i, row := range pgpusRows {
+		pgpus[i].decode(d, row)
+	}
+
+	o.PIFs = pifs
+	for i, row := range pifsRows {
+		pifs[i].decode(d, row)
+	}
+
+	o.Pools = pools
+	for i, row := range poolsRows {
+		pools[i].decode(d, row)
+	}
+
+	o.SMs = sms
+	for i, row := range smsRows {
+		sms[i].decode(d, row)
+	}
+
+	o.SRs = srs
+	for i, row := range srsRows {
+		srs[i].decode(d, row)
+	}
+
+	o.Tasks = tasks
+	for i, row := range tasksRows {
+		tasks[i].decode(d, row)
+	}
+
+	o.Tunnels = tunnels
+	for i, row := range tunnelsRows {
+		tunnels[i].decode(d, row)
+	}
+
+	o.VBDs = vbds
+	for i, row := range vbdsRows {
+		vbds[i].decode(d, row)
+	}
+
+	o.VDIs = vdis
+	for i, row := range vdisRows {
+		vdis[i].decode(d, row)
+	}
+
+	o.VGPUs = vgpus
+	for i, row := range vgpusRows {
+		vgpus[i].decode(d, row)
+	}
+
+	o.VGPUTypes = vgputypes
+	for i, row := range vgputypesRows {
+		vgputypes[i].decode(d, row)
+	}
+
+	o.VIFs = vifs
+	for i, row := range vifsRows {
+		vifs[i].decode(d, row)
+	}
+
+	o.VLANs = vlans
+	for i, row := range vlansRows {
+		vlans[i].decode(d, row)
+	}
+
+	o.VMs = vms
+	for i, row := range vmsRows {
+		vms[i].decode(d, row)
+	}
+
+	o.VMGuestMetrics = vmguestmetrics
+	for i, row := range vmguestmetricsRows {
+		vmguestmetrics[i].decode(d, row)
+	}
+
+	o.VMMetrics = vmmetrics
+	for i, row := range vmmetricsRows {
+		vmmetrics[i].decode(d, row)
+	}
+}
+
+// Bond is an object of the class Bond. A Network bond that combines physical
+// network interfaces, also known as link aggregation
+type Bond struct {
+	Ref string
+
+	// true if the MAC was taken from the primary slave when the bond was created,
+	// and false if the client specified the MAC (auto_update_mac)
+	AutoUpdateMAC bool
+
+	// Number of links up in this bond (links_up)
+	LinksUp int64
+
+	// The bonded interface (master)
+	Master Ref[PIF]
+
+	// The algorithm used to distribute traffic among the bonded NICs (mode)
+	Mode string
+
+	// additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// The PIF of which the IP configuration and MAC were copied to the bond, and
+	// which will receive all configuration/VLANs/VIFs on the bond if the bond is
+	// destroyed (primary_slave)
+	PrimarySlave Ref[PIF]
+
+	// Additional configuration properties specific to the bond mode (properties)
+	Properties map[string]string
+
+	// The interfaces which are part of this bond (slaves)
+	Slaves []Ref[PIF]
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var bondFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"auto_update_mac": true,
+	"links_up":        true,
+	"master":          true,
+	"mode":            true,
+	"other_config":    true,
+	"primary_slave":   true,
+	"properties":      true,
+	"slaves":          true,
+	"uuid":            true,
+}
+
+func (o *Bond) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.AutoUpdateMAC = field(d, row, "auto_update_mac", asBool)
+	o.LinksUp = field(d, row, "links_up", asInt)
+	o.Master = field(d, row, "master", asRef[PIF](d))
+	o.Mode = field(d, row, "mode", asString)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.PrimarySlave = field(d, row, "primary_slave", asRef[PIF](d))
+	o.Properties = field(d, row, "properties", asMap(asString))
+	o.Slaves = field(d, row, "slaves", asSet(asRef[PIF](d)))
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, bondFields)
+}
+
+// GPUGroup is an object of the class GPU_group. A group of compatible GPUs
+// across the resource pool
+type GPUGroup struct {
+	Ref string
+
+	// List of GPU types (vendor+device ID) that can be in this group (GPU_types)
+	GPUTypes []string
+
+	// List of pGPUs in the group (PGPUs)
+	PGPUs []Ref[PGPU]
+
+	// List of vGPUs using the group (VGPUs)
+	VGPUs []Ref[VGPU]
+
+	// Current allocation of vGPUs to pGPUs for this group (allocation_algorithm)
+	AllocationAlgorithm string
+
+	// vGPU types supported on at least one of the pGPUs in this group
+	// (enabled_VGPU_types)
+	EnabledVGPUTypes []Ref[VGPUType]
+
+	// A notes field containing human-readable description (name__description)
+	NameDescription string
+
+	// A human-readable name (name__label)
+	NameLabel string
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// vGPU types supported on at least one of the pGPUs in this group
+	// (supported_VGPU_types)
+	SupportedVGPUTypes []Ref[VGPUType]
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var gpugroupFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"GPU_types":            true,
+	"PGPUs":                true,
+	"VGPUs":                true,
+	"allocation_algorithm": true,
+	"enabled_VGPU_types":   true,
+	"name__description":    true,
+	"name__label":          true,
+	"other_config":         true,
+	"supported_VGPU_types": true,
+	"uuid":                 true,
+}
+
+func (o *GPUGroup) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.GPUTypes = field(d, row, "GPU_types", asSet(asString))
+	o.PGPUs = field(d, row, "PGPUs", asSet(asRef[PGPU](d)))
+	o.VGPUs = field(d, row, "VGPUs", asSet(asRef[VGPU](d)))
+	o.AllocationAlgorithm = field(d, row, "allocation_algorithm", asString)
+	o.EnabledVGPUTypes = field(d, row, "enabled_VGPU_types", asSet(asRef[VGPUType](d)))
+	o.NameDescription = field(d, row, "name__description", asString)
+	o.NameLabel = field(d, row, "name__label", asString)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.SupportedVGPUTypes = field(d, row, "supported_VGPU_types", asSet(asRef[VGPUType](d)))
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, gpugroupFields)
+}
+
+// Host is an object of the class host. A physical host
+type Host struct {
+	Ref string
+
+	// major version number (API_version__major)
+	APIVersionMajor int64
+
+	// minor version number (API_version__minor)
+	APIVersionMinor int64
+
+	// identification of vendor (API_version__vendor)
+	APIVersionVendor string
+
+	// details of vendor implementation (API_version__vendor_implementation)
+	APIVersionVendorImplementation map[string]string
+
+	// physical blockdevices (PBDs)
+	PBDs []Ref[PBD]
+
+	// List of PCI devices in the host (PCIs)
+	PCIs []Ref[PCI]
+
+	// List of physical GPUs in the host (PGPUs)
+	PGPUs []Ref[PGPU]
+
+	// physical network interfaces (PIFs)
+	PIFs []Ref[PIF]
+
+	// List of physical USBs in the host (PUSBs)
+	PUSBs []string
+
+	// The address by which this host can be contacted from any other host in the
+	// pool (address)
+	Address string
+
+	// List of the operations allowed in this state. This list is advisory only
+	// and the server state may have changed by the time this field is read by a
+	// client (allowed_operations)
+	AllowedOperations []string
+
+	// BIOS strings (bios_strings)
+	BiosStrings map[string]string
+
+	// Binary blobs associated with this host (blobs)
+	Blobs map[string]string
+
+	// Xen capabilities (capabilities)
+	Capabilities []string
+
+	// List of certificates installed in the host (certificates)
+	Certificates []string
+
+	// Information about chipset features (chipset_info)
+	ChipsetInfo map[string]string
+
+	// The timeout in seconds after which idle console will be automatically
+	// terminated (0 means never) (console_idle_timeout)
+	ConsoleIdleTimeout int64
+
+	// The control domain (domain 0) (control_domain)
+	ControlDomain Ref[VM]
+
+	// The CPU configuration on this host. May contain keys such as "nr_nodes",
+	// "sockets_per_node", "cores_per_socket", or "threads_per_core"
+	// (cpu_configuration)
+	CPUConfiguration map[string]string
+
+	// Details about the physical CPUs on this host (cpu_info)
+	CPUInfo map[string]string
+
+	// The SR in which VDIs for crash dumps are created (crash_dump_sr)
+	CrashDumpSR Ref[SR]
+
+	// Set of host crash dumps (crashdumps)
+	Crashdumps []string
+
+	// Links each of the running tasks using this object (by reference) to a
+	// current_operation enum which describes the nature of the task
+	// (current_operations)
+	CurrentOperations map[string]string
+
+	// indicates whether the host is configured to output its console to a
+	// physical display device (display)
+	Display string
+
+	// Product edition (edition)
+	Edition string
+
+	// List of all available product editions (editions)
+	Editions []string
+
+	// True if the host is currently enabled (enabled)
+	Enabled bool
+
+	// configuration specific to external authentication service
+	// (external_auth_configuration)
+	ExternalAuthConfiguration map[string]string
+
+	// name of external authentication service configured; empty if none
+	// configured (external_auth_service_name)
+	ExternalAuthServiceName string
+
+	// type of external authentication service configured; empty if none
+	// configured (external_auth_type)
+	ExternalAuthType string
+
+	// List of features available on this host (features)
+	Features []string
+
+	// VCPUs params to apply to all resident guests (guest_VCPUs_params)
+	GuestVCPUsParams map[string]string
+
+	// The set of hosts visible via the network from this host (ha_network_peers)
+	HANetworkPeers []string
+
+	// The set of statefiles accessible from this host (ha_statefiles)
+	HAStatefiles []string
+
+	// The physical CPUs on this host (host_CPUs)
+	HostCPUs []Ref[HostCPU]
+
+	// The hostname of this host (hostname)
+	Hostname string
+
+	// Reflects whether port 80 is open (false) or not (true) (https_only)
+	HTTPSOnly bool
+
+	// The initiator IQN for the host (iscsi_iqn)
+	ISCSIIQN string
+
+	// Date and time when the last software update was applied
+	// (last_software_update)
+	LastSoftwareUpdate time.Time
+
+	// The SHA256 checksum of updateinfo of the most recently applied update on
+	// the host (last_update_hash)
+	LastUpdateHash string
+
+	// Default as 'unknown', 'yes' if the host is up to date with updates synced
+	// from remote CDN, otherwise 'no' (latest_synced_updates_applied)
+	LatestSyncedUpdatesApplied string
+
+	// State of the current license (license_params)
+	LicenseParams map[string]string
+
+	// Contact information of the license server (license_server)
+	LicenseServer map[string]string
+
+	// The SR that is used as a local cache (local_cache_sr)
+	LocalCacheSR Ref[SR]
+
+	// logging configuration (logging)
+	Logging map[string]string
+
+	// Virtualization memory overhead (bytes) (memory__overhead)
+	MemoryOverhead int64
+
+	// metrics associated with this host (metrics)
+	Metrics Ref[HostMetrics]
+
+	// Specifies whether multipathing is enabled (multipathing)
+	Multipathing bool
+
+	// A notes field containing human-readable description (name__description)
+	NameDescription string
+
+	// A human-readable name (name__label)
+	NameLabel string
+
+	// NUMA-aware VM memory and vCPU placement policy (numa_affinity_policy)
+	NUMAAffinityPolicy string
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// Set of host patches (patches)
+	Patches []string
+
+	// The set of pending mandatory guidances after applying updates, which must
+	// be applied, as otherwise there may be e.g. VM failures (pending_guidances)
+	PendingGuidances []string
+
+	// The set of pending full guidances after applying updates, which a user
+	// should follow to make some updates, e.g. specific hardware drivers or CPU
+	// features, fully effective, but the 'average user' doesn't need to
+	// (pending_guidances_full)
+	PendingGuidancesFull []string
+
+	// The set of pending recommended guidances after applying updates, which most
+	// users should follow to make the updates effective, but if not followed,
+	// will not cause a failure (pending_guidances_recommended)
+	PendingGuidancesRecommended []string
+
+	// The power on config (power_on_config)
+	PowerOnConfig map[string]string
+
+	// The power on mode (power_on_mode)
+	PowerOnMode string
+
+	// list of VMs currently resident on host (resident_VMs)
+	ResidentVMs []Ref[VM]
+
+	// Scheduler policy currently in force on this host (sched_policy)
+	SchedPolicy string
+
+	// version strings (software_version)
+	SoftwareVersion map[string]string
+
+	// True if SSH access is enabled for the host (ssh_enabled)
+	SSHEnabled bool
+
+	// The timeout in seconds after which SSH access will be automatically
+	// disabled (0 means never) (ssh_enabled_timeout)
+	SSHEnabledTimeout int64
+
+	// The time in UTC after which the SSH access will be automatically disabled
+	// (ssh_expiry)
+	SSHExpiry time.Time
+
+	// Allow SSLv3 protocol and ciphersuites as used by older server versions.
+	// This controls both incoming and outgoing connections. When this is set to a
+	// different value, the host immediately restarts its SSL/TLS listening
+	// service; typically this takes less than a second but existing connections
+	// to it will be broken. API login sessions will remain valid (ssl_legacy)
+	SSLLegacy bool
+
+	// a list of the bootloaders installed on the machine (supported_bootloaders)
+	SupportedBootloaders []string
+
+	// The SR in which VDIs for suspend images are created (suspend_image_sr)
+	SuspendImageSR Ref[SR]
+
+	// user-specified tags for categorization purposes (tags)
+	Tags []string
+
+	// True if this host has TLS verifcation enabled (tls_verification_enabled)
+	TLSVerificationEnabled bool
+
+	// The UEFI certificates allowing Secure Boot (uefi_certificates)
+	UEFICertificates string
+
+	// Set of updates (updates)
+	Updates []string
+
+	// List of updates which require reboot (updates_requiring_reboot)
+	UpdatesRequiringReboot []string
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// The set of versions of the virtual hardware platform that the host can
+	// offer to its guests (virtual_hardware_platform_versions)
+	VirtualHardwarePlatformVersions []int64
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var hostFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"API_version__major":                 true,
+	"API_version__minor":                 true,
+	"API_version__vendor":                true,
+	"API_version__vendor_implementation": true,
+	"PBDs":                               true,
+	"PCIs":                               true,
+	"PGPUs":                              true,
+	"PIFs":                               true,
+	"PUSBs":                              true,
+	"address":                            true,
+	"allowed_operations":                 true,
+	"bios_strings":                       true,
+	"blobs":                              true,
+	"capabilities":                       true,
+	"certificates":                       true,
+	"chipset_info":                       true,
+	"console_idle_timeout":               true,
+	"control_domain":                     true,
+	"cpu_configuration":                  true,
+	"cpu_info":                           true,
+	"crash_dump_sr":                      true,
+	"crashdumps":                         true,
+	"current_operations":                 true,
+	"display":                            true,
+	"edition":                            true,
+	"editions":                           true,
+	"enabled":                            true,
+	"external_auth_configuration":        true,
+	"external_auth_service_name":         true,
+	"external_auth_type":                 true,
+	"features":                           true,
+	"guest_VCPUs_params":                 true,
+	"ha_network_peers":                   true,
+	"ha_statefiles":                      true,
+	"host_CPUs":                          true,
+	"hostname":                           true,
+	"https_only":                         true,
+	"iscsi_iqn":                          true,
+	"last_software_update":               true,
+	"last_update_hash":                   true,
+	"latest_synced_updates_applied":      true,
+	"license_params":                     true,
+	"license_server":                     true,
+	"local_cache_sr":                     true,
+	"logging":                            true,
+	"memory__overhead":                   true,
+	"metrics":                            true,
+	"multipathing":                       true,
+	"name__description":                  true,
+	"name__label":                        true,
+	"numa_affinity_policy":               true,
+	"other_config":                       true,
+	"patches":                            true,
+	"pending_guidances":                  true,
+	"pending_guidances_full":             true,
+	"pending_guidances_recommended":      true,
+	"power_on_config":                    true,
+	"power_on_mode":                      true,
+	"resident_VMs":                       true,
+	"sched_policy":                       true,
+	"software_version":                   true,
+	"ssh_enabled":                        true,
+	"ssh_enabled_timeout":                true,
+	"ssh_expiry":                         true,
+	"ssl_legacy":                         true,
+	"supported_bootloaders":              true,
+	"suspend_image_sr":                   true,
+	"tags":                               true,
+	"tls_verification_enabled":           true,
+	"uefi_certificates":                  true,
+	"updates":                            true,
+	"updates_requiring_reboot":           true,
+	"uuid":                               true,
+	"virtual_hardware_platform_versions": true,
+}
+
+func (o *Host) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.APIVersionMajor = field(d, row, "API_version__major", asInt)
+	o.APIVersionMinor = field(d, row, "API_version__minor", asInt)
+	o.APIVersionVendor = field(d, row, "API_version__vendor", asString)
+	o.APIVersionVendorImplementation = field(d, row, "API_version__vendor_implementation", asMap(asString))
+	o.PBDs = field(d, row, "PBDs", asSet(asRef[PBD](d)))
+	o.PCIs = field(d, row, "PCIs", asSet(asRef[PCI](d)))
+	o.PGPUs = field(d, row, "PGPUs", asSet(asRef[PGPU](d)))
+	o.PIFs = field(d, row, "PIFs", asSet(asRef[PIF](d)))
+	o.PUSBs = field(d, row, "PUSBs", asSet(asString))
+	o.Address = field(d, row, "address", asString)
+	o.AllowedOperations = field(d, row, "allowed_operations", asSet(asString))
+	o.BiosStrings = field(d, row, "bios_strings", asMap(asString))
+	o.Blobs = field(d, row, "blobs", asMap(asString))
+	o.Capabilities = field(d, row, "capabilities", asSet(asString))
+	o.Certificates = field(d, row, "certificates", asSet(asString))
+	o.ChipsetInfo = field(d, row, "chipset_info", asMap(asString))
+	o.ConsoleIdleTimeout = field(d, row, "console_idle_timeout", asInt)
+	o.ControlDomain = field(d, row, "control_domain", asRef[VM](d))
+	o.CPUConfiguration = field(d, row, "cpu_configuration", asMap(asString))
+	o.CPUInfo = field(d, row, "cpu_info", asMap(asString))
+	o.CrashDumpSR = field(d, row, "crash_dump_sr", asRef[SR](d))
+	o.Crashdumps = field(d, row, "crashdumps", asSet(asString))
+	o.CurrentOperations = field(d, row, "current_operations", asMap(asString))
+	o.Display = field(d, row, "display", asString)
+	o.Edition = field(d, row, "edition", asString)
+	o.Editions = field(d, row, "editions", asSet(asString))
+	o.Enabled = field(d, row, "enabled", asBool)
+	o.ExternalAuthConfiguration = field(d, row, "external_auth_configuration", asMap(asString))
+	o.ExternalAuthServiceName = field(d, row, "external_auth_service_name", asString)
+	o.ExternalAuthType = field(d, row, "external_auth_type", asString)
+	o.Features = field(d, row, "features", asSet(asString))
+	o.GuestVCPUsParams = field(d, row, "guest_VCPUs_params", asMap(asString))
+	o.HANetworkPeers = field(d, row, "ha_network_peers", asSet(asString))
+	o.HAStatefiles = field(d, row, "ha_statefiles", asSet(asString))
+	o.HostCPUs = field(d, row, "host_CPUs", asSet(asRef[HostCPU](d)))
+	o.Hostname = field(d, row, "hostname", asString)
+	o.HTTPSOnly = field(d, row, "https_only", asBool)
+	o.ISCSIIQN = field(d, row, "iscsi_iqn", asString)
+	o.LastSoftwareUpdate = field(d, row, "last_software_update", asTime)
+	o.LastUpdateHash = field(d, row, "last_update_hash", asString)
+	o.LatestSyncedUpdatesApplied = field(d, row, "latest_synced_updates_applied", asString)
+	o.LicenseParams = field(d, row, "license_params", asMap(asString))
+	o.LicenseServer = field(d, row, "license_server", asMap(asString))
+	o.LocalCacheSR = field(d, row, "local_cache_sr", asRef[SR](d))
+	o.Logging = field(d, row, "logging", asMap(asString))
+	o.MemoryOverhead = field(d, row, "memory__overhead", asInt)
+	o.Metrics = field(d, row, "metrics", asRef[HostMetrics](d))
+	o.Multipathing = field(d, row, "multipathing", asBool)
+	o.NameDescription = field(d, row, "name__description", asString)
+	o.NameLabel = field(d, row, "name__label", asString)
+	o.NUMAAffinityPolicy = field(d, row, "numa_affinity_policy", asString)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.Patches = field(d, row, "patches", asSet(asString))
+	o.PendingGuidances = field(d, row, "pending_guidances", asSet(asString))
+	o.PendingGuidancesFull = field(d, row, "pending_guidances_full", asSet(asString))
+	o.PendingGuidancesRecommended = field(d, row, "pending_guidances_recommended", asSet(asString))
+	o.PowerOnConfig = field(d, row, "power_on_config", asMap(asString))
+	o.PowerOnMode = field(d, row, "power_on_mode", asString)
+	o.ResidentVMs = field(d, row, "resident_VMs", asSet(asRef[VM](d)))
+	o.SchedPolicy = field(d, row, "sched_policy", asString)
+	o.SoftwareVersion = field(d, row, "software_version", asMap(asString))
+	o.SSHEnabled = field(d, row, "ssh_enabled", asBool)
+	o.SSHEnabledTimeout = field(d, row, "ssh_enabled_timeout", asInt)
+	o.SSHExpiry = field(d, row, "ssh_expiry", asTime)
+	o.SSLLegacy = field(d, row, "ssl_legacy", asBool)
+	o.SupportedBootloaders = field(d, row, "supported_bootloaders", asSet(asString))
+	o.SuspendImageSR = field(d, row, "suspend_image_sr", asRef[SR](d))
+	o.Tags = field(d, row, "tags", asSet(asString))
+	o.TLSVerificationEnabled = field(d, row, "tls_verification_enabled", asBool)
+	o.UEFICertificates = field(d, row, "uefi_certificates", asString)
+	o.Updates = field(d, row, "updates", asSet(asString))
+	o.UpdatesRequiringReboot = field(d, row, "updates_requiring_reboot", asSet(asString))
+	o.UUID = field(d, row, "uuid", asString)
+	o.VirtualHardwarePlatformVersions = field(d, row, "virtual_hardware_platform_versions", asSet(asInt))
+	o.Extras = extras(row, hostFields)
+}
+
+// HostCPU is an object of the class host_cpu. A physical CPU
+type HostCPU struct {
+	Ref string
+
+	// the family (number) of the physical CPU (family)
+	Family int64
+
+	// the physical CPU feature bitmap (features)
+	Features string
+
+	// the flags of the physical CPU (a decoded version of the features field)
+	// (flags)
+	Flags string
+
+	// the host the CPU is in (host)
+	Host Ref[Host]
+
+	// the model number of the physical CPU (model)
+	Model int64
+
+	// the model name of the physical CPU (modelname)
+	Modelname string
+
+	// the number of the physical CPU within the host (number)
+	Number int64
+
+	// additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// the speed of the physical CPU (speed)
+	Speed int64
+
+	// the stepping of the physical CPU (stepping)
+	Stepping string
+
+	// the current CPU utilisation (utilisation)
+	Utilisation float64
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// the vendor of the physical CPU (vendor)
+	Vendor string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var hostcpuFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"family":       true,
+	"features":     true,
+	"flags":        true,
+	"host":         true,
+	"model":        true,
+	"modelname":    true,
+	"number":       true,
+	"other_config": true,
+	"speed":        true,
+	"stepping":     true,
+	"utilisation":  true,
+	"uuid":         true,
+	"vendor":       true,
+}
+
+func (o *HostCPU) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.Family = field(d, row, "family", asInt)
+	o.Features = field(d, row, "features", asString)
+	o.Flags = field(d, row, "flags", asString)
+	o.Host = field(d, row, "host", asRef[Host](d))
+	o.Model = field(d, row, "model", asInt)
+	o.Modelname = field(d, row, "modelname", asString)
+	o.Number = field(d, row, "number", asInt)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.Speed = field(d, row, "speed", asInt)
+	o.Stepping = field(d, row, "stepping", asString)
+	o.Utilisation = field(d, row, "utilisation", asFloat)
+	o.UUID = field(d, row, "uuid", asString)
+	o.Vendor = field(d, row, "vendor", asString)
+	o.Extras = extras(row, hostcpuFields)
+}
+
+// HostMetrics is an object of the class host_metrics. The metrics associated
+// with a host
+type HostMetrics struct {
+	Ref string
+
+	// Time at which this information was last updated (last_updated)
+	LastUpdated time.Time
+
+	// Pool master thinks this host is live (live)
+	Live bool
+
+	// Free host memory (bytes) (memory__free)
+	MemoryFree int64
+
+	// Total host memory (bytes) (memory__total)
+	MemoryTotal int64
+
+	// additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var hostmetricsFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"last_updated":  true,
+	"live":          true,
+	"memory__free":  true,
+	"memory__total": true,
+	"other_config":  true,
+	"uuid":          true,
+}
+
+func (o *HostMetrics) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.LastUpdated = field(d, row, "last_updated", asTime)
+	o.Live = field(d, row, "live", asBool)
+	o.MemoryFree = field(d, row, "memory__free", asInt)
+	o.MemoryTotal = field(d, row, "memory__total", asInt)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, hostmetricsFields)
+}
+
+// Message is an object of the class message. An message for the attention of
+// the administrator
+type Message struct {
+	Ref string
+
+	// The body of the message (body)
+	Body string
+
+	// The class of the object this message is associated with (cls)
+	Cls string
+
+	// The name of the message (name)
+	Name string
+
+	// The uuid of the object this message is associated with (obj_uuid)
+	ObjUUID string
+
+	// The message priority, 0 being low priority (priority)
+	Priority int64
+
+	// The time at which the message was created (timestamp)
+	Timestamp time.Time
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var messageFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"body":      true,
+	"cls":       true,
+	"name":      true,
+	"obj_uuid":  true,
+	"priority":  true,
+	"timestamp": true,
+	"uuid":      true,
+}
+
+func (o *Message) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.Body = field(d, row, "body", asString)
+	o.Cls = field(d, row, "cls", asString)
+	o.Name = field(d, row, "name", asString)
+	o.ObjUUID = field(d, row, "obj_uuid", asString)
+	o.Priority = field(d, row, "priority", asInt)
+	o.Timestamp = field(d, row, "timestamp", asTime)
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, messageFields)
+}
+
+// Network is an object of the class network. A virtual network
+type Network struct {
+	Ref string
+
+	// MTU in octets (MTU)
+	MTU int64
+
+	// list of connected pifs (PIFs)
+	PIFs []Ref[PIF]
+
+	// list of connected vifs (VIFs)
+	VIFs []Ref[VIF]
+
+	// List of the operations allowed in this state. This list is advisory only
+	// and the server state may have changed by the time this field is read by a
+	// client (allowed_operations)
+	AllowedOperations []string
+
+	// The IP addresses assigned to VIFs on networks that have active xapi-managed
+	// DHCP servers (assigned_ips)
+	AssignedIPs map[string]string
+
+	// Binary blobs associated with this network (blobs)
+	Blobs map[string]string
+
+	// name of the bridge corresponding to this network on the local host (bridge)
+	Bridge string
+
+	// Links each of the running tasks using this object (by reference) to a
+	// current_operation enum which describes the nature of the task
+	// (current_operations)
+	CurrentOperations map[string]string
+
+	// The network will use this value to determine the behaviour of all VIFs
+	// where locking_mode = default (default_locking_mode)
+	DefaultLockingMode string
+
+	// true if the bridge is managed by xapi (managed)
+	Managed bool
+
+	// A notes field containing human-readable description (name__description)
+	NameDescription string
+
+	// A human-readable name (name__label)
+	NameLabel string
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// Set of purposes for which the server will use this network (purpose)
+	Purpose []string
+
+	// user-specified tags for categorization purposes (tags)
+	Tags []string
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var networkFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"MTU":                  true,
+	"PIFs":                 true,
+	"VIFs":                 true,
+	"allowed_operations":   true,
+	"assigned_ips":         true,
+	"blobs":                true,
+	"bridge":               true,
+	"current_operations":   true,
+	"default_locking_mode": true,
+	"managed":              true,
+	"name__description":    true,
+	"name__label":          true,
+	"other_config":         true,
+	"purpose":              true,
+	"tags":                 true,
+	"uuid":                 true,
+}
+
+func (o *Network) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.MTU = field(d, row, "MTU", asInt)
+	o.PIFs = field(d, row, "PIFs", asSet(asRef[PIF](d)))
+	o.VIFs = field(d, row, "VIFs", asSet(asRef[VIF](d)))
+	o.AllowedOperations = field(d, row, "allowed_operations", asSet(asString))
+	o.AssignedIPs = field(d, row, "assigned_ips", asMap(asString))
+	o.Blobs = field(d, row, "blobs", asMap(asString))
+	o.Bridge = field(d, row, "bridge", asString)
+	o.CurrentOperations = field(d, row, "current_operations", asMap(asString))
+	o.DefaultLockingMode = field(d, row, "default_locking_mode", asString)
+	o.Managed = field(d, row, "managed", asBool)
+	o.NameDescription = field(d, row, "name__description", asString)
+	o.NameLabel = field(d, row, "name__label", asString)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.Purpose = field(d, row, "purpose", asSet(asString))
+	o.Tags = field(d, row, "tags", asSet(asString))
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, networkFields)
+}
+
+// PBD is an object of the class PBD. The physical block devices through which
+// hosts access SRs
+type PBD struct {
+	Ref string
+
+	// the storage repository that the pbd realises (SR)
+	SR Ref[SR]
+
+	// is the SR currently attached on this host? (currently_attached)
+	CurrentlyAttached bool
+
+	// a config string to string map that is provided to the host's
+	// SR-backend-driver (device_config)
+	DeviceConfig map[string]string
+
+	// physical machine on which the pbd is available (host)
+	Host Ref[Host]
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var pbdFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"SR":                 true,
+	"currently_attached": true,
+	"device_config":      true,
+	"host":               true,
+	"other_config":       true,
+	"uuid":               true,
+}
+
+func (o *PBD) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.SR = field(d, row, "SR", asRef[SR](d))
+	o.CurrentlyAttached = field(d, row, "currently_attached", asBool)
+	o.DeviceConfig = field(d, row, "device_config", asMap(asString))
+	o.Host = field(d, row, "host", asRef[Host](d))
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, pbdFields)
+}
+
+// PCI is an object of the class PCI. A PCI device
+type PCI struct {
+	Ref string
+
+	// PCI class name (class_name)
+	ClassName string
+
+	// List of dependent PCI devices (dependencies)
+	Dependencies []Ref[PCI]
+
+	// Device name (device_name)
+	DeviceName string
+
+	// Driver name (driver_name)
+	DriverName string
+
+	// Physical machine that owns the PCI device (host)
+	Host Ref[Host]
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// PCI ID of the physical device (pci_id)
+	PCIID string
+
+	// The VM to which this PCI device is scheduled to be attached (passed
+	// through) (scheduled_to_be_attached_to)
+	ScheduledToBeAttachedTo Ref[VM]
+
+	// Subsystem device name (subsystem_device_name)
+	SubsystemDeviceName string
+
+	// Subsystem vendor name (subsystem_vendor_name)
+	SubsystemVendorName string
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Vendor name (vendor_name)
+	VendorName string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var pciFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"class_name":                  true,
+	"dependencies":                true,
+	"device_name":                 true,
+	"driver_name":                 true,
+	"host":                        true,
+	"other_config":                true,
+	"pci_id":                      true,
+	"scheduled_to_be_attached_to": true,
+	"subsystem_device_name":       true,
+	"subsystem_vendor_name":       true,
+	"uuid":                        true,
+	"vendor_name":                 true,
+}
+
+func (o *PCI) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.ClassName = field(d, row, "class_name", asString)
+	o.Dependencies = field(d, row, "dependencies", asSet(asRef[PCI](d)))
+	o.DeviceName = field(d, row, "device_name", asString)
+	o.DriverName = field(d, row, "driver_name", asString)
+	o.Host = field(d, row, "host", asRef[Host](d))
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.PCIID = field(d, row, "pci_id", asString)
+	o.ScheduledToBeAttachedTo = field(d, row, "scheduled_to_be_attached_to", asRef[VM](d))
+	o.SubsystemDeviceName = field(d, row, "subsystem_device_name", asString)
+	o.SubsystemVendorName = field(d, row, "subsystem_vendor_name", asString)
+	o.UUID = field(d, row, "uuid", asString)
+	o.VendorName = field(d, row, "vendor_name", asString)
+	o.Extras = extras(row, pciFields)
+}
+
+// PGPU is an object of the class PGPU. A physical GPU (pGPU)
+type PGPU struct {
+	Ref string
+
+	// GPU group the pGPU is contained in (GPU_group)
+	GPUGroup Ref[GPUGroup]
+
+	// Link to underlying PCI device (PCI)
+	PCI Ref[PCI]
+
+	// PGPU metadata to determine whether a VGPU can migrate between two PGPUs
+	// (compatibility_metadata)
+	CompatibilityMetadata map[string]string
+
+	// The accessibility of this device from dom0 (dom0_access)
+	Dom0Access string
+
+	// List of VGPU types which have been enabled for this PGPU
+	// (enabled_VGPU_types)
+	EnabledVGPUTypes []Ref[VGPUType]
+
+	// Host that owns the GPU (host)
+	Host Ref[Host]
+
+	// Is this device the system display device (is_system_display_device)
+	IsSystemDisplayDevice bool
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// List of VGPUs running on this PGPU (resident_VGPUs)
+	ResidentVGPUs []Ref[VGPU]
+
+	// A map relating each VGPU type supported on this GPU to the maximum number
+	// of VGPUs of that type which can run simultaneously on this GPU
+	// (supported_VGPU_max_capacities)
+	SupportedVGPUMaxCapacities map[string]int64
+
+	// List of VGPU types supported by the underlying hardware
+	// (supported_VGPU_types)
+	SupportedVGPUTypes []Ref[VGPUType]
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var pgpuFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"GPU_group":                     true,
+	"PCI":                           true,
+	"compatibility_metadata":        true,
+	"dom0_access":                   true,
+	"enabled_VGPU_types":            true,
+	"host":                          true,
+	"is_system_display_device":      true,
+	"other_config":                  true,
+	"resident_VGPUs":                true,
+	"supported_VGPU_max_capacities": true,
+	"supported_VGPU_types":          true,
+	"uuid":                          true,
+}
+
+func (o *PGPU) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.GPUGroup = field(d, row, "GPU_group", asRef[GPUGroup](d))
+	o.PCI = field(d, row, "PCI", asRef[PCI](d))
+	o.CompatibilityMetadata = field(d, row, "compatibility_metadata", asMap(asString))
+	o.Dom0Access = field(d, row, "dom0_access", asString)
+	o.EnabledVGPUTypes = field(d, row, "enabled_VGPU_types", asSet(asRef[VGPUType](d)))
+	o.Host = field(d, row, "host", asRef[Host](d))
+	o.IsSystemDisplayDevice = field(d, row, "is_system_display_device", asBool)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.ResidentVGPUs = field(d, row, "resident_VGPUs", asSet(asRef[VGPU](d)))
+	o.SupportedVGPUMaxCapacities = field(d, row, "supported_VGPU_max_capacities", asMap(asInt))
+	o.SupportedVGPUTypes = field(d, row, "supported_VGPU_types", asSet(asRef[VGPUType](d)))
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, pgpuFields)
+}
+
+// PIF is an object of the class PIF. A physical network interface (note
+// separate VLANs are represented as several PIFs)
+type PIF struct {
+	Ref string
+
+	// Comma separated list of the IP addresses of the DNS servers to use (DNS)
+	DNS string
+
+	// IP address (IP)
+	IP string
+
+	// IPv6 address (IPv6)
+	IPv6 []string
+
+	// ethernet MAC address of physical interface (MAC)
+	MAC string
+
+	// MTU in octets (MTU)
+	MTU int64
+
+	// Link to underlying PCI device (PCI)
+	PCI Ref[PCI]
+
+	// VLAN tag for all traffic passing through this interface (VLAN)
+	VLAN int64
+
+	// Indicates wich VLAN this interface receives untagged traffic from
+	// (VLAN_master_of)
+	VLANMasterOf Ref[VLAN]
+
+	// Indicates which VLANs this interface transmits tagged traffic to
+	// (VLAN_slave_of)
+	VLANSlaveOf []Ref[VLAN]
+
+	// Indicates this PIF represents the results of a bond (bond_master_of)
+	BondMasterOf []Ref[Bond]
+
+	// Indicates which bond this interface is part of (bond_slave_of)
+	BondSlaveOf Ref[Bond]
+
+	// Additional capabilities on the interface (capabilities)
+	Capabilities []string
+
+	// true if this interface is online (currently_attached)
+	CurrentlyAttached bool
+
+	// machine-readable name of the interface (e.g. eth0) (device)
+	Device string
+
+	// Prevent this PIF from being unplugged; set this to notify the management
+	// tool-stack that the PIF has a special use and should not be unplugged under
+	// any circumstances (e.g. because you're running storage traffic over it)
+	// (disallow_unplug)
+	DisallowUnplug bool
+
+	// IP gateway (gateway)
+	Gateway string
+
+	// physical machine to which this pif is connected (host)
+	Host Ref[Host]
+
+	// The IGMP snooping status of the corresponding network bridge
+	// (igmp_snooping_status)
+	IgmpSnoopingStatus string
+
+	// Sets if and how this interface gets an IP address (ip_configuration_mode)
+	IPConfigurationMode string
+
+	// Sets if and how this interface gets an IPv6 address
+	// (ipv6_configuration_mode)
+	IPv6ConfigurationMode string
+
+	// IPv6 gateway (ipv6_gateway)
+	IPv6Gateway string
+
+	// Indicates whether the interface is managed by xapi. If it is not, then xapi
+	// will not configure the interface, the commands
+	// PIF.plug/unplug/reconfigure_ip(v6) cannot be used, nor can the interface be
+	// bonded or have VLANs based on top through xapi (managed)
+	Managed bool
+
+	// Indicates whether the control software is listening for connections on this
+	// interface (management)
+	Management bool
+
+	// metrics associated with this PIF (metrics)
+	Metrics string
+
+	// IP netmask (netmask)
+	Netmask string
+
+	// virtual network to which this pif is connected (network)
+	Network Ref[Network]
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// true if this represents a physical network interface (physical)
+	Physical bool
+
+	// Which protocol should define the primary address of this interface
+	// (primary_address_type)
+	PrimaryAddressType string
+
+	// Additional configuration properties for the interface (properties)
+	Properties map[string]string
+
+	// Indicates which network_sriov this interface is logical of
+	// (sriov_logical_PIF_of)
+	SRIOVLogicalPIFOf []string
+
+	// Indicates which network_sriov this interface is physical of
+	// (sriov_physical_PIF_of)
+	SRIOVPhysicalPIFOf []string
+
+	// Indicates to which tunnel this PIF gives access (tunnel_access_PIF_of)
+	TunnelAccessPIFOf []Ref[Tunnel]
+
+	// Indicates to which tunnel this PIF provides transport
+	// (tunnel_transport_PIF_of)
+	TunnelTransportPIFOf []Ref[Tunnel]
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var pifFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"DNS":                     true,
+	"IP":                      true,
+	"IPv6":                    true,
+	"MAC":                     true,
+	"MTU":                     true,
+	"PCI":                     true,
+	"VLAN":                    true,
+	"VLAN_master_of":          true,
+	"VLAN_slave_of":           true,
+	"bond_master_of":          true,
+	"bond_slave_of":           true,
+	"capabilities":            true,
+	"currently_attached":      true,
+	"device":                  true,
+	"disallow_unplug":         true,
+	"gateway":                 true,
+	"host":                    true,
+	"igmp_snooping_status":    true,
+	"ip_configuration_mode":   true,
+	"ipv6_configuration_mode": true,
+	"ipv6_gateway":            true,
+	"managed":                 true,
+	"management":              true,
+	"metrics":                 true,
+	"netmask":                 true,
+	"network":                 true,
+	"other_config":            true,
+	"physical":                true,
+	"primary_address_type":    true,
+	"properties":              true,
+	"sriov_logical_PIF_of":    true,
+	"sriov_physical_PIF_of":   true,
+	"tunnel_access_PIF_of":    true,
+	"tunnel_transport_PIF_of": true,
+	"uuid":                    true,
+}
+
+func (o *PIF) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.DNS = field(d, row, "DNS", asString)
+	o.IP = field(d, row, "IP", asString)
+	o.IPv6 = field(d, row, "IPv6", asSet(asString))
+	o.MAC = field(d, row, "MAC", asString)
+	o.MTU = field(d, row, "MTU", asInt)
+	o.PCI = field(d, row, "PCI", asRef[PCI](d))
+	o.VLAN = field(d, row, "VLAN", asInt)
+	o.VLANMasterOf = field(d, row, "VLAN_master_of", asRef[VLAN](d))
+	o.VLANSlaveOf = field(d, row, "VLAN_slave_of", asSet(asRef[VLAN](d)))
+	o.BondMasterOf = field(d, row, "bond_master_of", asSet(asRef[Bond](d)))
+	o.BondSlaveOf = field(d, row, "bond_slave_of", asRef[Bond](d))
+	o.Capabilities = field(d, row, "capabilities", asSet(asString))
+	o.CurrentlyAttached = field(d, row, "currently_attached", asBool)
+	o.Device = field(d, row, "device", asString)
+	o.DisallowUnplug = field(d, row, "disallow_unplug", asBool)
+	o.Gateway = field(d, row, "gateway", asString)
+	o.Host = field(d, row, "host", asRef[Host](d))
+	o.IgmpSnoopingStatus = field(d, row, "igmp_snooping_status", asString)
+	o.IPConfigurationMode = field(d, row, "ip_configuration_mode", asString)
+	o.IPv6ConfigurationMode = field(d, row, "ipv6_configuration_mode", asString)
+	o.IPv6Gateway = field(d, row, "ipv6_gateway", asString)
+	o.Managed = field(d, row, "managed", asBool)
+	o.Management = field(d, row, "management", asBool)
+	o.Metrics = field(d, row, "metrics", asString)
+	o.Netmask = field(d, row, "netmask", asString)
+	o.Network = field(d, row, "network", asRef[Network](d))
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.Physical = field(d, row, "physical", asBool)
+	o.PrimaryAddressType = field(d, row, "primary_address_type", asString)
+	o.Properties = field(d, row, "properties", asMap(asString))
+	o.SRIOVLogicalPIFOf = field(d, row, "sriov_logical_PIF_of", asSet(asString))
+	o.SRIOVPhysicalPIFOf = field(d, row, "sriov_physical_PIF_of", asSet(asString))
+	o.TunnelAccessPIFOf = field(d, row, "tunnel_access_PIF_of", asSet(asRef[Tunnel](d)))
+	o.TunnelTransportPIFOf = field(d, row, "tunnel_transport_PIF_of", asSet(asRef[Tunnel](d)))
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, pifFields)
+}
+
+// Pool is an object of the class pool. Pool-wide information
+type Pool struct {
+	Ref string
+
+	// List of the operations allowed in this state. This list is advisory only
+	// and the server state may have changed by the time this field is read by a
+	// client (allowed_operations)
+	AllowedOperations []string
+
+	// Binary blobs associated with this pool (blobs)
+	Blobs map[string]string
+
+	// True if authentication by TLS client certificates is enabled
+	// (client_certificate_auth_enabled)
+	ClientCertificateAuthEnabled bool
+
+	// The name (CN/SAN) that an incoming client certificate must have to allow
+	// authentication (client_certificate_auth_name)
+	ClientCertificateAuthName string
+
+	// true if bias against pool master when scheduling vms is enabled, false
+	// otherwise (coordinator_bias)
+	CoordinatorBias bool
+
+	// Details about the physical CPUs on the pool (cpu_info)
+	CPUInfo map[string]string
+
+	// The SR in which VDIs for crash dumps are created (crash_dump_SR)
+	CrashDumpSR Ref[SR]
+
+	// Links each of the running tasks using this object (by reference) to a
+	// current_operation enum which describes the nature of the task
+	// (current_operations)
+	CurrentOperations map[string]string
+
+	// Default SR for VDIs (default_SR)
+	DefaultSR Ref[SR]
+
+	// Pool-wide guest agent configuration information (guest_agent_config)
+	GuestAgentConfig map[string]string
+
+	// gui-specific configuration for pool (gui_config)
+	GuiConfig map[string]string
+
+	// If set to false then operations which would cause the Pool to become
+	// overcommitted will be blocked (ha_allow_overcommit)
+	HAAllowOvercommit bool
+
+	// The HA cluster stack that is currently in use. Only valid when HA is
+	// enabled (ha_cluster_stack)
+	HAClusterStack string
+
+	// The current HA configuration (ha_configuration)
+	HAConfiguration map[string]string
+
+	// true if HA is enabled on the pool, false otherwise (ha_enabled)
+	HAEnabled bool
+
+	// Number of host failures to tolerate before the Pool is declared to be
+	// overcommitted (ha_host_failures_to_tolerate)
+	HAHostFailuresToTolerate int64
+
+	// True if the Pool is considered to be overcommitted i.e. if there exist
+	// insufficient physical resources to tolerate the configured number of host
+	// failures (ha_overcommitted)
+	HAOvercommitted bool
+
+	// Number of future host failures we have managed to find a plan for. Once
+	// this reaches zero any future host failures will cause the failure of
+	// protected VMs (ha_plan_exists_for)
+	HAPlanExistsFor int64
+
+	// Indicates whether an HA-protected VM that is shut down from inside (not
+	// through the API) should be automatically rebooted when HA is enabled
+	// (ha_reboot_vm_on_internal_shutdown)
+	HARebootVMOnInternalShutdown bool
+
+	// HA statefile VDIs in use (ha_statefiles)
+	HAStatefiles []string
+
+	// Configuration for the automatic health check feature (health_check_config)
+	HealthCheckConfig map[string]string
+
+	// true if IGMP snooping is enabled in the pool, false otherwise
+	// (igmp_snooping_enabled)
+	IgmpSnoopingEnabled bool
+
+	// True iff the pool pre-shared key rotation is pending (is_psr_pending)
+	IsPsrPending bool
+
+	// time of the last update sychronization (last_update_sync)
+	LastUpdateSync time.Time
+
+	// Licensing data shared within the whole pool (license_server)
+	LicenseServer map[string]string
+
+	// The pool-wide flag to show if the live patching feauture is disabled or not
+	// (live_patching_disabled)
+	LivePatchingDisabled bool
+
+	// The host that is pool master (master)
+	Master Ref[Host]
+
+	// The set of currently known metadata VDIs for this pool (metadata_VDIs)
+	MetadataVDIs []Ref[VDI]
+
+	// Default behaviour during migration, True if stream compression should be
+	// used (migration_compression)
+	MigrationCompression bool
+
+	// A notes field containing human-readable description (name__description)
+	NameDescription string
+
+	// A human-readable name (name__label)
+	NameLabel string
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// The pool-wide policy for clients on whether to use the vendor device or not
+	// on newly created VMs. This field will also be consulted if the
+	// 'has_vendor_device' field is not specified in the VM.create call
+	// (policy_no_vendor_device)
+	PolicyNoVendorDevice bool
+
+	// The recommended pool properties for clients to respect for optimal
+	// performance. e.g. max-vm-group=5 (recommendations)
+	Recommendations map[string]string
+
+	// true a redo-log is to be used other than when HA is enabled, false
+	// otherwise (redo_log_enabled)
+	RedoLogEnabled bool
+
+	// indicates the VDI to use for the redo-log other than when HA is enabled
+	// (redo_log_vdi)
+	RedoLogVDI Ref[VDI]
+
+	// The set of currently enabled repositories (repositories)
+	Repositories []string
+
+	// Password for the authentication of the proxy used in syncing with the
+	// enabled repositories (repository_proxy_password)
+	RepositoryProxyPassword string
+
+	// Url of the proxy used in syncing with the enabled repositories
+	// (repository_proxy_url)
+	RepositoryProxyURL string
+
+	// Username for the authentication of the proxy used in syncing with the
+	// enabled repositories (repository_proxy_username)
+	RepositoryProxyUsername string
+
+	// Pool-wide restrictions currently in effect (restrictions)
+	Restrictions map[string]string
+
+	// The SR in which VDIs for suspend images are created (suspend_image_SR)
+	SuspendImageSR Ref[SR]
+
+	// user-specified tags for categorization purposes (tags)
+	Tags []string
+
+	// How often the telemetry collection will be carried out
+	// (telemetry_frequency)
+	TelemetryFrequency string
+
+	// The earliest timestamp (in UTC) when the next round of telemetry collection
+	// can be carried out (telemetry_next_collection)
+	TelemetryNextCollection time.Time
+
+	// The UUID of the pool for identification of telemetry data (telemetry_uuid)
+	TelemetryUUID string
+
+	// True iff TLS certificate verification is enabled (tls_verification_enabled)
+	TLSVerificationEnabled bool
+
+	// The UEFI certificates allowing Secure Boot (uefi_certificates)
+	UEFICertificates string
+
+	// The day of the week the update synchronization will happen, based on pool's
+	// local timezone. Valid values are 0 to 6, 0 being Sunday. For 'daily'
+	// schedule, the value is ignored (update_sync_day)
+	UpdateSyncDay int64
+
+	// Whether periodic update synchronization is enabled or not
+	// (update_sync_enabled)
+	UpdateSyncEnabled bool
+
+	// The frequency at which updates are synchronized from a remote CDN: daily or
+	// weekly (update_sync_frequency)
+	UpdateSyncFrequency string
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// address of the vswitch controller (vswitch_controller)
+	VswitchController string
+
+	// true if workload balancing is enabled on the pool, false otherwise
+	// (wlb_enabled)
+	WLBEnabled bool
+
+	// Url for the configured workload balancing host (wlb_url)
+	WLBURL string
+
+	// Username for accessing the workload balancing host (wlb_username)
+	WLBUsername string
+
+	// true if communication with the WLB server should enforce TLS certificate
+	// verification (wlb_verify_cert)
+	WLBVerifyCert bool
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var poolFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"allowed_operations":                true,
+	"blobs":                             true,
+	"client_certificate_auth_enabled":   true,
+	"client_certificate_auth_name":      true,
+	"coordinator_bias":                  true,
+	"cpu_info":                          true,
+	"crash_dump_SR":                     true,
+	"current_operations":                true,
+	"default_SR":                        true,
+	"guest_agent_config":                true,
+	"gui_config":                        true,
+	"ha_allow_overcommit":               true,
+	"ha_cluster_stack":                  true,
+	"ha_configuration":                  true,
+	"ha_enabled":                        true,
+	"ha_host_failures_to_tolerate":      true,
+	"ha_overcommitted":                  true,
+	"ha_plan_exists_for":                true,
+	"ha_reboot_vm_on_internal_shutdown": true,
+	"ha_statefiles":                     true,
+	"health_check_config":               true,
+	"igmp_snooping_enabled":             true,
+	"is_psr_pending":                    true,
+	"last_update_sync":                  true,
+	"license_server":                    true,
+	"live_patching_disabled":            true,
+	"master":                            true,
+	"metadata_VDIs":                     true,
+	"migration_compression":             true,
+	"name__description":                 true,
+	"name__label":                       true,
+	"other_config":                      true,
+	"policy_no_vendor_device":           true,
+	"recommendations":                   true,
+	"redo_log_enabled":                  true,
+	"redo_log_vdi":                      true,
+	"repositories":                      true,
+	"repository_proxy_password":         true,
+	"repository_proxy_url":              true,
+	"repository_proxy_username":         true,
+	"restrictions":                      true,
+	"suspend_image_SR":                  true,
+	"tags":                              true,
+	"telemetry_frequency":               true,
+	"telemetry_next_collection":         true,
+	"telemetry_uuid":                    true,
+	"tls_verification_enabled":          true,
+	"uefi_certificates":                 true,
+	"update_sync_day":                   true,
+	"update_sync_enabled":               true,
+	"update_sync_frequency":             true,
+	"uuid":                              true,
+	"vswitch_controller":                true,
+	"wlb_enabled":                       true,
+	"wlb_url":                           true,
+	"wlb_username":                      true,
+	"wlb_verify_cert":                   true,
+}
+
+func (o *Pool) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.AllowedOperations = field(d, row, "allowed_operations", asSet(asString))
+	o.Blobs = field(d, row, "blobs", asMap(asString))
+	o.ClientCertificateAuthEnabled = field(d, row, "client_certificate_auth_enabled", asBool)
+	o.ClientCertificateAuthName = field(d, row, "client_certificate_auth_name", asString)
+	o.CoordinatorBias = field(d, row, "coordinator_bias", asBool)
+	o.CPUInfo = field(d, row, "cpu_info", asMap(asString))
+	o.CrashDumpSR = field(d, row, "crash_dump_SR", asRef[SR](d))
+	o.CurrentOperations = field(d, row, "current_operations", asMap(asString))
+	o.DefaultSR = field(d, row, "default_SR", asRef[SR](d))
+	o.GuestAgentConfig = field(d, row, "guest_agent_config", asMap(asString))
+	o.GuiConfig = field(d, row, "gui_config", asMap(asString))
+	o.HAAllowOvercommit = field(d, row, "ha_allow_overcommit", asBool)
+	o.HAClusterStack = field(d, row, "ha_cluster_stack", asString)
+	o.HAConfiguration = field(d, row, "ha_configuration", asMap(asString))
+	o.HAEnabled = field(d, row, "ha_enabled", asBool)
+	o.HAHostFailuresToTolerate = field(d, row, "ha_host_failures_to_tolerate", asInt)
+	o.HAOvercommitted = field(d, row, "ha_overcommitted", asBool)
+	o.HAPlanExistsFor = field(d, row, "ha_plan_exists_for", asInt)
+	o.HARebootVMOnInternalShutdown = field(d, row, "ha_reboot_vm_on_internal_shutdown", asBool)
+	o.HAStatefiles = field(d, row, "ha_statefiles", asSet(asString))
+	o.HealthCheckConfig = field(d, row, "health_check_config", asMap(asString))
+	o.IgmpSnoopingEnabled = field(d, row, "igmp_snooping_enabled", asBool)
+	o.IsPsrPending = field(d, row, "is_psr_pending", asBool)
+	o.LastUpdateSync = field(d, row, "last_update_sync", asTime)
+	o.LicenseServer = field(d, row, "license_server", asMap(asString))
+	o.LivePatchingDisabled = field(d, row, "live_patching_disabled", asBool)
+	o.Master = field(d, row, "master", asRef[Host](d))
+	o.MetadataVDIs = field(d, row, "metadata_VDIs", asSet(asRef[VDI](d)))
+	o.MigrationCompression = field(d, row, "migration_compression", asBool)
+	o.NameDescription = field(d, row, "name__description", asString)
+	o.NameLabel = field(d, row, "name__label", asString)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.PolicyNoVendorDevice = field(d, row, "policy_no_vendor_device", asBool)
+	o.Recommendations = field(d, row, "recommendations", asMap(asString))
+	o.RedoLogEnabled = field(d, row, "redo_log_enabled", asBool)
+	o.RedoLogVDI = field(d, row, "redo_log_vdi", asRef[VDI](d))
+	o.Repositories = field(d, row, "repositories", asSet(asString))
+	o.RepositoryProxyPassword = field(d, row, "repository_proxy_password", asString)
+	o.RepositoryProxyURL = field(d, row, "repository_proxy_url", asString)
+	o.RepositoryProxyUsername = field(d, row, "repository_proxy_username", asString)
+	o.Restrictions = field(d, row, "restrictions", asMap(asString))
+	o.SuspendImageSR = field(d, row, "suspend_image_SR", asRef[SR](d))
+	o.Tags = field(d, row, "tags", asSet(asString))
+	o.TelemetryFrequency = field(d, row, "telemetry_frequency", asString)
+	o.TelemetryNextCollection = field(d, row, "telemetry_next_collection", asTime)
+	o.TelemetryUUID = field(d, row, "telemetry_uuid", asString)
+	o.TLSVerificationEnabled = field(d, row, "tls_verification_enabled", asBool)
+	o.UEFICertificates = field(d, row, "uefi_certificates", asString)
+	o.UpdateSyncDay = field(d, row, "update_sync_day", asInt)
+	o.UpdateSyncEnabled = field(d, row, "update_sync_enabled", asBool)
+	o.UpdateSyncFrequency = field(d, row, "update_sync_frequency", asString)
+	o.UUID = field(d, row, "uuid", asString)
+	o.VswitchController = field(d, row, "vswitch_controller", asString)
+	o.WLBEnabled = field(d, row, "wlb_enabled", asBool)
+	o.WLBURL = field(d, row, "wlb_url", asString)
+	o.WLBUsername = field(d, row, "wlb_username", asString)
+	o.WLBVerifyCert = field(d, row, "wlb_verify_cert", asBool)
+	o.Extras = extras(row, poolFields)
+}
+
+// SM is an object of the class SM. A storage manager plugin
+type SM struct {
+	Ref string
+
+	// capabilities of the SM plugin (capabilities)
+	Capabilities []string
+
+	// names and descriptions of device config keys (configuration)
+	Configuration map[string]string
+
+	// Entity which owns the copyright of this plugin (copyright)
+	Copyright string
+
+	// filename of the storage driver (driver_filename)
+	DriverFilename string
+
+	// capabilities of the SM plugin, with capability version numbers (features)
+	Features map[string]int64
+
+	// SM features that are waiting to be declared per host
+	// (host_pending_features)
+	HostPendingFeatures map[string]map[string]int64
+
+	// A notes field containing human-readable description (name__description)
+	NameDescription string
+
+	// A human-readable name (name__label)
+	NameLabel string
+
+	// additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// Minimum SM API version required on the server (required_api_version)
+	RequiredAPIVersion string
+
+	// The storage plugin requires that one of these cluster stacks is configured
+	// and running (required_cluster_stack)
+	RequiredClusterStack []string
+
+	// The image formats supported by the SR (supported_image_formats)
+	SupportedImageFormats []string
+
+	// SR.type (type)
+	Type string
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Vendor who created this plugin (vendor)
+	Vendor string
+
+	// Version of the plugin (version)
+	Version string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var smFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"capabilities":            true,
+	"configuration":           true,
+	"copyright":               true,
+	"driver_filename":         true,
+	"features":                true,
+	"host_pending_features":   true,
+	"name__description":       true,
+	"name__label":             true,
+	"other_config":            true,
+	"required_api_version":    true,
+	"required_cluster_stack":  true,
+	"supported_image_formats": true,
+	"type":                    true,
+	"uuid":                    true,
+	"vendor":                  true,
+	"version":                 true,
+}
+
+func (o *SM) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.Capabilities = field(d, row, "capabilities", asSet(asString))
+	o.Configuration = field(d, row, "configuration", asMap(asString))
+	o.Copyright = field(d, row, "copyright", asString)
+	o.DriverFilename = field(d, row, "driver_filename", asString)
+	o.Features = field(d, row, "features", asMap(asInt))
+	o.HostPendingFeatures = field(d, row, "host_pending_features", asMap(asMap(asInt)))
+	o.NameDescription = field(d, row, "name__description", asString)
+	o.NameLabel = field(d, row, "name__label", asString)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.RequiredAPIVersion = field(d, row, "required_api_version", asString)
+	o.RequiredClusterStack = field(d, row, "required_cluster_stack", asSet(asString))
+	o.SupportedImageFormats = field(d, row, "supported_image_formats", asSet(asString))
+	o.Type = field(d, row, "type", asString)
+	o.UUID = field(d, row, "uuid", asString)
+	o.Vendor = field(d, row, "vendor", asString)
+	o.Version = field(d, row, "version", asString)
+	o.Extras = extras(row, smFields)
+}
+
+// SR is an object of the class SR. A storage repository
+type SR struct {
+	Ref string
+
+	// describes how particular hosts can see this storage repository (PBDs)
+	PBDs []Ref[PBD]
+
+	// all virtual disks known to this storage repository (VDIs)
+	VDIs []Ref[VDI]
+
+	// List of the operations allowed in this state. This list is advisory only
+	// and the server state may have changed by the time this field is read by a
+	// client (allowed_operations)
+	AllowedOperations []string
+
+	// Binary blobs associated with this SR (blobs)
+	Blobs map[string]string
+
+	// True if the SR is using aggregated local storage (clustered)
+	Clustered bool
+
+	// the type of the SR's content, if required (e.g. ISOs) (content_type)
+	ContentType string
+
+	// Links each of the running tasks using this object (by reference) to a
+	// current_operation enum which describes the nature of the task
+	// (current_operations)
+	CurrentOperations map[string]string
+
+	// The default visibility of VDIs created in this SR (default_vdi_visibility)
+	DefaultVDIVisibility bool
+
+	// The disaster recovery task which introduced this SR (introduced_by)
+	IntroducedBy string
+
+	// True if this is the SR that contains the Tools ISO VDIs (is_tools_sr)
+	IsToolsSR bool
+
+	// True if this SR is assigned to be the local cache for its host
+	// (local_cache_enabled)
+	LocalCacheEnabled bool
+
+	// A notes field containing human-readable description (name__description)
+	NameDescription string
+
+	// A human-readable name (name__label)
+	NameLabel string
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// total physical size of the repository (in bytes) (physical_size)
+	PhysicalSize int64
+
+	// physical space currently utilised on this storage repository (in bytes).
+	// Note that for sparse disk formats, physical_utilisation may be less than
+	// virtual_allocation (physical_utilisation)
+	PhysicalUtilisation int64
+
+	// true if this SR is (capable of being) shared between multiple hosts
+	// (shared)
+	Shared bool
+
+	// SM dependent data (sm_config)
+	SMConfig map[string]string
+
+	// user-specified tags for categorization purposes (tags)
+	Tags []string
+
+	// type of the storage repository (type)
+	Type string
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// sum of virtual_sizes of all VDIs in this storage repository (in bytes)
+	// (virtual_allocation)
+	VirtualAllocation int64
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var srFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"PBDs":                   true,
+	"VDIs":                   true,
+	"allowed_operations":     true,
+	"blobs":                  true,
+	"clustered":              true,
+	"content_type":           true,
+	"current_operations":     true,
+	"default_vdi_visibility": true,
+	"introduced_by":          true,
+	"is_tools_sr":            true,
+	"local_cache_enabled":    true,
+	"name__description":      true,
+	"name__label":            true,
+	"other_config":           true,
+	"physical_size":          true,
+	"physical_utilisation":   true,
+	"shared":                 true,
+	"sm_config":              true,
+	"tags":                   true,
+	"type":                   true,
+	"uuid":                   true,
+	"virtual_allocation":     true,
+}
+
+func (o *SR) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.PBDs = field(d, row, "PBDs", asSet(asRef[PBD](d)))
+	o.VDIs = field(d, row, "VDIs", asSet(asRef[VDI](d)))
+	o.AllowedOperations = field(d, row, "allowed_operations", asSet(asString))
+	o.Blobs = field(d, row, "blobs", asMap(asString))
+	o.Clustered = field(d, row, "clustered", asBool)
+	o.ContentType = field(d, row, "content_type", asString)
+	o.CurrentOperations = field(d, row, "current_operations", asMap(asString))
+	o.DefaultVDIVisibility = field(d, row, "default_vdi_visibility", asBool)
+	o.IntroducedBy = field(d, row, "introduced_by", asString)
+	o.IsToolsSR = field(d, row, "is_tools_sr", asBool)
+	o.LocalCacheEnabled = field(d, row, "local_cache_enabled", asBool)
+	o.NameDescription = field(d, row, "name__description", asString)
+	o.NameLabel = field(d, row, "name__label", asString)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.PhysicalSize = field(d, row, "physical_size", asInt)
+	o.PhysicalUtilisation = field(d, row, "physical_utilisation", asInt)
+	o.Shared = field(d, row, "shared", asBool)
+	o.SMConfig = field(d, row, "sm_config", asMap(asString))
+	o.Tags = field(d, row, "tags", asSet(asString))
+	o.Type = field(d, row, "type", asString)
+	o.UUID = field(d, row, "uuid", asString)
+	o.VirtualAllocation = field(d, row, "virtual_allocation", asInt)
+	o.Extras = extras(row, srFields)
+}
+
+// Task is an object of the class task. A long-running asynchronous task
+type Task struct {
+	Ref string
+
+	// List of the operations allowed in this state. This list is advisory only
+	// and the server state may have changed by the time this field is read by a
+	// client (allowed_operations)
+	AllowedOperations []string
+
+	// Function call trace for debugging (backtrace)
+	Backtrace string
+
+	// Time task was created (created)
+	Created time.Time
+
+	// Links each of the running tasks using this object (by reference) to a
+	// current_operation enum which describes the nature of the task
+	// (current_operations)
+	CurrentOperations map[string]string
+
+	// if the task has failed, this field contains the set of associated error
+	// strings. Undefined otherwise (error_info)
+	ErrorInfo []string
+
+	// Time task finished (i.e. succeeded or failed). If task-status is pending,
+	// then the value of this field has no meaning (finished)
+	Finished time.Time
+
+	// A notes field containing human-readable description (name__description)
+	NameDescription string
+
+	// A human-readable name (name__label)
+	NameLabel string
+
+	// additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// This field contains the estimated fraction of the task which is complete.
+	// This field should not be used to determine whether the task is complete -
+	// for this the status field of the task should be used (progress)
+	Progress float64
+
+	// the host on which the task is running (resident_on)
+	ResidentOn Ref[Host]
+
+	// if the task has completed successfully, this field contains the result
+	// value (either Void or an object reference). Undefined otherwise (result)
+	Result string
+
+	// current status of the task (status)
+	Status string
+
+	// Ref pointing to the task this is a substask of (subtask_of)
+	SubtaskOf Ref[Task]
+
+	// List pointing to all the substasks (subtasks)
+	Subtasks []Ref[Task]
+
+	// if the task has completed successfully, this field contains the type of the
+	// encoded result (i.e. name of the class whose reference is in the result
+	// field). Undefined otherwise (type)
+	Type string
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var taskFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"allowed_operations": true,
+	"backtrace":          true,
+	"created":            true,
+	"current_operations": true,
+	"error_info":         true,
+	"finished":           true,
+	"name__description":  true,
+	"name__label":        true,
+	"other_config":       true,
+	"progress":           true,
+	"resident_on":        true,
+	"result":             true,
+	"status":             true,
+	"subtask_of":         true,
+	"subtasks":           true,
+	"type":               true,
+	"uuid":               true,
+}
+
+func (o *Task) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.AllowedOperations = field(d, row, "allowed_operations", asSet(asString))
+	o.Backtrace = field(d, row, "backtrace", asString)
+	o.Created = field(d, row, "created", asTime)
+	o.CurrentOperations = field(d, row, "current_operations", asMap(asString))
+	o.ErrorInfo = field(d, row, "error_info", asSet(asString))
+	o.Finished = field(d, row, "finished", asTime)
+	o.NameDescription = field(d, row, "name__description", asString)
+	o.NameLabel = field(d, row, "name__label", asString)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.Progress = field(d, row, "progress", asFloat)
+	o.ResidentOn = field(d, row, "resident_on", asRef[Host](d))
+	o.Result = field(d, row, "result", asString)
+	o.Status = field(d, row, "status", asString)
+	o.SubtaskOf = field(d, row, "subtask_of", asRef[Task](d))
+	o.Subtasks = field(d, row, "subtasks", asSet(asRef[Task](d)))
+	o.Type = field(d, row, "type", asString)
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, taskFields)
+}
+
+// Tunnel is an object of the class tunnel. A tunnel for network traffic
+type Tunnel struct {
+	Ref string
+
+	// The interface through which the tunnel is accessed (access_PIF)
+	AccessPIF Ref[PIF]
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// The protocol used for tunneling (either GRE or VxLAN) (protocol)
+	Protocol string
+
+	// Status information about the tunnel (status)
+	Status map[string]string
+
+	// The interface used by the tunnel (transport_PIF)
+	TransportPIF Ref[PIF]
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var tunnelFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"access_PIF":    true,
+	"other_config":  true,
+	"protocol":      true,
+	"status":        true,
+	"transport_PIF": true,
+	"uuid":          true,
+}
+
+func (o *Tunnel) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.AccessPIF = field(d, row, "access_PIF", asRef[PIF](d))
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.Protocol = field(d, row, "protocol", asString)
+	o.Status = field(d, row, "status", asMap(asString))
+	o.TransportPIF = field(d, row, "transport_PIF", asRef[PIF](d))
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, tunnelFields)
+}
+
+// VBD is an object of the class VBD. A virtual block device
+type VBD struct {
+	Ref string
+
+	// the virtual disk (VDI)
+	VDI Ref[VDI]
+
+	// the virtual machine (VM)
+	VM Ref[VM]
+
+	// List of the operations allowed in this state. This list is advisory only
+	// and the server state may have changed by the time this field is read by a
+	// client (allowed_operations)
+	AllowedOperations []string
+
+	// true if this VBD is bootable (bootable)
+	Bootable bool
+
+	// Links each of the running tasks using this object (by reference) to a
+	// current_operation enum which describes the nature of the task
+	// (current_operations)
+	CurrentOperations map[string]string
+
+	// is the device currently attached (erased on reboot) (currently_attached)
+	CurrentlyAttached bool
+
+	// device seen by the guest e.g. hda1 (device)
+	Device string
+
+	// if true this represents an empty drive (empty)
+	Empty bool
+
+	// metrics associated with this VBD (metrics)
+	Metrics string
+
+	// the mode the VBD should be mounted with (mode)
+	Mode string
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// parameters for chosen QoS algorithm (qos__algorithm_params)
+	QoSAlgorithmParams map[string]string
+
+	// QoS algorithm to use (qos__algorithm_type)
+	QoSAlgorithmType string
+
+	// supported QoS algorithms for this VBD (qos__supported_algorithms)
+	QoSSupportedAlgorithms []string
+
+	// true if the VBD is reserved pending a reboot/migrate (reserved)
+	Reserved bool
+
+	// Device runtime properties (runtime_properties)
+	RuntimeProperties map[string]string
+
+	// error/success code associated with last attach-operation (erased on reboot)
+	// (status_code)
+	StatusCode int64
+
+	// error/success information associated with last attach-operation status
+	// (erased on reboot) (status_detail)
+	StatusDetail string
+
+	// true if a storage level lock was acquired (storage_lock)
+	StorageLock bool
+
+	// how the VBD will appear to the guest (e.g. disk or CD) (type)
+	Type string
+
+	// true if this VBD will support hot-unplug (unpluggable)
+	Unpluggable bool
+
+	// user-friendly device name e.g. 0,1,2,etc (userdevice)
+	Userdevice string
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var vbdFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"VDI":                       true,
+	"VM":                        true,
+	"allowed_operations":        true,
+	"bootable":                  true,
+	"current_operations":        true,
+	"currently_attached":        true,
+	"device":                    true,
+	"empty":                     true,
+	"metrics":                   true,
+	"mode":                      true,
+	"other_config":              true,
+	"qos__algorithm_params":     true,
+	"qos__algorithm_type":       true,
+	"qos__supported_algorithms": true,
+	"reserved":                  true,
+	"runtime_properties":        true,
+	"status_code":               true,
+	"status_detail":             true,
+	"storage_lock":              true,
+	"type":                      true,
+	"unpluggable":               true,
+	"userdevice":                true,
+	"uuid":                      true,
+}
+
+func (o *VBD) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.VDI = field(d, row, "VDI", asRef[VDI](d))
+	o.VM = field(d, row, "VM", asRef[VM](d))
+	o.AllowedOperations = field(d, row, "allowed_operations", asSet(asString))
+	o.Bootable = field(d, row, "bootable", asBool)
+	o.CurrentOperations = field(d, row, "current_operations", asMap(asString))
+	o.CurrentlyAttached = field(d, row, "currently_attached", asBool)
+	o.Device = field(d, row, "device", asString)
+	o.Empty = field(d, row, "empty", asBool)
+	o.Metrics = field(d, row, "metrics", asString)
+	o.Mode = field(d, row, "mode", asString)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.QoSAlgorithmParams = field(d, row, "qos__algorithm_params", asMap(asString))
+	o.QoSAlgorithmType = field(d, row, "qos__algorithm_type", asString)
+	o.QoSSupportedAlgorithms = field(d, row, "qos__supported_algorithms", asSet(asString))
+	o.Reserved = field(d, row, "reserved", asBool)
+	o.RuntimeProperties = field(d, row, "runtime_properties", asMap(asString))
+	o.StatusCode = field(d, row, "status_code", asInt)
+	o.StatusDetail = field(d, row, "status_detail", asString)
+	o.StorageLock = field(d, row, "storage_lock", asBool)
+	o.Type = field(d, row, "type", asString)
+	o.Unpluggable = field(d, row, "unpluggable", asBool)
+	o.Userdevice = field(d, row, "userdevice", asString)
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, vbdFields)
+}
+
+// VDI is an object of the class VDI. A virtual disk image
+type VDI struct {
+	Ref string
+
+	// storage repository in which the VDI resides (SR)
+	SR Ref[SR]
+
+	// list of vbds that refer to this disk (VBDs)
+	VBDs []Ref[VBD]
+
+	// true if this VDI is to be cached in the local cache SR (allow_caching)
+	AllowCaching bool
+
+	// List of the operations allowed in this state. This list is advisory only
+	// and the server state may have changed by the time this field is read by a
+	// client (allowed_operations)
+	AllowedOperations []string
+
+	// True if changed blocks are tracked for this VDI (cbt_enabled)
+	CBTEnabled bool
+
+	// list of crash dumps that refer to this disk (crash_dumps)
+	CrashDumps []string
+
+	// Links each of the running tasks using this object (by reference) to a
+	// current_operation enum which describes the nature of the task
+	// (current_operations)
+	CurrentOperations map[string]string
+
+	// true if this is a snapshot (is_a_snapshot)
+	IsASnapshot bool
+
+	// Whether this VDI is a Tools ISO (is_tools_iso)
+	IsToolsISO bool
+
+	// location information (location)
+	Location string
+
+	// No documentation (managed)
+	Managed bool
+
+	// Whether this VDI contains the latest known accessible metadata for the pool
+	// (metadata_latest)
+	MetadataLatest bool
+
+	// The pool whose metadata is contained in this VDI (metadata_of_pool)
+	MetadataOfPool Ref[Pool]
+
+	// true if SR scan operation reported this VDI as not present on disk
+	// (missing)
+	Missing bool
+
+	// A notes field containing human-readable description (name__description)
+	NameDescription string
+
+	// A human-readable name (name__label)
+	NameLabel string
+
+	// The behaviour of this VDI on a VM boot (on_boot)
+	OnBoot string
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// This field is always null. Deprecated (parent)
+	Parent Ref[VDI]
+
+	// amount of physical space that the disk image is currently taking up on the
+	// storage repository (in bytes) (physical_utilisation)
+	PhysicalUtilisation int64
+
+	// true if this disk may ONLY be mounted read-only (read_only)
+	ReadOnly bool
+
+	// true if this disk may be shared (sharable)
+	Sharable bool
+
+	// SM dependent data (sm_config)
+	SMConfig map[string]string
+
+	// Ref pointing to the VDI this snapshot is of (snapshot_of)
+	SnapshotOf Ref[VDI]
+
+	// Date/time when this snapshot was created (snapshot_time)
+	SnapshotTime time.Time
+
+	// List pointing to all the VDIs snapshots (snapshots)
+	Snapshots []Ref[VDI]
+
+	// true if this disk is locked at the storage level (storage_lock)
+	StorageLock bool
+
+	// user-specified tags for categorization purposes (tags)
+	Tags []string
+
+	// type of the VDI (type)
+	Type string
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// size of disk as presented to the guest (in bytes). Note that, depending on
+	// storage backend type, requested size may not be respected exactly
+	// (virtual_size)
+	VirtualSize int64
+
+	// data to be inserted into the xenstore tree
+	// (/local/domain/0/backend/vbd/<domid>/<device-id>/sm-data) after the VDI is
+	// attached. This is generally set by the SM backends on vdi_attach
+	// (xenstore_data)
+	XenstoreData map[string]string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var vdiFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"SR":                   true,
+	"VBDs":                 true,
+	"allow_caching":        true,
+	"allowed_operations":   true,
+	"cbt_enabled":          true,
+	"crash_dumps":          true,
+	"current_operations":   true,
+	"is_a_snapshot":        true,
+	"is_tools_iso":         true,
+	"location":             true,
+	"managed":              true,
+	"metadata_latest":      true,
+	"metadata_of_pool":     true,
+	"missing":              true,
+	"name__description":    true,
+	"name__label":          true,
+	"on_boot":              true,
+	"other_config":         true,
+	"parent":               true,
+	"physical_utilisation": true,
+	"read_only":            true,
+	"sharable":             true,
+	"sm_config":            true,
+	"snapshot_of":          true,
+	"snapshot_time":        true,
+	"snapshots":            true,
+	"storage_lock":         true,
+	"tags":                 true,
+	"type":                 true,
+	"uuid":                 true,
+	"virtual_size":         true,
+	"xenstore_data":        true,
+}
+
+func (o *VDI) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.SR = field(d, row, "SR", asRef[SR](d))
+	o.VBDs = field(d, row, "VBDs", asSet(asRef[VBD](d)))
+	o.AllowCaching = field(d, row, "allow_caching", asBool)
+	o.AllowedOperations = field(d, row, "allowed_operations", asSet(asString))
+	o.CBTEnabled = field(d, row, "cbt_enabled", asBool)
+	o.CrashDumps = field(d, row, "crash_dumps", asSet(asString))
+	o.CurrentOperations = field(d, row, "current_operations", asMap(asString))
+	o.IsASnapshot = field(d, row, "is_a_snapshot", asBool)
+	o.IsToolsISO = field(d, row, "is_tools_iso", asBool)
+	o.Location = field(d, row, "location", asString)
+	o.Managed = field(d, row, "managed", asBool)
+	o.MetadataLatest = field(d, row, "metadata_latest", asBool)
+	o.MetadataOfPool = field(d, row, "metadata_of_pool", asRef[Pool](d))
+	o.Missing = field(d, row, "missing", asBool)
+	o.NameDescription = field(d, row, "name__description", asString)
+	o.NameLabel = field(d, row, "name__label", asString)
+	o.OnBoot = field(d, row, "on_boot", asString)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.Parent = field(d, row, "parent", asRef[VDI](d))
+	o.PhysicalUtilisation = field(d, row, "physical_utilisation", asInt)
+	o.ReadOnly = field(d, row, "read_only", asBool)
+	o.Sharable = field(d, row, "sharable", asBool)
+	o.SMConfig = field(d, row, "sm_config", asMap(asString))
+	o.SnapshotOf = field(d, row, "snapshot_of", asRef[VDI](d))
+	o.SnapshotTime = field(d, row, "snapshot_time", asTime)
+	o.Snapshots = field(d, row, "snapshots", asSet(asRef[VDI](d)))
+	o.StorageLock = field(d, row, "storage_lock", asBool)
+	o.Tags = field(d, row, "tags", asSet(asString))
+	o.Type = field(d, row, "type", asString)
+	o.UUID = field(d, row, "uuid", asString)
+	o.VirtualSize = field(d, row, "virtual_size", asInt)
+	o.XenstoreData = field(d, row, "xenstore_data", asMap(asString))
+	o.Extras = extras(row, vdiFields)
+}
+
+// VGPU is an object of the class VGPU. A virtual GPU (vGPU)
+type VGPU struct {
+	Ref string
+
+	// GPU group used by the vGPU (GPU_group)
+	GPUGroup Ref[GPUGroup]
+
+	// Device passed trough to VM, either as full device or SR-IOV virtual
+	// function (PCI)
+	PCI Ref[PCI]
+
+	// VM that owns the vGPU (VM)
+	VM Ref[VM]
+
+	// VGPU metadata to determine whether a VGPU can migrate between two PGPUs
+	// (compatibility_metadata)
+	CompatibilityMetadata map[string]string
+
+	// Reflects whether the virtual device is currently connected to a physical
+	// device (currently_attached)
+	CurrentlyAttached bool
+
+	// Order in which the devices are plugged into the VM (device)
+	Device string
+
+	// Extra arguments for vGPU and passed to demu (extra_args)
+	ExtraArgs string
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// The PGPU on which this VGPU is running (resident_on)
+	ResidentOn Ref[PGPU]
+
+	// The PGPU on which this VGPU is scheduled to run
+	// (scheduled_to_be_resident_on)
+	ScheduledToBeResidentOn Ref[PGPU]
+
+	// Preset type for this VGPU (type)
+	Type Ref[VGPUType]
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var vgpuFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"GPU_group":                   true,
+	"PCI":                         true,
+	"VM":                          true,
+	"compatibility_metadata":      true,
+	"currently_attached":          true,
+	"device":                      true,
+	"extra_args":                  true,
+	"other_config":                true,
+	"resident_on":                 true,
+	"scheduled_to_be_resident_on": true,
+	"type":                        true,
+	"uuid":                        true,
+}
+
+func (o *VGPU) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.GPUGroup = field(d, row, "GPU_group", asRef[GPUGroup](d))
+	o.PCI = field(d, row, "PCI", asRef[PCI](d))
+	o.VM = field(d, row, "VM", asRef[VM](d))
+	o.CompatibilityMetadata = field(d, row, "compatibility_metadata", asMap(asString))
+	o.CurrentlyAttached = field(d, row, "currently_attached", asBool)
+	o.Device = field(d, row, "device", asString)
+	o.ExtraArgs = field(d, row, "extra_args", asString)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.ResidentOn = field(d, row, "resident_on", asRef[PGPU](d))
+	o.ScheduledToBeResidentOn = field(d, row, "scheduled_to_be_resident_on", asRef[PGPU](d))
+	o.Type = field(d, row, "type", asRef[VGPUType](d))
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, vgpuFields)
+}
+
+// VGPUType is an object of the class VGPU_type. A type of virtual GPU
+type VGPUType struct {
+	Ref string
+
+	// List of VGPUs of this type (VGPUs)
+	VGPUs []Ref[VGPU]
+
+	// List of VGPU types which are compatible in one VM (compatible_types_in_vm)
+	CompatibleTypesInVM []Ref[VGPUType]
+
+	// List of GPU groups in which at least one have this VGPU type enabled
+	// (enabled_on_GPU_groups)
+	EnabledOnGPUGroups []Ref[GPUGroup]
+
+	// List of PGPUs that have this VGPU type enabled (enabled_on_PGPUs)
+	EnabledOnPGPUs []Ref[PGPU]
+
+	// Indicates whether VGPUs of this type should be considered experimental
+	// (experimental)
+	Experimental bool
+
+	// Framebuffer size of the VGPU type, in bytes (framebuffer_size)
+	FramebufferSize int64
+
+	// Key used to identify VGPU types and avoid creating duplicates - this field
+	// is used internally and not intended for interpretation by API clients
+	// (identifier)
+	Identifier string
+
+	// The internal implementation of this VGPU type (implementation)
+	Implementation string
+
+	// Maximum number of displays supported by the VGPU type (max_heads)
+	MaxHeads int64
+
+	// Maximum resolution (width) supported by the VGPU type (max_resolution_x)
+	MaxResolutionX int64
+
+	// Maximum resolution (height) supported by the VGPU type (max_resolution_y)
+	MaxResolutionY int64
+
+	// Model name associated with the VGPU type (model_name)
+	ModelName string
+
+	// List of GPU groups in which at least one PGPU supports this VGPU type
+	// (supported_on_GPU_groups)
+	SupportedOnGPUGroups []Ref[GPUGroup]
+
+	// List of PGPUs that support this VGPU type (supported_on_PGPUs)
+	SupportedOnPGPUs []Ref[PGPU]
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Name of VGPU vendor (vendor_name)
+	VendorName string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var vgputypeFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"VGPUs":                   true,
+	"compatible_types_in_vm":  true,
+	"enabled_on_GPU_groups":   true,
+	"enabled_on_PGPUs":        true,
+	"experimental":            true,
+	"framebuffer_size":        true,
+	"identifier":              true,
+	"implementation":          true,
+	"max_heads":               true,
+	"max_resolution_x":        true,
+	"max_resolution_y":        true,
+	"model_name":              true,
+	"supported_on_GPU_groups": true,
+	"supported_on_PGPUs":      true,
+	"uuid":                    true,
+	"vendor_name":             true,
+}
+
+func (o *VGPUType) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.VGPUs = field(d, row, "VGPUs", asSet(asRef[VGPU](d)))
+	o.CompatibleTypesInVM = field(d, row, "compatible_types_in_vm", asSet(asRef[VGPUType](d)))
+	o.EnabledOnGPUGroups = field(d, row, "enabled_on_GPU_groups", asSet(asRef[GPUGroup](d)))
+	o.EnabledOnPGPUs = field(d, row, "enabled_on_PGPUs", asSet(asRef[PGPU](d)))
+	o.Experimental = field(d, row, "experimental", asBool)
+	o.FramebufferSize = field(d, row, "framebuffer_size", asInt)
+	o.Identifier = field(d, row, "identifier", asString)
+	o.Implementation = field(d, row, "implementation", asString)
+	o.MaxHeads = field(d, row, "max_heads", asInt)
+	o.MaxResolutionX = field(d, row, "max_resolution_x", asInt)
+	o.MaxResolutionY = field(d, row, "max_resolution_y", asInt)
+	o.ModelName = field(d, row, "model_name", asString)
+	o.SupportedOnGPUGroups = field(d, row, "supported_on_GPU_groups", asSet(asRef[GPUGroup](d)))
+	o.SupportedOnPGPUs = field(d, row, "supported_on_PGPUs", asSet(asRef[PGPU](d)))
+	o.UUID = field(d, row, "uuid", asString)
+	o.VendorName = field(d, row, "vendor_name", asString)
+	o.Extras = extras(row, vgputypeFields)
+}
+
+// VIF is an object of the class VIF. A virtual network interface
+type VIF struct {
+	Ref string
+
+	// ethernet MAC address of virtual interface, as exposed to guest (MAC)
+	MAC string
+
+	// true if the MAC was autogenerated; false indicates it was set manually
+	// (MAC_autogenerated)
+	MACAutogenerated bool
+
+	// MTU in octets (MTU)
+	MTU int64
+
+	// virtual machine to which this vif is connected (VM)
+	VM Ref[VM]
+
+	// List of the operations allowed in this state. This list is advisory only
+	// and the server state may have changed by the time this field is read by a
+	// client (allowed_operations)
+	AllowedOperations []string
+
+	// Links each of the running tasks using this object (by reference) to a
+	// current_operation enum which describes the nature of the task
+	// (current_operations)
+	CurrentOperations map[string]string
+
+	// is the device currently attached (erased on reboot) (currently_attached)
+	CurrentlyAttached bool
+
+	// order in which VIF backends are created by xapi (device)
+	Device string
+
+	// IPv4 addresses in CIDR format (ipv4_addresses)
+	IPv4Addresses []string
+
+	// A list of IPv4 addresses which can be used to filter traffic passing
+	// through this VIF (ipv4_allowed)
+	IPv4Allowed []string
+
+	// Determines whether IPv4 addresses are configured on the VIF
+	// (ipv4_configuration_mode)
+	IPv4ConfigurationMode string
+
+	// IPv4 gateway (the empty string means that no gateway is set) (ipv4_gateway)
+	IPv4Gateway string
+
+	// IPv6 addresses in CIDR format (ipv6_addresses)
+	IPv6Addresses []string
+
+	// A list of IPv6 addresses which can be used to filter traffic passing
+	// through this VIF (ipv6_allowed)
+	IPv6Allowed []string
+
+	// Determines whether IPv6 addresses are configured on the VIF
+	// (ipv6_configuration_mode)
+	IPv6ConfigurationMode string
+
+	// IPv6 gateway (the empty string means that no gateway is set) (ipv6_gateway)
+	IPv6Gateway string
+
+	// current locking mode of the VIF (locking_mode)
+	LockingMode string
+
+	// metrics associated with this VIF (metrics)
+	Metrics string
+
+	// virtual network to which this vif is connected (network)
+	Network Ref[Network]
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// parameters for chosen QoS algorithm (qos__algorithm_params)
+	QoSAlgorithmParams map[string]string
+
+	// QoS algorithm to use (qos__algorithm_type)
+	QoSAlgorithmType string
+
+	// supported QoS algorithms for this VIF (qos__supported_algorithms)
+	QoSSupportedAlgorithms []string
+
+	// true if the VIF is reserved pending a reboot/migrate (reserved)
+	Reserved bool
+
+	// pci of network SR-IOV VF which is reserved for this vif (reserved_pci)
+	ReservedPCI Ref[PCI]
+
+	// Device runtime properties (runtime_properties)
+	RuntimeProperties map[string]string
+
+	// error/success code associated with last attach-operation (erased on reboot)
+	// (status_code)
+	StatusCode int64
+
+	// error/success information associated with last attach-operation status
+	// (erased on reboot) (status_detail)
+	StatusDetail string
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var vifFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"MAC":                       true,
+	"MAC_autogenerated":         true,
+	"MTU":                       true,
+	"VM":                        true,
+	"allowed_operations":        true,
+	"current_operations":        true,
+	"currently_attached":        true,
+	"device":                    true,
+	"ipv4_addresses":            true,
+	"ipv4_allowed":              true,
+	"ipv4_configuration_mode":   true,
+	"ipv4_gateway":              true,
+	"ipv6_addresses":            true,
+	"ipv6_allowed":              true,
+	"ipv6_configuration_mode":   true,
+	"ipv6_gateway":              true,
+	"locking_mode":              true,
+	"metrics":                   true,
+	"network":                   true,
+	"other_config":              true,
+	"qos__algorithm_params":     true,
+	"qos__algorithm_type":       true,
+	"qos__supported_algorithms": true,
+	"reserved":                  true,
+	"reserved_pci":              true,
+	"runtime_properties":        true,
+	"status_code":               true,
+	"status_detail":             true,
+	"uuid":                      true,
+}
+
+func (o *VIF) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.MAC = field(d, row, "MAC", asString)
+	o.MACAutogenerated = field(d, row, "MAC_autogenerated", asBool)
+	o.MTU = field(d, row, "MTU", asInt)
+	o.VM = field(d, row, "VM", asRef[VM](d))
+	o.AllowedOperations = field(d, row, "allowed_operations", asSet(asString))
+	o.CurrentOperations = field(d, row, "current_operations", asMap(asString))
+	o.CurrentlyAttached = field(d, row, "currently_attached", asBool)
+	o.Device = field(d, row, "device", asString)
+	o.IPv4Addresses = field(d, row, "ipv4_addresses", asSet(asString))
+	o.IPv4Allowed = field(d, row, "ipv4_allowed", asSet(asString))
+	o.IPv4ConfigurationMode = field(d, row, "ipv4_configuration_mode", asString)
+	o.IPv4Gateway = field(d, row, "ipv4_gateway", asString)
+	o.IPv6Addresses = field(d, row, "ipv6_addresses", asSet(asString))
+	o.IPv6Allowed = field(d, row, "ipv6_allowed", asSet(asString))
+	o.IPv6ConfigurationMode = field(d, row, "ipv6_configuration_mode", asString)
+	o.IPv6Gateway = field(d, row, "ipv6_gateway", asString)
+	o.LockingMode = field(d, row, "locking_mode", asString)
+	o.Metrics = field(d, row, "metrics", asString)
+	o.Network = field(d, row, "network", asRef[Network](d))
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.QoSAlgorithmParams = field(d, row, "qos__algorithm_params", asMap(asString))
+	o.QoSAlgorithmType = field(d, row, "qos__algorithm_type", asString)
+	o.QoSSupportedAlgorithms = field(d, row, "qos__supported_algorithms", asSet(asString))
+	o.Reserved = field(d, row, "reserved", asBool)
+	o.ReservedPCI = field(d, row, "reserved_pci", asRef[PCI](d))
+	o.RuntimeProperties = field(d, row, "runtime_properties", asMap(asString))
+	o.StatusCode = field(d, row, "status_code", asInt)
+	o.StatusDetail = field(d, row, "status_detail", asString)
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, vifFields)
+}
+
+// VLAN is an object of the class VLAN. A VLAN mux/demux
+type VLAN struct {
+	Ref string
+
+	// additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// VLAN tag in use (tag)
+	Tag int64
+
+	// interface on which traffic is tagged (tagged_PIF)
+	TaggedPIF Ref[PIF]
+
+	// interface on which traffic is untagged (untagged_PIF)
+	UntaggedPIF Ref[PIF]
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var vlanFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"other_config": true,
+	"tag":          true,
+	"tagged_PIF":   true,
+	"untagged_PIF": true,
+	"uuid":         true,
+}
+
+func (o *VLAN) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.Tag = field(d, row, "tag", asInt)
+	o.TaggedPIF = field(d, row, "tagged_PIF", asRef[PIF](d))
+	o.UntaggedPIF = field(d, row, "untagged_PIF", asRef[PIF](d))
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, vlanFields)
+}
+
+// VM is an object of the class VM. A virtual machine (or 'guest').
+type VM struct {
+	Ref string
+
+	// HVM boot params (HVM__boot_params)
+	HVMBootParams map[string]string
+
+	// HVM boot policy (HVM__boot_policy)
+	HVMBootPolicy string
+
+	// multiplier applied to the amount of shadow that will be made available to
+	// the guest (HVM__shadow_multiplier)
+	HVMShadowMultiplier float64
+
+	// initial value for guest NVRAM (containing UEFI variables, etc). Cannot be
+	// changed while the VM is running (NVRAM)
+	NVRAM map[string]string
+
+	// PCI bus path for pass-through devices (PCI_bus)
+	PCIBus string
+
+	// kernel command-line arguments (PV__args)
+	PVArgs string
+
+	// name of or path to bootloader (PV__bootloader)
+	PVBootloader string
+
+	// miscellaneous arguments for the bootloader (PV__bootloader_args)
+	PVBootloaderArgs string
+
+	// path to the kernel (PV__kernel)
+	PVKernel string
+
+	// to make Zurich guests boot (PV__legacy_args)
+	PVLegacyArgs string
+
+	// path to the initrd (PV__ramdisk)
+	PVRamdisk string
+
+	// virtual block devices (VBDs)
+	VBDs []Ref[VBD]
+
+	// Boot number of VCPUs (VCPUs__at_startup)
+	VCPUsAtStartup int64
+
+	// Max number of VCPUs (VCPUs__max)
+	VCPUsMax int64
+
+	// configuration parameters for the selected VCPU policy (VCPUs__params)
+	VCPUsParams map[string]string
+
+	// Virtual GPUs (VGPUs)
+	VGPUs []Ref[VGPU]
+
+	// virtual network interfaces (VIFs)
+	VIFs []Ref[VIF]
+
+	// virtual TPMs (VTPMs)
+	VTPMs []string
+
+	// virtual usb devices (VUSBs)
+	VUSBs []string
+
+	// action to take if the guest crashes (actions__after_crash)
+	ActionsAfterCrash string
+
+	// action to take after the guest has rebooted itself (actions__after_reboot)
+	ActionsAfterReboot string
+
+	// action to take after the guest has shutdown itself
+	// (actions__after_shutdown)
+	ActionsAfterShutdown string
+
+	// action to take after soft reboot (actions__after_softreboot)
+	ActionsAfterSoftreboot string
+
+	// A host which the VM has some affinity for (or NULL). This is used as a hint
+	// to the start call when it decides where to run the VM. Resource constraints
+	// may cause the VM to be started elsewhere (affinity)
+	Affinity Ref[Host]
+
+	// List of the operations allowed in this state. This list is advisory only
+	// and the server state may have changed by the time this field is read by a
+	// client (allowed_operations)
+	AllowedOperations []string
+
+	// the appliance to which this VM belongs (appliance)
+	Appliance string
+
+	// Currently passed-through PCI devices (attached_PCIs)
+	AttachedPCIs []Ref[PCI]
+
+	// BIOS strings (bios_strings)
+	BiosStrings map[string]string
+
+	// Binary blobs associated with this VM (blobs)
+	Blobs map[string]string
+
+	// List of operations which have been explicitly blocked and an error code
+	// (blocked_operations)
+	BlockedOperations map[string]string
+
+	// List pointing to all the children of this VM (children)
+	Children []Ref[VM]
+
+	// virtual console devices (consoles)
+	Consoles []string
+
+	// crash dumps associated with this VM (crash_dumps)
+	CrashDumps []string
+
+	// Links each of the running tasks using this object (by reference) to a
+	// current_operation enum which describes the nature of the task
+	// (current_operations)
+	CurrentOperations map[string]string
+
+	// The type of domain that will be created when the VM is started
+	// (domain_type)
+	DomainType string
+
+	// Domain architecture (if available, null string otherwise) (domarch)
+	Domarch string
+
+	// domain ID (if available, -1 otherwise) (domid)
+	Domid int64
+
+	// Generation ID of the VM (generation_id)
+	GenerationID string
+
+	// VM groups associated with the VM (groups)
+	Groups []string
+
+	// metrics associated with the running guest (guest_metrics)
+	GuestMetrics Ref[VMGuestMetrics]
+
+	// if true then the system will attempt to keep the VM running as much as
+	// possible (ha_always_run)
+	HAAlwaysRun bool
+
+	// has possible values: "best-effort" meaning "try to restart this VM if
+	// possible but don't consider the Pool to be overcommitted if this is not
+	// possible"; "restart" meaning "this VM should be restarted"; "" meaning "do
+	// not try to restart this VM" (ha_restart_priority)
+	HARestartPriority string
+
+	// The host virtual hardware platform version the VM can run on
+	// (hardware_platform_version)
+	HardwarePlatformVersion int64
+
+	// When an HVM guest starts, this controls the presence of the emulated C000
+	// PCI device which triggers Windows Update to fetch or update PV drivers
+	// (has_vendor_device)
+	HasVendorDevice bool
+
+	// true if this is a snapshot. Snapshotted VMs can never be started, they are
+	// used only for cloning other VMs (is_a_snapshot)
+	IsASnapshot bool
+
+	// true if this is a template. Template VMs can never be started, they are
+	// used only for cloning other VMs (is_a_template)
+	IsATemplate bool
+
+	// true if this is a control domain (domain 0 or a driver domain)
+	// (is_control_domain)
+	IsControlDomain bool
+
+	// true if this is a default template. Default template VMs can never be
+	// started or migrated, they are used only for cloning other VMs
+	// (is_default_template)
+	IsDefaultTemplate bool
+
+	// true if this snapshot was created by the protection policy
+	// (is_snapshot_from_vmpp)
+	IsSnapshotFromVMPP bool
+
+	// true if this snapshot was created by the snapshot schedule
+	// (is_vmss_snapshot)
+	IsVMSSSnapshot bool
+
+	// describes the CPU flags on which the VM was last booted
+	// (last_boot_CPU_flags)
+	LastBootCPUFlags map[string]string
+
+	// marshalled value containing VM record at time of last boot
+	// (last_booted_record)
+	LastBootedRecord string
+
+	// Dynamic maximum (bytes) (memory__dynamic_max)
+	MemoryDynamicMax int64
+
+	// Dynamic minimum (bytes) (memory__dynamic_min)
+	MemoryDynamicMin int64
+
+	// Virtualization memory overhead (bytes) (memory__overhead)
+	MemoryOverhead int64
+
+	// Statically-set (i.e. absolute) maximum (bytes). The value of this field at
+	// VM start time acts as a hard limit of the amount of memory a guest can use.
+	// New values only take effect on reboot (memory__static_max)
+	MemoryStaticMax int64
+
+	// Statically-set (i.e. absolute) mininum (bytes). The value of this field
+	// indicates the least amount of memory this VM can boot with without crashing
+	// (memory__static_min)
+	MemoryStaticMin int64
+
+	// Dynamically-set memory target (bytes). The value of this field indicates
+	// the current target for memory available to this VM (memory__target)
+	MemoryTarget int64
+
+	// metrics associated with this VM (metrics)
+	Metrics Ref[VMMetrics]
+
+	// A notes field containing human-readable description (name__description)
+	NameDescription string
+
+	// A human-readable name (name__label)
+	NameLabel string
+
+	// The point in the startup or shutdown sequence at which this VM will be
+	// started (order)
+	Order int64
+
+	// Additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// Ref pointing to the parent of this VM (parent)
+	Parent Ref[VM]
+
+	// The set of pending mandatory guidances after applying updates, which must
+	// be applied, as otherwise there may be e.g. VM failures (pending_guidances)
+	PendingGuidances []string
+
+	// The set of pending full guidances after applying updates, which a user
+	// should follow to make some updates, e.g. specific hardware drivers or CPU
+	// features, fully effective, but the 'average user' doesn't need to
+	// (pending_guidances_full)
+	PendingGuidancesFull []string
+
+	// The set of pending recommended guidances after applying updates, which most
+	// users should follow to make the updates effective, but if not followed,
+	// will not cause a failure (pending_guidances_recommended)
+	PendingGuidancesRecommended []string
+
+	// platform-specific configuration (platform)
+	Platform map[string]string
+
+	// Current power state of the machine (power_state)
+	PowerState string
+
+	// Ref pointing to a protection policy for this VM (protection_policy)
+	ProtectionPolicy string
+
+	// An XML specification of recommended values and ranges for properties of
+	// this VM (recommendations)
+	Recommendations string
+
+	// Textual reference to the template used to create a VM. This can be used by
+	// clients in need of an immutable reference to the template since the
+	// latter's uuid and name_label may change, for example, after a package
+	// installation or upgrade (reference_label)
+	ReferenceLabel string
+
+	// Indicates whether a VM requires a reboot in order to update its
+	// configuration, e.g. its memory allocation (requires_reboot)
+	RequiresReboot bool
+
+	// the host the VM is currently resident on (resident_on)
+	ResidentOn Ref[Host]
+
+	// the host on which the VM is due to be started/resumed/migrated. This acts
+	// as a memory reservation indicator (scheduled_to_be_resident_on)
+	ScheduledToBeResidentOn Ref[Host]
+
+	// The delay to wait before proceeding to the next order in the shutdown
+	// sequence (seconds) (shutdown_delay)
+	ShutdownDelay int64
+
+	// Human-readable information concerning this snapshot (snapshot_info)
+	SnapshotInfo map[string]string
+
+	// Encoded information about the VM's metadata this is a snapshot of
+	// (snapshot_metadata)
+	SnapshotMetadata string
+
+	// Ref pointing to the VM this snapshot is of (snapshot_of)
+	SnapshotOf Ref[VM]
+
+	// Ref pointing to a snapshot schedule for this VM (snapshot_schedule)
+	SnapshotSchedule string
+
+	// Date/time when this snapshot was created (snapshot_time)
+	SnapshotTime time.Time
+
+	// List pointing to all the VM snapshots (snapshots)
+	Snapshots []Ref[VM]
+
+	// The delay to wait before proceeding to the next order in the startup
+	// sequence (seconds) (start_delay)
+	StartDelay int64
+
+	// The SR on which a suspend image is stored (suspend_SR)
+	SuspendSR Ref[SR]
+
+	// The VDI that a suspend image is stored on. (Only has meaning if VM is
+	// currently suspended) (suspend_VDI)
+	SuspendVDI Ref[VDI]
+
+	// user-specified tags for categorization purposes (tags)
+	Tags []string
+
+	// Transportable ID of the snapshot VM (transportable_snapshot_id)
+	TransportableSnapshotID string
+
+	// Creators of VMs and templates may store version information here
+	// (user_version)
+	UserVersion int64
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// The number of times this VM has been recovered (version)
+	Version int64
+
+	// data to be inserted into the xenstore tree (/local/domain/<domid>/vm-data)
+	// after the VM is created (xenstore_data)
+	XenstoreData map[string]string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var vmFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"HVM__boot_params":              true,
+	"HVM__boot_policy":              true,
+	"HVM__shadow_multiplier":        true,
+	"NVRAM":                         true,
+	"PCI_bus":                       true,
+	"PV__args":                      true,
+	"PV__bootloader":                true,
+	"PV__bootloader_args":           true,
+	"PV__kernel":                    true,
+	"PV__legacy_args":               true,
+	"PV__ramdisk":                   true,
+	"VBDs":                          true,
+	"VCPUs__at_startup":             true,
+	"VCPUs__max":                    true,
+	"VCPUs__params":                 true,
+	"VGPUs":                         true,
+	"VIFs":                          true,
+	"VTPMs":                         true,
+	"VUSBs":                         true,
+	"actions__after_crash":          true,
+	"actions__after_reboot":         true,
+	"actions__after_shutdown":       true,
+	"actions__after_softreboot":     true,
+	"affinity":                      true,
+	"allowed_operations":            true,
+	"appliance":                     true,
+	"attached_PCIs":                 true,
+	"bios_strings":                  true,
+	"blobs":                         true,
+	"blocked_operations":            true,
+	"children":                      true,
+	"consoles":                      true,
+	"crash_dumps":                   true,
+	"current_operations":            true,
+	"domain_type":                   true,
+	"domarch":                       true,
+	"domid":                         true,
+	"generation_id":                 true,
+	"groups":                        true,
+	"guest_metrics":                 true,
+	"ha_always_run":                 true,
+	"ha_restart_priority":           true,
+	"hardware_platform_version":     true,
+	"has_vendor_device":             true,
+	"is_a_snapshot":                 true,
+	"is_a_template":                 true,
+	"is_control_domain":             true,
+	"is_default_template":           true,
+	"is_snapshot_from_vmpp":         true,
+	"is_vmss_snapshot":              true,
+	"last_boot_CPU_flags":           true,
+	"last_booted_record":            true,
+	"memory__dynamic_max":           true,
+	"memory__dynamic_min":           true,
+	"memory__overhead":              true,
+	"memory__static_max":            true,
+	"memory__static_min":            true,
+	"memory__target":                true,
+	"metrics":                       true,
+	"name__description":             true,
+	"name__label":                   true,
+	"order":                         true,
+	"other_config":                  true,
+	"parent":                        true,
+	"pending_guidances":             true,
+	"pending_guidances_full":        true,
+	"pending_guidances_recommended": true,
+	"platform":                      true,
+	"power_state":                   true,
+	"protection_policy":             true,
+	"recommendations":               true,
+	"reference_label":               true,
+	"requires_reboot":               true,
+	"resident_on":                   true,
+	"scheduled_to_be_resident_on":   true,
+	"shutdown_delay":                true,
+	"snapshot_info":                 true,
+	"snapshot_metadata":             true,
+	"snapshot_of":                   true,
+	"snapshot_schedule":             true,
+	"snapshot_time":                 true,
+	"snapshots":                     true,
+	"start_delay":                   true,
+	"suspend_SR":                    true,
+	"suspend_VDI":                   true,
+	"tags":                          true,
+	"transportable_snapshot_id":     true,
+	"user_version":                  true,
+	"uuid":                          true,
+	"version":                       true,
+	"xenstore_data":                 true,
+}
+
+func (o *VM) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.HVMBootParams = field(d, row, "HVM__boot_params", asMap(asString))
+	o.HVMBootPolicy = field(d, row, "HVM__boot_policy", asString)
+	o.HVMShadowMultiplier = field(d, row, "HVM__shadow_multiplier", asFloat)
+	o.NVRAM = field(d, row, "NVRAM", asMap(asString))
+	o.PCIBus = field(d, row, "PCI_bus", asString)
+	o.PVArgs = field(d, row, "PV__args", asString)
+	o.PVBootloader = field(d, row, "PV__bootloader", asString)
+	o.PVBootloaderArgs = field(d, row, "PV__bootloader_args", asString)
+	o.PVKernel = field(d, row, "PV__kernel", asString)
+	o.PVLegacyArgs = field(d, row, "PV__legacy_args", asString)
+	o.PVRamdisk = field(d, row, "PV__ramdisk", asString)
+	o.VBDs = field(d, row, "VBDs", asSet(asRef[VBD](d)))
+	o.VCPUsAtStartup = field(d, row, "VCPUs__at_startup", asInt)
+	o.VCPUsMax = field(d, row, "VCPUs__max", asInt)
+	o.VCPUsParams = field(d, row, "VCPUs__params", asMap(asString))
+	o.VGPUs = field(d, row, "VGPUs", asSet(asRef[VGPU](d)))
+	o.VIFs = field(d, row, "VIFs", asSet(asRef[VIF](d)))
+	o.VTPMs = field(d, row, "VTPMs", asSet(asString))
+	o.VUSBs = field(d, row, "VUSBs", asSet(asString))
+	o.ActionsAfterCrash = field(d, row, "actions__after_crash", asString)
+	o.ActionsAfterReboot = field(d, row, "actions__after_reboot", asString)
+	o.ActionsAfterShutdown = field(d, row, "actions__after_shutdown", asString)
+	o.ActionsAfterSoftreboot = field(d, row, "actions__after_softreboot", asString)
+	o.Affinity = field(d, row, "affinity", asRef[Host](d))
+	o.AllowedOperations = field(d, row, "allowed_operations", asSet(asString))
+	o.Appliance = field(d, row, "appliance", asString)
+	o.AttachedPCIs = field(d, row, "attached_PCIs", asSet(asRef[PCI](d)))
+	o.BiosStrings = field(d, row, "bios_strings", asMap(asString))
+	o.Blobs = field(d, row, "blobs", asMap(asString))
+	o.BlockedOperations = field(d, row, "blocked_operations", asMap(asString))
+	o.Children = field(d, row, "children", asSet(asRef[VM](d)))
+	o.Consoles = field(d, row, "consoles", asSet(asString))
+	o.CrashDumps = field(d, row, "crash_dumps", asSet(asString))
+	o.CurrentOperations = field(d, row, "current_operations", asMap(asString))
+	o.DomainType = field(d, row, "domain_type", asString)
+	o.Domarch = field(d, row, "domarch", asString)
+	o.Domid = field(d, row, "domid", asInt)
+	o.GenerationID = field(d, row, "generation_id", asString)
+	o.Groups = field(d, row, "groups", asSet(asString))
+	o.GuestMetrics = field(d, row, "guest_metrics", asRef[VMGuestMetrics](d))
+	o.HAAlwaysRun = field(d, row, "ha_always_run", asBool)
+	o.HARestartPriority = field(d, row, "ha_restart_priority", asString)
+	o.HardwarePlatformVersion = field(d, row, "hardware_platform_version", asInt)
+	o.HasVendorDevice = field(d, row, "has_vendor_device", asBool)
+	o.IsASnapshot = field(d, row, "is_a_snapshot", asBool)
+	o.IsATemplate = field(d, row, "is_a_template", asBool)
+	o.IsControlDomain = field(d, row, "is_control_domain", asBool)
+	o.IsDefaultTemplate = field(d, row, "is_default_template", asBool)
+	o.IsSnapshotFromVMPP = field(d, row, "is_snapshot_from_vmpp", asBool)
+	o.IsVMSSSnapshot = field(d, row, "is_vmss_snapshot", asBool)
+	o.LastBootCPUFlags = field(d, row, "last_boot_CPU_flags", asMap(asString))
+	o.LastBootedRecord = field(d, row, "last_booted_record", asString)
+	o.MemoryDynamicMax = field(d, row, "memory__dynamic_max", asInt)
+	o.MemoryDynamicMin = field(d, row, "memory__dynamic_min", asInt)
+	o.MemoryOverhead = field(d, row, "memory__overhead", asInt)
+	o.MemoryStaticMax = field(d, row, "memory__static_max", asInt)
+	o.MemoryStaticMin = field(d, row, "memory__static_min", asInt)
+	o.MemoryTarget = field(d, row, "memory__target", asInt)
+	o.Metrics = field(d, row, "metrics", asRef[VMMetrics](d))
+	o.NameDescription = field(d, row, "name__description", asString)
+	o.NameLabel = field(d, row, "name__label", asString)
+	o.Order = field(d, row, "order", asInt)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.Parent = field(d, row, "parent", asRef[VM](d))
+	o.PendingGuidances = field(d, row, "pending_guidances", asSet(asString))
+	o.PendingGuidancesFull = field(d, row, "pending_guidances_full", asSet(asString))
+	o.PendingGuidancesRecommended = field(d, row, "pending_guidances_recommended", asSet(asString))
+	o.Platform = field(d, row, "platform", asMap(asString))
+	o.PowerState = field(d, row, "power_state", asString)
+	o.ProtectionPolicy = field(d, row, "protection_policy", asString)
+	o.Recommendations = field(d, row, "recommendations", asString)
+	o.ReferenceLabel = field(d, row, "reference_label", asString)
+	o.RequiresReboot = field(d, row, "requires_reboot", asBool)
+	o.ResidentOn = field(d, row, "resident_on", asRef[Host](d))
+	o.ScheduledToBeResidentOn = field(d, row, "scheduled_to_be_resident_on", asRef[Host](d))
+	o.ShutdownDelay = field(d, row, "shutdown_delay", asInt)
+	o.SnapshotInfo = field(d, row, "snapshot_info", asMap(asString))
+	o.SnapshotMetadata = field(d, row, "snapshot_metadata", asString)
+	o.SnapshotOf = field(d, row, "snapshot_of", asRef[VM](d))
+	o.SnapshotSchedule = field(d, row, "snapshot_schedule", asString)
+	o.SnapshotTime = field(d, row, "snapshot_time", asTime)
+	o.Snapshots = field(d, row, "snapshots", asSet(asRef[VM](d)))
+	o.StartDelay = field(d, row, "start_delay", asInt)
+	o.SuspendSR = field(d, row, "suspend_SR", asRef[SR](d))
+	o.SuspendVDI = field(d, row, "suspend_VDI", asRef[VDI](d))
+	o.Tags = field(d, row, "tags", asSet(asString))
+	o.TransportableSnapshotID = field(d, row, "transportable_snapshot_id", asString)
+	o.UserVersion = field(d, row, "user_version", asInt)
+	o.UUID = field(d, row, "uuid", asString)
+	o.Version = field(d, row, "version", asInt)
+	o.XenstoreData = field(d, row, "xenstore_data", asMap(asString))
+	o.Extras = extras(row, vmFields)
+}
+
+// VMGuestMetrics is an object of the class VM_guest_metrics. The metrics
+// reported by the guest (as opposed to inferred from outside)
+type VMGuestMetrics struct {
+	Ref string
+
+	// At least one of the guest's devices has successfully connected to the
+	// backend (PV_drivers_detected)
+	PVDriversDetected bool
+
+	// Logically equivalent to PV_drivers_detected (PV_drivers_up_to_date)
+	PVDriversUpToDate bool
+
+	// version of the PV drivers (PV_drivers_version)
+	PVDriversVersion map[string]string
+
+	// The guest's statement of whether it supports VBD hotplug, i.e. whether it
+	// is capable of responding immediately to instantiation of a new VBD by
+	// bringing online a new PV block device. If the guest states that it is not
+	// capable, then the VBD plug and unplug operations will not be allowed while
+	// the guest is running (can_use_hotplug_vbd)
+	CanUseHotplugVBD string
+
+	// The guest's statement of whether it supports VIF hotplug, i.e. whether it
+	// is capable of responding immediately to instantiation of a new VIF by
+	// bringing online a new PV network device. If the guest states that it is not
+	// capable, then the VIF plug and unplug operations will not be allowed while
+	// the guest is running (can_use_hotplug_vif)
+	CanUseHotplugVIF string
+
+	// This field exists but has no data (disks)
+	Disks map[string]string
+
+	// Time at which this information was last updated (last_updated)
+	LastUpdated time.Time
+
+	// True if the guest is sending heartbeat messages via the guest agent (live)
+	Live bool
+
+	// This field exists but has no data. Use the memory and memory_internal_free
+	// RRD data-sources instead (memory)
+	Memory map[string]string
+
+	// The NETBIOS name of the machine (netbios_name)
+	NetbiosName map[string]string
+
+	// network configuration (networks)
+	Networks map[string]string
+
+	// version of the OS (os_version)
+	OSVersion map[string]string
+
+	// anything else (other)
+	Other map[string]string
+
+	// additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// The services running in the guest (services)
+	Services map[string]string
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var vmguestmetricsFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"PV_drivers_detected":   true,
+	"PV_drivers_up_to_date": true,
+	"PV_drivers_version":    true,
+	"can_use_hotplug_vbd":   true,
+	"can_use_hotplug_vif":   true,
+	"disks":                 true,
+	"last_updated":          true,
+	"live":                  true,
+	"memory":                true,
+	"netbios_name":          true,
+	"networks":              true,
+	"os_version":            true,
+	"other":                 true,
+	"other_config":          true,
+	"services":              true,
+	"uuid":                  true,
+}
+
+func (o *VMGuestMetrics) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.PVDriversDetected = field(d, row, "PV_drivers_detected", asBool)
+	o.PVDriversUpToDate = field(d, row, "PV_drivers_up_to_date", asBool)
+	o.PVDriversVersion = field(d, row, "PV_drivers_version", asMap(asString))
+	o.CanUseHotplugVBD = field(d, row, "can_use_hotplug_vbd", asString)
+	o.CanUseHotplugVIF = field(d, row, "can_use_hotplug_vif", asString)
+	o.Disks = field(d, row, "disks", asMap(asString))
+	o.LastUpdated = field(d, row, "last_updated", asTime)
+	o.Live = field(d, row, "live", asBool)
+	o.Memory = field(d, row, "memory", asMap(asString))
+	o.NetbiosName = field(d, row, "netbios_name", asMap(asString))
+	o.Networks = field(d, row, "networks", asMap(asString))
+	o.OSVersion = field(d, row, "os_version", asMap(asString))
+	o.Other = field(d, row, "other", asMap(asString))
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.Services = field(d, row, "services", asMap(asString))
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, vmguestmetricsFields)
+}
+
+// VMMetrics is an object of the class VM_metrics. The metrics associated with
+// a VM
+type VMMetrics struct {
+	Ref string
+
+	// VCPU to PCPU map (VCPUs__CPU)
+	VCPUsCPU map[string]int64
+
+	// CPU flags (blocked,online,running) (VCPUs__flags)
+	VCPUsFlags map[string][]string
+
+	// Current number of VCPUs (VCPUs__number)
+	VCPUsNumber int64
+
+	// The live equivalent to VM.VCPUs_params (VCPUs__params)
+	VCPUsParams map[string]string
+
+	// Utilisation for all of guest's current VCPUs (VCPUs__utilisation)
+	VCPUsUtilisation map[string]float64
+
+	// The current domain type of the VM (for running,suspended, or paused VMs).
+	// The last-known domain type for halted VMs (current_domain_type)
+	CurrentDomainType string
+
+	// hardware virtual machine (hvm)
+	HVM bool
+
+	// Time at which the VM was installed (install_time)
+	InstallTime time.Time
+
+	// Time at which this information was last updated (last_updated)
+	LastUpdated time.Time
+
+	// Guest's actual memory (bytes) (memory__actual)
+	MemoryActual int64
+
+	// VM supports nested virtualisation (nested_virt)
+	NestedVirt bool
+
+	// VM is immobile and can't migrate between hosts (nomigrate)
+	Nomigrate bool
+
+	// additional configuration (other_config)
+	OtherConfig map[string]string
+
+	// Time at which this VM was last booted (start_time)
+	StartTime time.Time
+
+	// The state of the guest, eg blocked, dying etc (state)
+	State []string
+
+	// Unique identifier/object reference (uuid)
+	UUID string
+
+	// Fields not in the schema, as stored in the database
+	Extras map[string]string
+}
+
+var vmmetricsFields = map[string]bool{
+	"ref": true, "_ref": true, "__ctime": true, "__mtime": true,
+	"VCPUs__CPU":          true,
+	"VCPUs__flags":        true,
+	"VCPUs__number":       true,
+	"VCPUs__params":       true,
+	"VCPUs__utilisation":  true,
+	"current_domain_type": true,
+	"hvm":                 true,
+	"install_time":        true,
+	"last_updated":        true,
+	"memory__actual":      true,
+	"nested_virt":         true,
+	"nomigrate":           true,
+	"other_config":        true,
+	"start_time":          true,
+	"state":               true,
+	"uuid":                true,
+}
+
+func (o *VMMetrics) decode(d *decoder, row *Row) {
+	o.Ref = row.Ref()
+	o.VCPUsCPU = field(d, row, "VCPUs__CPU", asMap(asInt))
+	o.VCPUsFlags = field(d, row, "VCPUs__flags", asMap(asSet(asString)))
+	o.VCPUsNumber = field(d, row, "VCPUs__number", asInt)
+	o.VCPUsParams = field(d, row, "VCPUs__params", asMap(asString))
+	o.VCPUsUtilisation = field(d, row, "VCPUs__utilisation", asMap(asFloat))
+	o.CurrentDomainType = field(d, row, "current_domain_type", asString)
+	o.HVM = field(d, row, "hvm", asBool)
+	o.InstallTime = field(d, row, "install_time", asTime)
+	o.LastUpdated = field(d, row, "last_updated", asTime)
+	o.MemoryActual = field(d, row, "memory__actual", asInt)
+	o.NestedVirt = field(d, row, "nested_virt", asBool)
+	o.Nomigrate = field(d, row, "nomigrate", asBool)
+	o.OtherConfig = field(d, row, "other_config", asMap(asString))
+	o.StartTime = field(d, row, "start_time", asTime)
+	o.State = field(d, row, "state", asSet(asString))
+	o.UUID = field(d, row, "uuid", asString)
+	o.Extras = extras(row, vmmetricsFields)
+}
@@ -0,0 +1,238 @@
+package xapidb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"example.com/readxapidb/internal/schema"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// NullRef is the value of references that don't point to an object.
+const NullRef = xapidb.NullRef
+
+var (
+	// ErrNullRef is returned when resolving NullRef or an empty reference.
+	ErrNullRef = errors.New("null reference")
+	// ErrNotFound is returned when resolving a reference to an object that
+	// is not in the database (dangling references are common, objects
+	// like sessions are not persisted).
+	ErrNotFound = errors.New("object not found")
+	// ErrUnknownField is returned when decoding a field that is not in the
+	// schema.
+	ErrUnknownField = errors.New("field not in the schema")
+)
+
+// defaultSchema is parsed once for all the databases.
+var defaultSchema = sync.OnceValue(schema.Default)
+
+// DB is a parsed database.
+type DB struct {
+	db     *xapidb.DB
+	schema *schema.Schema
+}
+
+// Open parses the database file at path.
+func Open(path string) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	db, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Read parses a database while reading it from r.
+func Read(r io.Reader) (*DB, error) {
+	db, err := xapidb.ReadXapiDB(r)
+	if err != nil {
+		return nil, err
+	}
+	return &DB{db: db, schema: defaultSchema()}, nil
+}
+
+// Parse parses a database in memory.
+func Parse(data []byte) (*DB, error) {
+	db, err := xapidb.ParseXapiDB(data)
+	if err != nil {
+		return nil, err
+	}
+	return &DB{db: db, schema: defaultSchema()}, nil
+}
+
+// LoadSchema makes db decode the fields with the embedded schema updated
+// with the JSON schema file at path, see the Readme for its format.
+func (db *DB) LoadSchema(path string) error {
+	s, err := schema.Load(path)
+	if err != nil {
+		return err
+	}
+	db.schema = s
+	return nil
+}
+
+// Manifest returns the pairs of the manifest of the database
+// (schema_major_vsn, schema_minor_vsn, generation_count).
+func (db *DB) Manifest() map[string]string {
+	return db.db.Manifest()
+}
+
+// SchemaVersion returns the version of the schema of the database, ok is
+// false if the manifest doesn't have it.
+func (db *DB) SchemaVersion() (major, minor int, ok bool) {
+	m := db.Manifest()
+	major, errMajor := strconv.Atoi(m["schema_major_vsn"])
+	minor, errMinor := strconv.Atoi(m["schema_minor_vsn"])
+	return major, minor, errMajor == nil && errMinor == nil
+}
+
+// GenerationCount returns the generation count of the database, or -1 if it
+// is unknown. Rows have the generations at which they were created and
+// last modified in their __ctime and __mtime fields.
+func (db *DB) GenerationCount() int64 {
+	return db.db.GenerationCount()
+}
+
+// Tables returns the tables in the order of the file, empty ones included.
+func (db *DB) Tables() iter.Seq[*Table] {
+	return func(yield func(*Table) bool) {
+		for _, n := range db.db.Root.Children {
+			if n.Name == "table" && !yield(&Table{db: db, node: n}) {
+				return
+			}
+		}
+	}
+}
+
+// Table returns the table of a class, names are compared without case (vm
+// and VM). It returns nil if there is no such table.
+func (db *DB) Table(name string) *Table {
+	n := db.db.Table(name)
+	if n == nil {
+		return nil
+	}
+	return &Table{db: db, node: n}
+}
+
+// Rows returns the rows of all the tables.
+func (db *DB) Rows() iter.Seq[*Row] {
+	return func(yield func(*Row) bool) {
+		for t := range db.Tables() {
+			for r := range t.Rows() {
+				if !yield(r) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Row returns the row with the given reference, or nil if there is none.
+func (db *DB) Row(ref string) *Row {
+	n, ok := db.db.Lookup(ref)
+	if !ok {
+		return nil
+	}
+	return db.row(n)
+}
+
+// ByUUID returns the row with the given uuid, or nil if there is none.
+func (db *DB) ByUUID(uuid string) *Row {
+	ref, ok := db.db.RefByUUID(uuid)
+	if !ok {
+		return nil
+	}
+	return db.Row(ref)
+}
+
+// Resolve returns the row referenced by ref. It fails with ErrNullRef for
+// null references and ErrNotFound for references to missing objects.
+func (db *DB) Resolve(ref string) (*Row, error) {
+	if ref == "" || ref == NullRef {
+		return nil, ErrNullRef
+	}
+	r := db.Row(ref)
+	if r == nil {
+		return nil, fmt.Errorf("%s: %w", ref, ErrNotFound)
+	}
+	return r, nil
+}
+
+// Referrers returns the rows with a field holding ref, sorted by reference.
+func (db *DB) Referrers(ref string) []*Row {
+	rows := []*Row{}
+	for _, r := range db.db.Referrers(ref) {
+		if row := db.Row(r); row != nil {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Ref() < rows[j].Ref() })
+	return rows
+}
+
+func (db *DB) row(n *xapidb.Node) *Row {
+	return &Row{db: db, node: n}
+}
+
+// Table is the table of a class.
+type Table struct {
+	db   *DB
+	node *xapidb.Node
+}
+
+// Name returns the name of the table, the name of its class.
+func (t *Table) Name() string {
+	return t.node.Attr["name"]
+}
+
+// Doc returns the documentation of the class from the schema.
+func (t *Table) Doc() string {
+	if c := t.db.schema.Class(t.Name()); c != nil {
+		return c.Doc
+	}
+	return ""
+}
+
+// Len returns the number of rows.
+func (t *Table) Len() int {
+	return t.node.Len()
+}
+
+// Rows returns the rows of the table in the order of the file.
+func (t *Table) Rows() iter.Seq[*Row] {
+	return func(yield func(*Row) bool) {
+		// Tables of a missing class are nil so callers can chain
+		// db.Table(name).Rows()
+		if t == nil {
+			return
+		}
+		for _, n := range t.node.Children {
+			if !yield(t.db.row(n)) {
+				return
+			}
+		}
+	}
+}
+
+// Where returns the rows whose field has the given value, compared with the
+// value as stored in the database unescaped.
+func (t *Table) Where(field, value string) iter.Seq[*Row] {
+	return func(yield func(*Row) bool) {
+		for r := range t.Rows() {
+			if v, ok := r.Raw(field); ok && xapidb.UnescapeValue(v) == value && !yield(r) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Package xapidb reads XAPI database files (the state.db of XCP-ng and
+// XenServer hosts) and gives access to their objects.
+//
+// A database has tables named after the XAPI classes (VM, VBD, host, ...)
+// with a row per object. Rows are identified by their reference
+// (OpaqueRef:...) and most of them by their uuid. The fields of a row are
+// decoded with the embedded XAPI schema, so sets, maps, numbers and dates
+// come back as Go values. Fields are named like in the file, the ones of
+// namespaces are joined by a double underscore (name__label,
+// memory__static_max) where the API uses a single one:
+//
+//	db, err := xapidb.Open("/var/lib/xcp/state.db")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	for vm := range db.Table("VM").Rows() {
+//		if dom0, _ := vm.Bool("is_control_domain"); dom0 {
+//			continue
+//		}
+//		memory, _ := vm.Int("memory__static_max")
+//		fmt.Printf("%s %s %d MiB\n", vm.UUID(), vm.Label(), memory>>20)
+//	}
+//
+// References are resolved to the rows they point to, like following them in
+// readxapidb:
+//
+//	vm := db.ByUUID("3f8d1c5e-...")
+//	for _, vbd := range vm.Follow("VBDs") {
+//		vdi, err := db.Resolve(vbd.String("VDI"))
+//		if err != nil {
+//			continue // empty CD drive or dangling reference
+//		}
+//		size, _ := vdi.Int("virtual_size")
+//		fmt.Println(vbd.String("device"), vdi.Label(), size)
+//	}
+//
+// The rows referencing an object are found with Referrers:
+//
+//	for _, r := range db.Referrers(vm.Ref()) {
+//		fmt.Println(r.Class(), r.Label())
+//	}
+//
+// Objects returns the core classes as typed structs instead, with the
+// references resolved to the structs they point to:
+//
+//	objects, err := db.Objects()
+//	for _, vm := range objects.VMs {
+//		if host := vm.ResidentOn.Target; host != nil {
+//			fmt.Println(vm.NameLabel, "runs on", host.NameLabel)
+//		}
+//	}
+//
+// A DB is read only and can be used by several goroutines.
+package xapidb
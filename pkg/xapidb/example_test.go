@@ -0,0 +1,127 @@
+package xapidb_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"example.com/readxapidb/pkg/xapidb"
+)
+
+// stateDB is a small pool: a host running dom0 and a VM with a disk and an
+// empty CD drive.
+const stateDB = `<?xml version="1.0" encoding="UTF-8"?>
+<database>
+  <manifest>
+    <pair key="schema_major_vsn" value="5"/>
+    <pair key="schema_minor_vsn" value="790"/>
+    <pair key="generation_count" value="1234"/>
+  </manifest>
+  <table name="VBD">
+    <row ref="OpaqueRef:b2" _ref="OpaqueRef:b2" uuid="b2" VM="OpaqueRef:v1" VDI="OpaqueRef:NULL" device="xvdd" type="CD"/>
+    <row ref="OpaqueRef:b1" _ref="OpaqueRef:b1" uuid="b1" VM="OpaqueRef:v1" VDI="OpaqueRef:d1" device="xvda" type="Disk"/>
+  </table>
+  <table name="VDI">
+    <row ref="OpaqueRef:d1" _ref="OpaqueRef:d1" uuid="d1" name__label="debian%.root" virtual_size="21474836480" VBDs="('OpaqueRef:b1')"/>
+  </table>
+  <table name="VM">
+    <row ref="OpaqueRef:v0" _ref="OpaqueRef:v0" uuid="v0" name__label="Control%.domain%.on%.host:%.xcp1" is_control_domain="true"
+      memory__static_max="4294967296" resident_on="OpaqueRef:h1" VBDs="()" tags="()"/>
+    <row ref="OpaqueRef:v1" _ref="OpaqueRef:v1" uuid="v1" name__label="debian" is_control_domain="false"
+      memory__static_max="2147483648" resident_on="OpaqueRef:h1" VBDs="('OpaqueRef:b1'%.'OpaqueRef:b2')"
+      tags="('web'%.'prod')" other_config="(('base_template_name'%.'Debian%.Bookworm%.12'))"/>
+  </table>
+  <table name="host">
+    <row ref="OpaqueRef:h1" _ref="OpaqueRef:h1" uuid="h1" name__label="xcp1" API_version__major="2" API_version__minor="21"
+      resident_VMs="('OpaqueRef:v0'%.'OpaqueRef:v1')" control_domain="OpaqueRef:v0"/>
+  </table>
+</database>`
+
+func Example() {
+	db, err := xapidb.Parse([]byte(stateDB))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for vm := range db.Table("VM").Rows() {
+		if dom0, _ := vm.Bool("is_control_domain"); dom0 {
+			continue
+		}
+		memory, _ := vm.Int("memory__static_max")
+		fmt.Printf("%s %s %d MiB\n", vm.UUID(), vm.Label(), memory>>20)
+	}
+	// Output: v1 debian 2048 MiB
+}
+
+func ExampleRow_Follow() {
+	db, _ := xapidb.Parse([]byte(stateDB))
+
+	vm := db.ByUUID("v1")
+	for _, vbd := range vm.Follow("VBDs") {
+		vdi, err := db.Resolve(vbd.String("VDI"))
+		if errors.Is(err, xapidb.ErrNullRef) {
+			fmt.Println(vbd.String("device"), "empty")
+			continue
+		}
+		size, _ := vdi.Int("virtual_size")
+		fmt.Println(vbd.String("device"), vdi.Label(), size>>30, "GiB")
+	}
+	// Output:
+	// xvda debian root 20 GiB
+	// xvdd empty
+}
+
+func ExampleDB_Referrers() {
+	db, _ := xapidb.Parse([]byte(stateDB))
+
+	for _, r := range db.Referrers("OpaqueRef:v1") {
+		fmt.Println(r.Class(), r.Ref())
+	}
+	// Output:
+	// VBD OpaqueRef:b1
+	// VBD OpaqueRef:b2
+	// host OpaqueRef:h1
+}
+
+func ExampleRow_Field() {
+	db, _ := xapidb.Parse([]byte(stateDB))
+	vm := db.Row("OpaqueRef:v1")
+
+	tags, _ := vm.Set("tags")
+	config, _ := vm.Map("other_config")
+	fmt.Println(tags, config["base_template_name"])
+
+	for _, field := range []string{"memory__static_max", "is_control_domain", "tags"} {
+		v, _ := vm.Field(field)
+		fmt.Printf("%s %T\n", field, v)
+	}
+
+	_, err := vm.Field("memory_static_max")
+	fmt.Println(errors.Is(err, xapidb.ErrUnknownField))
+	// Output:
+	// [web prod] Debian Bookworm 12
+	// memory__static_max int64
+	// is_control_domain bool
+	// tags []interface {}
+	// true
+}
+
+func ExampleDB_Objects() {
+	db, _ := xapidb.Parse([]byte(stateDB))
+
+	objects, err := db.Objects()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, vm := range objects.VMs {
+		if host := vm.ResidentOn.Target; host != nil {
+			fmt.Println(vm.NameLabel, "runs on", host.NameLabel, "with", vm.MemoryStaticMax>>20, "MiB")
+		}
+	}
+	host := objects.Hosts[0]
+	fmt.Printf("XAPI %d.%d\n", host.APIVersionMajor, host.APIVersionMinor)
+	// Output:
+	// Control domain on host: xcp1 runs on xcp1 with 4096 MiB
+	// debian runs on xcp1 with 2048 MiB
+	// XAPI 2.21
+}
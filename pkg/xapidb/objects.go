@@ -0,0 +1,167 @@
+package xapidb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+//go:generate go run ../../cmd/xapitypes -o classes_gen.go
+
+// Ref is a reference to an object of class T. Target is the object if it is
+// in the database, nil for null and dangling references.
+type Ref[T any] struct {
+	Ref    string
+	Target *T
+}
+
+// IsNull returns true if the reference doesn't point to an object.
+func (r Ref[T]) IsNull() bool {
+	return r.Ref == "" || r.Ref == NullRef
+}
+
+// Objects returns the objects of the core classes of the database as Go
+// structs generated from the schema by cmd/xapitypes (classes_gen.go). The
+// fields are named after the ones of the schema (name__label is NameLabel)
+// and typed:
+//
+//	string, enum       -> string
+//	int                -> int64
+//	float              -> float64
+//	bool               -> bool
+//	datetime           -> time.Time
+//	ref of a struct    -> Ref[T] (ref of another class -> string)
+//	set                -> []T
+//	map                -> map[string]T (keys are kept as strings)
+//
+// The fields of the rows that are not in the structs (added by a newer
+// XAPI) are kept in their Extras, as stored in the database. The objects
+// are returned even if some values cannot be decoded, these fields are left
+// empty and the error lists them.
+func (db *DB) Objects() (*Objects, error) {
+	d := &decoder{db: db, objects: map[string]any{}}
+	o := &Objects{objects: d.objects}
+	o.load(d)
+	return o, errors.Join(d.errs...)
+}
+
+// Lookup returns the object with the given reference (a *VM, a *Host, ...),
+// or nil if it is not in the objects.
+func (o *Objects) Lookup(ref string) any {
+	return o.objects[ref]
+}
+
+// decoder fills the objects from the rows and keeps the errors.
+type decoder struct {
+	db *DB
+	// objects by reference, created before decoding the fields to resolve
+	// references
+	objects map[string]any
+	errs    []error
+}
+
+// create returns an object for each row of the table.
+func create[T any](d *decoder, class string) ([]*T, []*Row) {
+	var objects []*T
+	var rows []*Row
+	for row := range d.db.Table(class).Rows() {
+		o := new(T)
+		objects = append(objects, o)
+		rows = append(rows, row)
+		d.objects[row.Ref()] = o
+	}
+	return objects, rows
+}
+
+// field decodes a field with conv, fields missing in the row (optional ones
+// of older databases) are left empty.
+func field[T any](d *decoder, row *Row, name string, conv func(any) (T, bool)) T {
+	var zero T
+	if _, ok := row.Raw(name); !ok {
+		return zero
+	}
+
+	v, err := row.Field(name)
+	if err != nil {
+		d.errs = append(d.errs, fmt.Errorf("%s %s: %w", row.Class(), row.Ref(), err))
+		return zero
+	}
+	t, ok := conv(v)
+	if !ok {
+		typ, _ := row.Type(name)
+		d.errs = append(d.errs, fmt.Errorf("%s %s: %s.%s: %s does not match the struct", row.Class(), row.Ref(), row.Class(), name, typ))
+	}
+	return t
+}
+
+// extras returns the fields of the row that are not in known.
+func extras(row *Row, known map[string]bool) map[string]string {
+	m := map[string]string{}
+	for name, value := range row.Fields() {
+		if !known[name] {
+			m[name] = value
+		}
+	}
+	return m
+}
+
+func as[T any](v any) (T, bool) {
+	t, ok := v.(T)
+	return t, ok
+}
+
+var (
+	asString = as[string]
+	asInt    = as[int64]
+	asFloat  = as[float64]
+	asBool   = as[bool]
+	asTime   = as[time.Time]
+)
+
+// asRef returns a conversion resolving references to the objects of d.
+func asRef[T any](d *decoder) func(any) (Ref[T], bool) {
+	return func(v any) (Ref[T], bool) {
+		s, ok := v.(string)
+		if !ok {
+			return Ref[T]{}, false
+		}
+		target, _ := d.objects[s].(*T)
+		return Ref[T]{Ref: s, Target: target}, true
+	}
+}
+
+func asSet[T any](conv func(any) (T, bool)) func(any) ([]T, bool) {
+	return func(v any) ([]T, bool) {
+		items, ok := v.([]any)
+		if !ok {
+			return nil, false
+		}
+		set := make([]T, 0, len(items))
+		for _, item := range items {
+			t, ok := conv(item)
+			if !ok {
+				return nil, false
+			}
+			set = append(set, t)
+		}
+		return set, true
+	}
+}
+
+func asMap[T any](conv func(any) (T, bool)) func(any) (map[string]T, bool) {
+	return func(v any) (map[string]T, bool) {
+		items, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		m := make(map[string]T, len(items))
+		for k, item := range items {
+			t, ok := conv(item)
+			if !ok {
+				return nil, false
+			}
+			m[k] = t
+		}
+		return m, true
+	}
+}
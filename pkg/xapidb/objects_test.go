@@ -0,0 +1,101 @@
+package xapidb_test
+
+import (
+	"testing"
+
+	"example.com/readxapidb/pkg/xapidb"
+)
+
+// objectsDB has rows with the names of the database, the namespaced fields
+// are joined by a double underscore.
+const objectsDB = `<?xml version="1.0" encoding="UTF-8"?>
+<database>
+  <manifest>
+    <pair key="schema_major_vsn" value="5"/>
+    <pair key="schema_minor_vsn" value="790"/>
+  </manifest>
+  <table name="VM">
+    <row ref="OpaqueRef:vm" __ctime="7950" __mtime="9912" _ref="OpaqueRef:vm" uuid="2b7f8e4c-1d3a-4f5b-9c6d-7e8f9a0b1c2d"
+      HVM__boot_params="(('firmware'%.'uefi'))" HVM__shadow_multiplier="1." VBDs="('OpaqueRef:vbd')"
+      VCPUs__at_startup="2" VCPUs__max="4" actions__after_crash="restart" actions__after_softreboot="soft_reboot"
+      allowed_operations="('snapshot'%.'clean_shutdown')" memory__dynamic_max="4294967296"
+      memory__static_max="8589934592" memory__static_min="1073741824" name__label="debian%.12"
+      power_state="Running" resident_on="OpaqueRef:host" snapshot_time="20250331T15:00:19Z" future_field="1"/>
+  </table>
+  <table name="VBD">
+    <row ref="OpaqueRef:vbd" __ctime="7951" __mtime="7951" _ref="OpaqueRef:vbd" uuid="0d9e1f5a-3c2b-4a1d-8e7f-6a5b4c3d2e1f"
+      VM="OpaqueRef:vm" VDI="OpaqueRef:NULL" device="xvda" qos__algorithm_type="" qos__algorithm_params="()"/>
+  </table>
+  <table name="host">
+    <row ref="OpaqueRef:host" __ctime="12" __mtime="40" _ref="OpaqueRef:host" uuid="6b2d3b4e-6d47-4e5e-9fb1-9d0c5a3b8c11"
+      API_version__major="2" API_version__minor="21" memory__overhead="619634688" name__label="xcp-ng-1"
+      resident_VMs="('OpaqueRef:vm')"/>
+  </table>
+</database>`
+
+func TestObjects(t *testing.T) {
+	db, err := xapidb.Parse([]byte(objectsDB))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := db.Objects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects.VMs) != 1 || len(objects.Hosts) != 1 || len(objects.VBDs) != 1 {
+		t.Fatalf("got %d VMs, %d hosts and %d VBDs", len(objects.VMs), len(objects.Hosts), len(objects.VBDs))
+	}
+
+	vm := objects.VMs[0]
+	for _, f := range []struct {
+		name      string
+		got, want any
+	}{
+		{"MemoryStaticMax", vm.MemoryStaticMax, int64(8589934592)},
+		{"MemoryStaticMin", vm.MemoryStaticMin, int64(1073741824)},
+		{"MemoryDynamicMax", vm.MemoryDynamicMax, int64(4294967296)},
+		{"VCPUsMax", vm.VCPUsMax, int64(4)},
+		{"VCPUsAtStartup", vm.VCPUsAtStartup, int64(2)},
+		{"ActionsAfterCrash", vm.ActionsAfterCrash, "restart"},
+		{"ActionsAfterSoftreboot", vm.ActionsAfterSoftreboot, "soft_reboot"},
+		{"HVMShadowMultiplier", vm.HVMShadowMultiplier, 1.0},
+		{"HVMBootParams", vm.HVMBootParams["firmware"], "uefi"},
+		{"NameLabel", vm.NameLabel, "debian 12"},
+		{"SnapshotTime", vm.SnapshotTime.Unix(), int64(1743433219)},
+	} {
+		if f.got != f.want {
+			t.Errorf("VM.%s: got %v, want %v", f.name, f.got, f.want)
+		}
+	}
+
+	if len(vm.Extras) != 1 || vm.Extras["future_field"] != "1" {
+		t.Errorf("VM.Extras: got %v", vm.Extras)
+	}
+
+	host := objects.Hosts[0]
+	if host.APIVersionMajor != 2 || host.APIVersionMinor != 21 || host.MemoryOverhead != 619634688 {
+		t.Errorf("host API version %d.%d, memory overhead %d", host.APIVersionMajor, host.APIVersionMinor, host.MemoryOverhead)
+	}
+	if len(host.Extras) != 0 {
+		t.Errorf("host.Extras: got %v", host.Extras)
+	}
+
+	// References resolve to the objects
+	if vm.ResidentOn.Target != host {
+		t.Error("VM.ResidentOn does not point to the host")
+	}
+	if len(host.ResidentVMs) != 1 || host.ResidentVMs[0].Target != vm {
+		t.Error("host.ResidentVMs does not point to the VM")
+	}
+	vbd := objects.VBDs[0]
+	if len(vm.VBDs) != 1 || vm.VBDs[0].Target != vbd || vbd.VM.Target != vm {
+		t.Error("VM.VBDs and VBD.VM do not point to each other")
+	}
+	if !vbd.VDI.IsNull() || vbd.VDI.Target != nil {
+		t.Error("VBD.VDI is not null")
+	}
+	if objects.Lookup("OpaqueRef:vm") != vm {
+		t.Error("Lookup does not return the VM")
+	}
+}
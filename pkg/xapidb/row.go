@@ -0,0 +1,219 @@
+package xapidb
+
+import (
+	"fmt"
+	"iter"
+	"sort"
+	"time"
+
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// Row is an object of the database.
+type Row struct {
+	db   *DB
+	node *xapidb.Node
+}
+
+// Reference is a reference held by a field of a row.
+type Reference struct {
+	Field string
+	Ref   string
+	// Class of the referenced object, empty if the field is not in the
+	// schema.
+	Class string
+}
+
+// Ref returns the reference of the row (OpaqueRef:...).
+func (r *Row) Ref() string {
+	return r.node.Attr["ref"]
+}
+
+// UUID returns the uuid of the row, empty for classes without one.
+func (r *Row) UUID() string {
+	return r.node.Attr["uuid"]
+}
+
+// Label returns the name__label of the row, empty for classes without one.
+func (r *Row) Label() string {
+	return r.String("name__label")
+}
+
+// Class returns the class of the row, the name of its table.
+func (r *Row) Class() string {
+	return r.node.Parent.Attr["name"]
+}
+
+// Table returns the table of the row.
+func (r *Row) Table() *Table {
+	return &Table{db: r.db, node: r.node.Parent}
+}
+
+// Fields returns the names and the raw values of the fields sorted by name.
+func (r *Row) Fields() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		names := make([]string, 0, len(r.node.Attr))
+		for name := range r.node.Attr {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !yield(name, r.node.Attr[name]) {
+				return
+			}
+		}
+	}
+}
+
+// Raw returns the value of a field as stored in the database: whitespaces
+// are escaped and sets and maps are s-expressions.
+func (r *Row) Raw(field string) (string, bool) {
+	v, ok := r.node.Attr[field]
+	return v, ok
+}
+
+// String returns the value of a field unescaped, empty if the row doesn't
+// have it.
+func (r *Row) String(field string) string {
+	return xapidb.UnescapeValue(r.node.Attr[field])
+}
+
+// Type returns the type of a field in the schema (int, set ref VBD, ...).
+func (r *Row) Type(field string) (string, bool) {
+	f, ok := r.db.schema.Field(r.Class(), field)
+	if !ok {
+		return "", false
+	}
+	return f.Type.String(), true
+}
+
+// Field returns the value of a field decoded with the type of the schema:
+//
+//	string, enum, ref -> string
+//	int               -> int64
+//	float             -> float64
+//	bool              -> bool
+//	datetime          -> time.Time
+//	set               -> []any
+//	map               -> map[string]any (keys are kept as strings)
+//
+// It fails with ErrUnknownField for fields not in the schema, their value
+// is available with String.
+func (r *Row) Field(field string) (any, error) {
+	f, ok := r.db.schema.Field(r.Class(), field)
+	if !ok {
+		return nil, fmt.Errorf("%s.%s: %w", r.Class(), field, ErrUnknownField)
+	}
+	v, ok := r.node.Attr[field]
+	if !ok {
+		return nil, fmt.Errorf("%s.%s: missing field", r.Class(), field)
+	}
+	value, err := xapidb.Decode(f.Type, v)
+	if err != nil {
+		return nil, fmt.Errorf("%s.%s: %w", r.Class(), field, err)
+	}
+	return value, nil
+}
+
+// Int returns the value of an int field.
+func (r *Row) Int(field string) (int64, error) {
+	return fieldAs[int64](r, field)
+}
+
+// Float returns the value of a float field.
+func (r *Row) Float(field string) (float64, error) {
+	return fieldAs[float64](r, field)
+}
+
+// Bool returns the value of a bool field.
+func (r *Row) Bool(field string) (bool, error) {
+	return fieldAs[bool](r, field)
+}
+
+// Time returns the value of a datetime field.
+func (r *Row) Time(field string) (time.Time, error) {
+	return fieldAs[time.Time](r, field)
+}
+
+// Set returns the values of a set of strings, enums or references.
+func (r *Row) Set(field string) ([]string, error) {
+	items, err := fieldAs[[]any](r, field)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s.%s: not a set of strings", r.Class(), field)
+		}
+		values = append(values, s)
+	}
+	return values, nil
+}
+
+// Map returns the values of a map whose values are strings, enums or
+// references, like other_config.
+func (r *Row) Map(field string) (map[string]string, error) {
+	m, err := fieldAs[map[string]any](r, field)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(m))
+	for k, item := range m {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s.%s: not a map of strings", r.Class(), field)
+		}
+		values[k] = s
+	}
+	return values, nil
+}
+
+func fieldAs[T any](r *Row, field string) (T, error) {
+	var zero T
+	v, err := r.Field(field)
+	if err != nil {
+		return zero, err
+	}
+	t, ok := v.(T)
+	if !ok {
+		typ, _ := r.Type(field)
+		return zero, fmt.Errorf("%s.%s: the field is a %s", r.Class(), field, typ)
+	}
+	return t, nil
+}
+
+// Refs returns the references held by the fields of the row, including the
+// ones in sets and maps, sorted by field. Null references are skipped.
+func (r *Row) Refs() []Reference {
+	refs := []Reference{}
+	for _, ref := range xapidb.Refs(r.db.schema, r.node) {
+		refs = append(refs, Reference(ref))
+	}
+	return refs
+}
+
+// Follow returns the rows referenced by a field of type ref, set or map.
+// References to objects not in the database are skipped, see Resolve to
+// know about them.
+func (r *Row) Follow(field string) []*Row {
+	rows := []*Row{}
+	for _, ref := range r.Refs() {
+		if ref.Field != field {
+			continue
+		}
+		if row := r.db.Row(ref.Ref); row != nil {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// Doc returns the documentation of a field from the schema.
+func (r *Row) Doc(field string) string {
+	if f, ok := r.db.schema.Field(r.Class(), field); ok {
+		return f.Doc
+	}
+	return ""
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"example.com/readxapidb/internal/args"
+	"example.com/readxapidb/internal/cache"
+	"example.com/readxapidb/internal/diff"
+	"example.com/readxapidb/internal/fetch"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// saveSnapshot keeps a copy of data fetched from host in the cache.
+func saveSnapshot(args args.Args, host string, data []byte, db *xapidb.DB) {
+	if args.NoCache || args.CacheDir == "" {
+		return
+	}
+
+	c := cache.Cache{Dir: args.CacheDir}
+	s, err := c.Save(host, data, db.GenerationCount())
+	if err != nil {
+		fmt.Printf("failed to save snapshot of %s: %s\n", host, err)
+		return
+	}
+
+	fmt.Printf("Saved snapshot of %s to %s\n", host, s.Path)
+
+	if err := cache.WriteIndex(s.Path); err != nil {
+		fmt.Printf("failed to index the snapshot of %s: %s\n", host, err)
+	}
+}
+
+// runSnapshots runs the snapshots command. It returns true if the UI must
+// be started, args has then been updated to open the snapshot.
+func runSnapshots(a *args.Args) bool {
+	c := cache.Cache{Dir: a.CacheDir}
+
+	usage := func() {
+		fmt.Println("Usage: readxapidb snapshots list [host]")
+		fmt.Println("       readxapidb snapshots open <host> [index]")
+		fmt.Println("       readxapidb snapshots prune [-keep N] [host]")
+		fmt.Println("       readxapidb snapshots diff <host> [index1 index2]")
+		fmt.Println()
+		fmt.Println("Index 0 is the most recent snapshot, see 'list'.")
+		os.Exit(1)
+	}
+
+	if len(a.CommandArgs) == 0 {
+		usage()
+	}
+
+	sub, rest := a.CommandArgs[0], a.CommandArgs[1:]
+
+	switch sub {
+	case "list":
+		hosts := rest
+		if len(hosts) == 0 {
+			var err error
+			if hosts, err = c.Hosts(); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		for _, host := range hosts {
+			snapshots, err := c.List(host)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Printf("%s:\n", host)
+			for i, s := range snapshots {
+				fmt.Printf("  %3d  %s  generation %-8d %10d bytes\n",
+					i, s.Time.Local().Format("2006-01-02 15:04:05"), s.Generation, s.Size)
+			}
+		}
+		return false
+
+	case "open":
+		if len(rest) < 1 || len(rest) > 2 {
+			usage()
+		}
+
+		s := snapshotAt(c, rest[0], rest[1:])
+		a.FileName = s.Path
+		a.Hostname = ""
+		a.Offline = false
+		return true
+
+	case "prune":
+		fs := flag.NewFlagSet("prune", flag.ExitOnError)
+		keep := fs.Int("keep", 10, "Number of snapshots to keep per host")
+		fs.Parse(rest)
+
+		hosts := fs.Args()
+		if len(hosts) == 0 {
+			var err error
+			if hosts, err = c.Hosts(); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		for _, host := range hosts {
+			removed, err := c.Prune(host, *keep)
+			for _, s := range removed {
+				fmt.Printf("Removed %s\n", s.Path)
+			}
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		return false
+
+	case "diff":
+		if len(rest) != 1 && len(rest) != 3 {
+			usage()
+		}
+
+		// By default compare the previous snapshot with the last one
+		indexes := []string{"1", "0"}
+		if len(rest) == 3 {
+			indexes = rest[1:]
+		}
+
+		sa := snapshotAt(c, rest[0], indexes[:1])
+		sb := snapshotAt(c, rest[0], indexes[1:])
+
+		report := diff.Compare(loadSnapshot(sa), loadSnapshot(sb), diff.DefaultOptions())
+		report.AName = fmt.Sprintf("%s (generation %d)", sa.Path, sa.Generation)
+		report.BName = fmt.Sprintf("%s (generation %d)", sb.Path, sb.Generation)
+		report.Print(os.Stdout)
+		return false
+	}
+
+	usage()
+	return false
+}
+
+// snapshotAt returns the snapshot of the host at the optional index.
+func snapshotAt(c cache.Cache, host string, index []string) cache.Snapshot {
+	snapshots, err := c.List(host)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	i := 0
+	if len(index) > 0 {
+		if i, err = strconv.Atoi(index[0]); err != nil {
+			fmt.Printf("invalid index %s\n", index[0])
+			os.Exit(1)
+		}
+	}
+
+	if i < 0 || i >= len(snapshots) {
+		fmt.Printf("%s has %d snapshot(s), no index %d\n", host, len(snapshots), i)
+		os.Exit(1)
+	}
+
+	return snapshots[i]
+}
+
+func loadSnapshot(s cache.Snapshot) *xapidb.DB {
+	data, err := fetch.Local(s.Path)
+	if err != nil {
+		fmt.Printf("failed to read %s: %s\n", s.Path, err)
+		os.Exit(1)
+	}
+
+	db, err := xapidb.ParseXapiDB(data)
+	if err != nil {
+		fmt.Printf("failed to parse %s: %s\n", s.Path, err)
+		os.Exit(1)
+	}
+
+	return db
+}
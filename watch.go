@@ -0,0 +1,86 @@
+package main
+
+import (
+	"time"
+
+	"github.com/rivo/tview"
+
+	"example.com/readxapidb/internal/args"
+	"example.com/readxapidb/internal/fetch"
+	"example.com/readxapidb/internal/ui"
+	"example.com/readxapidb/internal/xapidb"
+)
+
+// watch refetches the database when the local file changes, or at each
+// interval for a remote one, and updates the displayed db in place. Rows
+// changed since the previous fetch are highlighted.
+func watch(
+	args args.Args,
+	app *tview.Application,
+	tree *tview.TreeView,
+	status *tview.Table,
+	debugView *tview.TextView,
+	db *xapidb.DB,
+) {
+	// Several notifications can happen while the file is written, the
+	// buffer coalesces them.
+	trigger := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	}
+
+	report := func(format string, a ...any) {
+		app.QueueUpdateDraw(func() {
+			debugView.Clear()
+			ui.Logf(debugView, format, a...)
+		})
+	}
+
+	if args.Hostname == "" {
+		go func() {
+			if err := fetch.WatchLocal(args.FileName, notify); err != nil {
+				report("[red]Failed to watch %s: %s, refetching every %s", args.FileName, err, args.WatchInterval)
+				for range time.Tick(args.WatchInterval) {
+					notify()
+				}
+			}
+		}()
+	} else {
+		go func() {
+			for range time.Tick(args.WatchInterval) {
+				notify()
+			}
+		}()
+	}
+
+	for range trigger {
+		// Let the writer finish
+		time.Sleep(200 * time.Millisecond)
+
+		data, err := fetch.DB(args)
+		if err != nil {
+			report("[red]Failed to refetch %s: %s", args.FileName, err)
+			continue
+		}
+
+		newDB, err := xapidb.ParseXapiDB(data)
+		if err != nil {
+			// Probably read while being written, next change will fix it
+			report("[red]Failed to parse %s: %s", args.FileName, err)
+			continue
+		}
+
+		app.QueueUpdateDraw(func() {
+			changes := db.Update(newDB)
+			ui.ClearHighlights(tree)
+			ui.ApplyChanges(app, tree, status, changes, 0)
+
+			debugView.Clear()
+			ui.Logf(debugView, "[green]Refetched at %s: %d change(s), generation %d",
+				time.Now().Format(time.TimeOnly), len(changes), db.GenerationCount())
+		})
+	}
+}